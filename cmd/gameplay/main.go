@@ -0,0 +1,85 @@
+// Command gameplay checks config/gameplay.json.
+//
+//	gameplay lint [-config path]
+//	gameplay canon [-proto path] [-enums path] [-config path]
+//
+// lint prints every problem found (unknown effect keys, broken
+// cross-references, rule violations). canon compares IDs, keys, ranks and
+// counts across the proto, internal/protocol constants and the gameplay
+// config. Both exit 1 if anything is wrong; run them from the repo root.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"example.com/mvp-repo/internal/canon"
+	"example.com/mvp-repo/internal/config"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "lint":
+		os.Exit(lint(os.Args[2:]))
+	case "canon":
+		os.Exit(checkCanon(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gameplay lint [-config path]")
+	fmt.Fprintln(os.Stderr, "       gameplay canon [-proto path] [-enums path] [-config path]")
+	os.Exit(2)
+}
+
+func lint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	path := fs.String("config", canon.DefaultGameplayPath, "gameplay config to check")
+	_ = fs.Parse(args)
+
+	cfg, err := config.ReadGameplayConfig(*path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *path, err)
+		return 2
+	}
+	problems := cfg.Lint()
+	for _, p := range problems {
+		fmt.Printf("%s: %v\n", *path, p)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("%d problem(s)\n", len(problems))
+		return 1
+	}
+	fmt.Printf("%s: ok\n", *path)
+	return 0
+}
+
+func checkCanon(args []string) int {
+	fs := flag.NewFlagSet("canon", flag.ExitOnError)
+	protoPath := fs.String("proto", canon.DefaultProtoPath, "proto source")
+	enumsPath := fs.String("enums", canon.DefaultEnumsPath, "internal/protocol enum constants")
+	gameplayPath := fs.String("config", canon.DefaultGameplayPath, "gameplay config")
+	_ = fs.Parse(args)
+
+	src, err := canon.Load(*protoPath, *enumsPath, *gameplayPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	mismatches := canon.Check(src)
+	for _, m := range mismatches {
+		fmt.Println(m)
+	}
+	if len(mismatches) > 0 {
+		fmt.Printf("%d mismatch(es)\n", len(mismatches))
+		return 1
+	}
+	fmt.Println("canon: ok")
+	return 0
+}
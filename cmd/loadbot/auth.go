@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// apiResponse mirrors the httpapi envelope.
+type apiResponse struct {
+	OK   bool `json:"ok"`
+	Data struct {
+		Token string `json:"token"`
+	} `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// login registers the bot's account, falling back to a username login when
+// it already exists from an earlier run. It returns the session token.
+func (b *bot) login(ctx context.Context) (string, error) {
+	username := b.cfg.Prefix + strconv.Itoa(b.id)
+	status, resp, err := b.post(ctx, "/api/auth/register", map[string]string{
+		"email":    username + "@loadbot.invalid",
+		"username": username,
+		"password": b.cfg.Password,
+	})
+	if err != nil {
+		return "", err
+	}
+	if status == http.StatusConflict {
+		status, resp, err = b.post(ctx, "/api/auth/login", map[string]string{
+			"username": username,
+			"password": b.cfg.Password,
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+	if !resp.OK || resp.Data.Token == "" {
+		if resp.Error != nil {
+			return "", fmt.Errorf("http %d %s", status, resp.Error.Code)
+		}
+		return "", fmt.Errorf("http %d", status)
+	}
+	return resp.Data.Token, nil
+}
+
+func (b *bot) post(ctx context.Context, path string, body any) (int, apiResponse, error) {
+	var resp apiResponse
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return 0, resp, err
+	}
+	url := strings.TrimRight(b.cfg.APIURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return 0, resp, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	start := time.Now()
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, resp, err
+	}
+	defer res.Body.Close()
+	b.stats.latency(strings.TrimPrefix(path, "/api/"), time.Since(start))
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+		return res.StatusCode, resp, errors.Join(fmt.Errorf("http %d", res.StatusCode), err)
+	}
+	return res.StatusCode, resp, nil
+}
@@ -0,0 +1,389 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/coder/websocket"
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/net/frame"
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// readLimit matches the gateway's default ws.read_limit_bytes.
+const readLimit = frame.DefaultMaxPayloadBytes
+
+type botConfig struct {
+	WSURL        string
+	APIURL       string
+	Prefix       string
+	Password     string
+	MoveInterval time.Duration
+	ChatInterval time.Duration
+	PingInterval time.Duration
+	Reconnect    time.Duration
+	Batch        bool
+}
+
+type bot struct {
+	id    int
+	cfg   botConfig
+	stats *stats
+	rng   *rand.Rand
+	token string
+}
+
+func newBot(id int, cfg botConfig, st *stats) *bot {
+	return &bot{
+		id:    id,
+		cfg:   cfg,
+		stats: st,
+		rng:   rand.New(rand.NewPCG(uint64(id), uint64(time.Now().UnixNano()))),
+	}
+}
+
+// run keeps the bot connected until ctx ends, reconnecting after each
+// disconnect unless reconnecting is disabled.
+func (b *bot) run(ctx context.Context) {
+	for {
+		err := b.session(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		b.stats.disconnect(reason(err))
+		if b.cfg.Reconnect <= 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(b.cfg.Reconnect):
+		}
+	}
+}
+
+// session is one login + connection lifetime.
+func (b *bot) session(ctx context.Context) error {
+	if b.token == "" {
+		token, err := b.login(ctx)
+		if err != nil {
+			return &stageError{stage: "auth", err: err}
+		}
+		b.token = token
+	}
+
+	ws, res, err := websocket.Dial(ctx, b.cfg.WSURL, nil)
+	if err != nil {
+		// Handshake refusals (503 draining, 429 per-IP limits) are worth
+		// telling apart from network failures.
+		if res != nil {
+			return &stageError{stage: "dial " + strconv.Itoa(res.StatusCode), err: err}
+		}
+		return &stageError{stage: "dial", err: err}
+	}
+	ws.SetReadLimit(readLimit + frame.HeaderLen)
+	c := &botConn{
+		ws:      ws,
+		stats:   b.stats,
+		welcome: make(chan struct{}),
+		turns:   make(chan turn, 1),
+	}
+	defer ws.CloseNow()
+
+	b.stats.connected.Add(1)
+	defer b.stats.connected.Add(-1)
+
+	features := []string{"server_shutdown", "error_codes"}
+	if b.cfg.Batch {
+		features = append(features, "batch")
+	}
+	c.helloAt = time.Now()
+	if err := c.send(ctx, &gen.Hello{
+		Token:           []byte(b.token),
+		ProtocolVersion: protocol.ProtocolVersion,
+		ClientBuild:     "loadbot",
+		Features:        features,
+	}); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errCh := make(chan error, 2)
+	go func() {
+		errCh <- c.readLoop(ctx)
+	}()
+	go func() {
+		errCh <- b.actLoop(ctx, c)
+	}()
+	err = <-errCh
+	if ctx.Err() == nil {
+		_ = ws.Close(websocket.StatusNormalClosure, "")
+	}
+	cancel()
+	<-errCh
+	return err
+}
+
+// actLoop sends player intents on fixed intervals once the server welcomed
+// the bot.
+func (b *bot) actLoop(ctx context.Context, c *botConn) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.welcome:
+	}
+
+	move := tickerOrNil(b.cfg.MoveInterval)
+	chat := tickerOrNil(b.cfg.ChatInterval)
+	ping := tickerOrNil(b.cfg.PingInterval)
+	defer stopTicker(move)
+	defer stopTicker(chat)
+	defer stopTicker(ping)
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-tickC(move):
+			err = c.send(ctx, b.moveIntent())
+		case <-tickC(chat):
+			err = c.send(ctx, &gen.ChatSend{Text: fmt.Sprintf("%s%d says hi", b.cfg.Prefix, b.id)})
+		case <-tickC(ping):
+			err = c.ping(ctx)
+		case turn := <-c.turns:
+			if err = c.send(ctx, b.turnInput(turn)); err == nil {
+				b.stats.battleInput()
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// moveIntent picks one of the four cardinal steps.
+func (b *bot) moveIntent() *gen.WorldMoveIntent {
+	steps := [4][2]int32{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	s := steps[b.rng.IntN(len(steps))]
+	return &gen.WorldMoveIntent{Dx: s[0], Dy: s[1]}
+}
+
+// turnInput builds a random MOVE on the 8x8 board. The bot cannot pick its
+// own pieces: BattleStart.initial_board and the timeline event payloads have
+// no defined encoding yet. The server's verdicts are therefore reported under
+// "battle" in the stats, apart from the rest of the traffic.
+func (b *bot) turnInput(t turn) *gen.BattleTurnInput {
+	return &gen.BattleTurnInput{
+		BattleId:      t.battleID,
+		TurnSeq:       t.seq,
+		ActionType:    gen.BattleActionType_MOVE,
+		MovePieceId:   uint64(1 + b.rng.IntN(16)),
+		MoveToX:       int32(b.rng.IntN(8)),
+		MoveToY:       int32(b.rng.IntN(8)),
+		BlockPathDir4: 255,
+	}
+}
+
+// turn asks the act loop to submit the input for turnSeq of a battle.
+type turn struct {
+	battleID uint64
+	seq      uint32
+}
+
+// botConn is the client side of one WebSocket connection.
+type botConn struct {
+	ws      *websocket.Conn
+	stats   *stats
+	writeMu sync.Mutex
+	helloAt time.Time
+	pingAt  atomic.Int64
+	// welcome is closed by the read loop on MSG_WELCOME.
+	welcome  chan struct{}
+	welcomed bool
+	turns    chan turn
+}
+
+func (c *botConn) send(ctx context.Context, msg proto.Message) error {
+	msgType, ok := protocol.MsgTypeOf(msg)
+	if !ok {
+		return fmt.Errorf("loadbot: unregistered message %T", msg)
+	}
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return c.sendRaw(ctx, msgType, payload)
+}
+
+func (c *botConn) sendRaw(ctx context.Context, msgType protocol.MsgType, payload []byte) error {
+	buf, err := frame.Encode(nil, uint16(msgType), payload, readLimit)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.ws.Write(ctx, websocket.MessageBinary, buf); err != nil {
+		return err
+	}
+	c.stats.sent(msgType)
+	return nil
+}
+
+// ping probes application-level round trip; only one probe is outstanding
+// at a time so the matching MSG_PONG is unambiguous.
+func (c *botConn) ping(ctx context.Context) error {
+	if !c.pingAt.CompareAndSwap(0, time.Now().UnixNano()) {
+		return nil
+	}
+	return c.sendRaw(ctx, protocol.MSG_PING, nil)
+}
+
+func (c *botConn) readLoop(ctx context.Context) error {
+	for {
+		msgType, data, err := c.ws.Read(ctx)
+		if err != nil {
+			return err
+		}
+		if msgType != websocket.MessageBinary {
+			return errors.New("loadbot: text message from server")
+		}
+		wireType, payload, err := frame.Decode(data, readLimit)
+		if err != nil {
+			return err
+		}
+		if wireType != frame.BatchType {
+			if err := c.handle(ctx, protocol.MsgType(wireType), payload); err != nil {
+				return err
+			}
+			continue
+		}
+		c.stats.batch()
+		r := frame.NewBatchReader(payload, readLimit)
+		for r.Next() {
+			recType, record, compressed := r.Record()
+			if compressed {
+				// Payload deflate is never negotiated by the bot; count and skip.
+				c.stats.received(protocol.MsgType(recType))
+				continue
+			}
+			if err := c.handle(ctx, protocol.MsgType(recType), record); err != nil {
+				return err
+			}
+		}
+		if err := r.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *botConn) handle(ctx context.Context, msgType protocol.MsgType, payload []byte) error {
+	c.stats.received(msgType)
+	switch msgType {
+	case protocol.MSG_WELCOME:
+		if !c.welcomed {
+			c.welcomed = true
+			c.stats.latency("hello", time.Since(c.helloAt))
+			close(c.welcome)
+		}
+	case protocol.MSG_PING:
+		return c.sendRaw(ctx, protocol.MSG_PONG, nil)
+	case protocol.MSG_PONG:
+		if at := c.pingAt.Swap(0); at != 0 {
+			c.stats.latency("ping", time.Since(time.Unix(0, at)))
+		}
+	case protocol.MSG_ERROR:
+		var e gen.Error
+		if err := proto.Unmarshal(payload, &e); err != nil {
+			return err
+		}
+		c.stats.rejected(protocol.ErrorCode(e.GetCode()))
+	case protocol.MSG_BATTLE_START:
+		var m gen.BattleStart
+		if err := proto.Unmarshal(payload, &m); err != nil {
+			return err
+		}
+		c.queueTurn(turn{battleID: m.GetBattleId()})
+	case protocol.MSG_BATTLE_OUTCOME_TIMELINE:
+		var m gen.BattleOutcomeTimeline
+		if err := proto.Unmarshal(payload, &m); err != nil {
+			return err
+		}
+		c.stats.battleTimeline()
+		c.queueTurn(turn{battleID: m.GetBattleId(), seq: m.GetTurnSeq() + 1})
+	}
+	return nil
+}
+
+// queueTurn keeps only the latest pending turn; a newer timeline supersedes
+// an input the act loop has not sent yet.
+func (c *botConn) queueTurn(t turn) {
+	for {
+		select {
+		case c.turns <- t:
+			return
+		default:
+		}
+		select {
+		case <-c.turns:
+		default:
+		}
+	}
+}
+
+// stageError marks failures before the WebSocket session started.
+type stageError struct {
+	stage string
+	err   error
+}
+
+func (e *stageError) Error() string { return e.stage + ": " + e.err.Error() }
+func (e *stageError) Unwrap() error { return e.err }
+
+// reason buckets a session error into a low-cardinality disconnect reason.
+func reason(err error) string {
+	var se *stageError
+	if errors.As(err, &se) {
+		return se.stage
+	}
+	var ce websocket.CloseError
+	if errors.As(err, &ce) {
+		if ce.Reason != "" {
+			return fmt.Sprintf("close %d %s", int(ce.Code), ce.Reason)
+		}
+		return fmt.Sprintf("close %d", int(ce.Code))
+	}
+	if err == nil {
+		return "none"
+	}
+	return "transport"
+}
+
+func tickerOrNil(d time.Duration) *time.Ticker {
+	if d <= 0 {
+		return nil
+	}
+	return time.NewTicker(d)
+}
+
+func tickC(t *time.Ticker) <-chan time.Time {
+	if t == nil {
+		return nil
+	}
+	return t.C
+}
+
+func stopTicker(t *time.Ticker) {
+	if t != nil {
+		t.Stop()
+	}
+}
@@ -0,0 +1,90 @@
+// Command loadbot drives the server with simulated players: each bot logs in
+// over the HTTP API, opens a WebSocket, sends Hello, then wanders the
+// overworld, chats and answers battles until the run ends. Latency
+// percentiles, frame counts and disconnect reasons are printed periodically
+// and at exit.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+func main() {
+	var cfg botConfig
+	var (
+		bots     = flag.Int("bots", 10, "number of simulated players")
+		ramp     = flag.Float64("ramp", 50, "bots started per second")
+		duration = flag.Duration("duration", time.Minute, "run length (0 runs until interrupted)")
+		every    = flag.Duration("report", 10*time.Second, "interval between progress reports")
+	)
+	flag.StringVar(&cfg.WSURL, "ws", "ws://127.0.0.1:8443/ws", "gateway WebSocket URL")
+	flag.StringVar(&cfg.APIURL, "api", "http://127.0.0.1:8080", "HTTP API base URL for register/login (required: Hello must carry a session token)")
+	flag.StringVar(&cfg.Prefix, "prefix", "loadbot", "username prefix; bot i is <prefix><i>")
+	flag.StringVar(&cfg.Password, "password", "loadbot-password", "password for every bot account")
+	flag.DurationVar(&cfg.MoveInterval, "move", 250*time.Millisecond, "interval between move intents (0 disables)")
+	flag.DurationVar(&cfg.ChatInterval, "chat", 15*time.Second, "interval between chat messages (0 disables)")
+	flag.DurationVar(&cfg.PingInterval, "ping", 2*time.Second, "interval between latency probes")
+	flag.DurationVar(&cfg.Reconnect, "reconnect", 2*time.Second, "delay before reconnecting after a disconnect (0 disables)")
+	flag.BoolVar(&cfg.Batch, "batch", true, "offer the batch feature in Hello")
+	flag.Parse()
+
+	if *bots <= 0 || *ramp <= 0 {
+		log.Fatalf("loadbot: -bots and -ramp must be positive")
+	}
+	if cfg.APIURL == "" {
+		log.Fatalf("loadbot: -api is required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	st := newStats()
+	go func() {
+		ticker := time.NewTicker(*every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				st.report(os.Stdout)
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	gap := time.Duration(float64(time.Second) / *ramp)
+	start := time.Now()
+	for i := 0; i < *bots; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		b := newBot(i, cfg, st)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.run(ctx)
+		}()
+		// Pace against the start time so slow dials do not stretch the ramp.
+		if wait := time.Until(start.Add(time.Duration(i+1) * gap)); wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+		}
+	}
+	wg.Wait()
+	st.report(os.Stdout)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// stats aggregates results across all bots. Latency samples are kept in full
+// and sorted on report; at a few probes per bot per second that stays small
+// for runs of minutes.
+type stats struct {
+	connected atomic.Int64
+	batches   atomic.Uint64
+
+	mu          sync.Mutex
+	samples     map[string][]time.Duration
+	sentFrames  map[protocol.MsgType]uint64
+	recvFrames  map[protocol.MsgType]uint64
+	rejections  map[protocol.ErrorCode]uint64
+	disconnects map[string]uint64
+	// Battle inputs are random, so their results are kept apart from the
+	// other traffic rather than read as battle throughput.
+	battleInputs     uint64
+	battleTimelines  uint64
+	battleRejections map[protocol.ErrorCode]uint64
+}
+
+func newStats() *stats {
+	return &stats{
+		samples:     make(map[string][]time.Duration),
+		sentFrames:  make(map[protocol.MsgType]uint64),
+		recvFrames:  make(map[protocol.MsgType]uint64),
+		rejections:  make(map[protocol.ErrorCode]uint64),
+		disconnects: make(map[string]uint64),
+
+		battleRejections: make(map[protocol.ErrorCode]uint64),
+	}
+}
+
+func (s *stats) latency(name string, d time.Duration) {
+	s.mu.Lock()
+	s.samples[name] = append(s.samples[name], d)
+	s.mu.Unlock()
+}
+
+func (s *stats) sent(t protocol.MsgType) {
+	s.mu.Lock()
+	s.sentFrames[t]++
+	s.mu.Unlock()
+}
+
+func (s *stats) received(t protocol.MsgType) {
+	s.mu.Lock()
+	s.recvFrames[t]++
+	s.mu.Unlock()
+}
+
+func (s *stats) batch() {
+	s.batches.Add(1)
+}
+
+func (s *stats) rejected(code protocol.ErrorCode) {
+	s.mu.Lock()
+	if battleCode(code) {
+		s.battleRejections[code]++
+	} else {
+		s.rejections[code]++
+	}
+	s.mu.Unlock()
+}
+
+func (s *stats) battleInput() {
+	s.mu.Lock()
+	s.battleInputs++
+	s.mu.Unlock()
+}
+
+func (s *stats) battleTimeline() {
+	s.mu.Lock()
+	s.battleTimelines++
+	s.mu.Unlock()
+}
+
+// battleCode reports whether code is one of the battle rejections (4xx).
+// Generic codes such as ERR_UNIMPLEMENTED carry no request type and stay in
+// the shared error table.
+func battleCode(code protocol.ErrorCode) bool {
+	return code >= protocol.ERR_BATTLE_NOT_FOUND && code < 500
+}
+
+func (s *stats) disconnect(reason string) {
+	s.mu.Lock()
+	s.disconnects[reason]++
+	s.mu.Unlock()
+}
+
+func (s *stats) report(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintf(w, "--- %s connected=%d batches=%d\n", time.Now().Format(time.TimeOnly), s.connected.Load(), s.batches.Load())
+	for _, name := range slices.Sorted(maps.Keys(s.samples)) {
+		d := s.samples[name]
+		slices.Sort(d)
+		fmt.Fprintf(w, "latency %-14s n=%-7d p50=%-10s p90=%-10s p99=%-10s max=%s\n",
+			name, len(d), percentile(d, 50), percentile(d, 90), percentile(d, 99), d[len(d)-1])
+	}
+	for _, t := range slices.Sorted(maps.Keys(s.sentFrames)) {
+		fmt.Fprintf(w, "sent     %-28s %d\n", t, s.sentFrames[t])
+	}
+	for _, t := range slices.Sorted(maps.Keys(s.recvFrames)) {
+		fmt.Fprintf(w, "received %-28s %d\n", t, s.recvFrames[t])
+	}
+	for _, code := range slices.Sorted(maps.Keys(s.rejections)) {
+		fmt.Fprintf(w, "error    %-28s %d\n", code, s.rejections[code])
+	}
+	if s.battleInputs > 0 || s.battleTimelines > 0 || len(s.battleRejections) > 0 {
+		var rejected uint64
+		for _, n := range s.battleRejections {
+			rejected += n
+		}
+		fmt.Fprintf(w, "battle   inputs=%d timelines=%d rejected=%d (random moves, not legal play)\n", s.battleInputs, s.battleTimelines, rejected)
+		for _, code := range slices.Sorted(maps.Keys(s.battleRejections)) {
+			fmt.Fprintf(w, "battle   %-28s %d\n", code, s.battleRejections[code])
+		}
+	}
+	for _, reason := range slices.Sorted(maps.Keys(s.disconnects)) {
+		fmt.Fprintf(w, "disconnect %-26s %d\n", reason, s.disconnects[reason])
+	}
+}
+
+// percentile expects sorted, non-empty samples (nearest rank).
+func percentile(sorted []time.Duration, p int) time.Duration {
+	i := (len(sorted)*p + 99) / 100
+	if i > 0 {
+		i--
+	}
+	return sorted[i].Round(time.Microsecond)
+}
@@ -0,0 +1,9 @@
+package main
+
+// database/sql drivers for persistence.dev_driver ("sqlite", pure Go) and
+// persistence.prod_driver ("postgres"). persist never imports drivers
+// (DECISION 0009); the binary does.
+import (
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
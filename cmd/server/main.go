@@ -1,8 +1,21 @@
+// Command server runs the HTTP and WebSocket endpoints.
+//
+//	server [-config path] [-gameplay path] [-http-addr addr] [-ws-addr addr]
+//	       [-log-level level] [-check-config] [-dump-config]
+//
+// Server config values are layered: the -config file, then MVP_* environment
+// variables (one per field, see config.EnvPrefix), then the address flags.
+// -check-config validates both configs and exits; -dump-config prints the
+// effective server config as JSON, with DSN credentials redacted, and exits.
 package main
 
 import (
 	"context"
-	"log"
+	"encoding/json"
+	"expvar"
+	"flag"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,33 +27,145 @@ import (
 )
 
 const (
-	serverConfigPath   = "config/server.json"
-	gameplayConfigPath = "config/gameplay.json"
+	defaultServerConfigPath   = "config/server.json"
+	defaultGameplayConfigPath = "config/gameplay.json"
+	// configPollInterval is how often the config files are checked for edits;
+	// SIGHUP reloads immediately.
+	configPollInterval = 2 * time.Second
 )
 
 func main() {
-	serverCfg, err := config.LoadServerConfig(serverConfigPath)
-	if err != nil {
-		log.Fatalf("load server config: %v", err)
+	serverConfigPath := flag.String("config", defaultServerConfigPath, "server config file")
+	gameplayConfigPath := flag.String("gameplay", defaultGameplayConfigPath, "gameplay config file")
+	httpAddr := flag.String("http-addr", "", "override http.listen_addr")
+	wsAddr := flag.String("ws-addr", "", "override ws.listen_addr")
+	logLevel := flag.String("log-level", "info", "debug, info, warn or error")
+	checkConfig := flag.Bool("check-config", false, "validate the configs and exit")
+	dumpConfig := flag.Bool("dump-config", false, "print the effective server config as JSON and exit")
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := setLogLevel(*logLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "-log-level: %v\n", err)
+		os.Exit(2)
+	}
+	opts := options{
+		serverConfigPath:   *serverConfigPath,
+		gameplayConfigPath: *gameplayConfigPath,
+		httpAddr:           *httpAddr,
+		wsAddr:             *wsAddr,
+		checkConfig:        *checkConfig,
+		dumpConfig:         *dumpConfig,
+	}
+	if err := run(opts); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+// options are the parsed command-line flags run needs.
+type options struct {
+	serverConfigPath   string
+	gameplayConfigPath string
+	httpAddr           string
+	wsAddr             string
+	checkConfig        bool
+	dumpConfig         bool
+}
+
+// run serves until a signal or a listener error and returns only after the
+// shutdown sequence, so deferred cleanup (closing the store) always runs.
+func run(opts options) error {
+	override := func(cfg *config.ServerConfig) error {
+		if err := config.ApplyEnv(cfg, os.LookupEnv); err != nil {
+			return err
+		}
+		if opts.httpAddr != "" {
+			cfg.HTTP.ListenAddr = opts.httpAddr
+		}
+		if opts.wsAddr != "" {
+			cfg.WS.ListenAddr = opts.wsAddr
+		}
+		return nil
 	}
-	_, err = config.LoadGameplayConfig(gameplayConfigPath)
+	configs, err := config.NewManager(opts.serverConfigPath, opts.gameplayConfigPath, override)
 	if err != nil {
-		log.Fatalf("load gameplay config: %v", err)
+		return err
+	}
+	serverCfg := configs.Server()
+	if opts.dumpConfig {
+		out, err := json.MarshalIndent(serverCfg.Redacted(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	}
+	if opts.checkConfig {
+		fmt.Fprintf(os.Stderr, "%s, %s: ok\n", opts.serverConfigPath, opts.gameplayConfigPath)
+	}
+	if opts.dumpConfig || opts.checkConfig {
+		return nil
+	}
+	for _, v := range config.ServerEnvVars() {
+		if _, ok := os.LookupEnv(v.Name); ok {
+			slog.Info(fmt.Sprintf("config: %s set from %s", v.Path, v.Name))
+		}
 	}
 
-	application, err := app.New(serverCfg)
+	store, err := app.OpenPersistence(context.Background(), serverCfg.Persistence)
 	if err != nil {
-		log.Fatalf("init app: %v", err)
+		return err
 	}
+	defer store.Close()
+	application, err := app.New(configs, store)
+	if err != nil {
+		return fmt.Errorf("init app: %w", err)
+	}
+	configs.OnServerChange(func(cfg config.ServerConfig) {
+		if err := application.ApplyServerConfig(cfg); err != nil {
+			slog.Error(fmt.Sprintf("config: apply server settings: %v", err))
+		}
+	})
 
-	healthMux := http.NewServeMux()
-	healthMux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+	expvar.Publish("ws_gateway", expvar.Func(func() any {
+		return application.Gateway.Metrics().Snapshot()
+	}))
+	expvar.Publish("router", expvar.Func(func() any {
+		return application.RouterMetrics.Snapshot()
+	}))
+	expvar.Publish("config", expvar.Func(func() any {
+		gameplay := configs.Gameplay()
+		return map[string]any{
+			"gameplay_version":   gameplay.Version,
+			"gameplay_loaded_at": gameplay.LoadedAt,
+		}
+	}))
+	expvar.Publish("mailboxes", expvar.Func(func() any {
+		out := make(map[string]any, len(application.Mailboxes))
+		for name, mb := range application.Mailboxes {
+			out[name] = mb.Snapshot()
+		}
+		return out
+	}))
+
+	httpMux := http.NewServeMux()
+	httpMux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if err := store.Ping(r.Context()); err != nil {
+			http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
 	})
+	httpMux.Handle("/debug/vars", expvar.Handler())
+	httpMux.Handle("/api/", application.API.Handler())
 	httpServer := &http.Server{
-		Addr:    serverCfg.HTTP.ListenAddr,
-		Handler: healthMux,
+		Addr:              serverCfg.HTTP.ListenAddr,
+		Handler:           httpMux,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       5 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       30 * time.Second,
 	}
 
 	wsMux := http.NewServeMux()
@@ -53,6 +178,31 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	// Background work outlives the signal so chat keeps persisting while
+	// the gateway drains.
+	appCtx, stopApp := context.WithCancel(context.Background())
+	appDone := make(chan struct{})
+	go func() {
+		defer close(appDone)
+		if err := application.Run(appCtx); err != nil && err != context.Canceled {
+			slog.Error(fmt.Sprintf("app: %v", err))
+		}
+	}()
+
+	go func() {
+		_ = configs.Watch(appCtx, configPollInterval)
+	}()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			slog.Info("config: reload requested")
+			if err := configs.Reload(); err != nil {
+				slog.Error(fmt.Sprintf("config: %v", err))
+			}
+		}
+	}()
+
 	errCh := make(chan error, 2)
 	go func() {
 		errCh <- httpServer.ListenAndServe()
@@ -65,17 +215,54 @@ func main() {
 		errCh <- wsServer.ListenAndServe()
 	}()
 
+	// A listener error still runs the full shutdown below and is returned
+	// once the store is no longer in use.
+	var serveErr error
 	select {
 	case <-ctx.Done():
-		log.Printf("shutdown requested")
+		slog.Info("shutdown requested")
 	case err := <-errCh:
 		if err != nil && err != http.ErrServerClosed {
-			log.Fatalf("server error: %v", err)
+			serveErr = fmt.Errorf("server error: %w", err)
 		}
 	}
 
+	// A second signal falls through to the default handler and kills the process.
+	stop()
+	slog.Info("draining websocket connections")
+	if err := application.Gateway.Drain(context.Background()); err != nil {
+		slog.Warn(fmt.Sprintf("drain: %v", err))
+	}
+
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	_ = httpServer.Shutdown(shutdownCtx)
 	_ = wsServer.Shutdown(shutdownCtx)
+	stopApp()
+	<-appDone
+	// store is closed by the deferred Close once chat persistence has stopped.
+	return serveErr
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "usage: server [flags]\n\nflags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nenvironment (override the -config file, overridden by flags):\n")
+	for _, v := range config.ServerEnvVars() {
+		fmt.Fprintf(out, "  %s\n\t%s\n", v.Name, v.Path)
+	}
+}
+
+// setLogLevel sets the minimum level of slog records. Every internal package
+// logs through the default slog logger: per-connection and routine sweep
+// lines are debug, lifecycle and config changes info, dropped work and slow
+// dispatches warn, failures error.
+func setLogLevel(level string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	slog.SetLogLoggerLevel(l)
+	return nil
 }
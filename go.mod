@@ -4,7 +4,21 @@ go 1.24.0
 
 require (
 	github.com/coder/websocket v1.8.12
+	github.com/lib/pq v1.10.9
 	golang.org/x/crypto v0.47.0
+	google.golang.org/protobuf v1.36.9
+	modernc.org/sqlite v1.38.2
 )
 
-require golang.org/x/sys v0.40.0 // indirect
+require (
+	github.com/dustin/go-humanize v1.0.1 // indirect
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/ncruces/go-strftime v0.1.9 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20230129092748-24d4a6f8daec // indirect
+	golang.org/x/exp v0.0.0-20250620022241-b7579e27df2b // indirect
+	golang.org/x/sys v0.40.0 // indirect
+	modernc.org/libc v1.66.3 // indirect
+	modernc.org/mathutil v1.7.1 // indirect
+	modernc.org/memory v1.11.0 // indirect
+)
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"example.com/mvp-repo/internal/auth"
+	"example.com/mvp-repo/internal/chat"
+	"example.com/mvp-repo/internal/config"
+	"example.com/mvp-repo/internal/httpapi"
+	"example.com/mvp-repo/internal/loadout"
+)
+
+// newAPI builds the auth service and the HTTP API on store; chatSvc backs the
+// block-list endpoints.
+func newAPI(configs *config.Manager, store *Persistence, chatSvc *chat.Service) (*auth.Service, *httpapi.Server, error) {
+	serverCfg := configs.Server()
+	mailer, err := newMailer(serverCfg.Auth.PasswordReset)
+	if err != nil {
+		return nil, nil, err
+	}
+	authSvc, err := auth.NewService(store.Accounts, store.Sessions, store.PasswordResets, auth.Config{
+		TokenBytes:   serverCfg.Auth.SessionTokenBytes,
+		TokenTTL:     time.Duration(serverCfg.Auth.SessionTTLSeconds) * time.Second,
+		ResetTTL:     time.Duration(serverCfg.Auth.PasswordReset.TTLSeconds) * time.Second,
+		ResetURL:     serverCfg.Auth.PasswordReset.LinkURL,
+		Mailer:       mailer,
+		ReapInterval: time.Duration(serverCfg.Auth.SessionReaper.IntervalSeconds) * time.Second,
+		ReapBatch:    serverCfg.Auth.SessionReaper.BatchSize,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	loadouts, err := httpapi.NewRepoLoadouts(store.Loadouts)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules, err := newGameplayLoadouts(configs)
+	if err != nil {
+		return nil, nil, err
+	}
+	api, err := httpapi.NewServer(httpapi.Config{}, authSvc, loadouts, rules, chatSvc)
+	if err != nil {
+		return nil, nil, err
+	}
+	return authSvc, api, nil
+}
+
+func newMailer(cfg config.PasswordResetConfig) (auth.Mailer, error) {
+	if cfg.Mailer == "file" {
+		return auth.NewFileMailer(cfg.MailFile)
+	}
+	return auth.LogMailer{}, nil
+}
+
+// gameplayLoadouts validates loadouts against the newest gameplay version,
+// rebuilding the validator after a reload publishes a new one. A version the
+// validator rejects is logged and the previous validator stays in use.
+type gameplayLoadouts struct {
+	configs   *config.Manager
+	mu        sync.Mutex
+	version   uint64
+	validator *loadout.Validator
+}
+
+func newGameplayLoadouts(configs *config.Manager) (*gameplayLoadouts, error) {
+	gameplay := configs.Gameplay()
+	validator, err := loadout.New(*gameplay.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &gameplayLoadouts{configs: configs, version: gameplay.Version, validator: validator}, nil
+}
+
+func (g *gameplayLoadouts) Normalize(in loadout.Loadout) (loadout.Loadout, error) {
+	return g.current().Normalize(in)
+}
+
+func (g *gameplayLoadouts) current() *loadout.Validator {
+	gameplay := g.configs.Gameplay()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if gameplay.Version != g.version {
+		g.version = gameplay.Version
+		validator, err := loadout.New(*gameplay.Config)
+		if err != nil {
+			slog.Error("app: loadout rules rejected; keeping previous rules", "gameplay_version", gameplay.Version, "err", err)
+		} else {
+			g.validator = validator
+		}
+	}
+	return g.validator
+}
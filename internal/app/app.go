@@ -1,77 +1,373 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
 
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"example.com/mvp-repo/internal/auth"
+	"example.com/mvp-repo/internal/chat"
 	"example.com/mvp-repo/internal/config"
+	"example.com/mvp-repo/internal/httpapi"
+	"example.com/mvp-repo/internal/persist"
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/protocol"
 	"example.com/mvp-repo/internal/router"
 	"example.com/mvp-repo/internal/ws_gateway"
 )
 
+// slowDispatchThreshold is the handler latency above which dispatches are logged.
+const slowDispatchThreshold = 50 * time.Millisecond
+
+// helloAuthTimeout bounds the session lookup for a HELLO token.
+const helloAuthTimeout = 5 * time.Second
+
 type App struct {
-	Router  *router.Router
-	Gateway *ws_gateway.Server
+	Router        *router.Router
+	RouterMetrics *router.Metrics
+	Gateway       *ws_gateway.Server
+	Chat          *chat.Service
+	Store         *Persistence
+	Auth          *auth.Service
+	API           *httpapi.Server
+	// Mailboxes is keyed by subsystem name; empty in inline dispatch mode.
+	Mailboxes map[string]*router.Mailbox
 }
 
-func New(serverCfg config.ServerConfig) (*App, error) {
+// New wires the realtime stack and the HTTP API on store. configs supplies
+// the server settings at startup and the current gameplay rules thereafter.
+func New(configs *config.Manager, store *Persistence) (*App, error) {
+	serverCfg := configs.Server()
+	chatSvc := chat.NewService(chat.Config{
+		MaxTextBytes:     serverCfg.Chat.MaxTextBytes,
+		Filter:           chatFilter(serverCfg.Chat),
+		Mutes:            store.Mutes,
+		Blocks:           store.Blocks,
+		History:          store.ChatMessages,
+		HistorySize:      serverCfg.Chat.History.RingSize,
+		BackfillMessages: serverCfg.Chat.History.BackfillMessages,
+		Retention:        time.Duration(serverCfg.Chat.History.RetentionHours) * time.Hour,
+		PruneInterval:    time.Duration(serverCfg.Chat.History.PruneIntervalSeconds) * time.Second,
+	})
+	authSvc, api, err := newAPI(configs, store, chatSvc)
+	if err != nil {
+		return nil, err
+	}
 	r := router.New()
-	auth := authHandler{}
+	routerMetrics := &router.Metrics{}
+	offered, _ := protocol.ParseFeatures(serverCfg.Protocol.Features)
+	auth := authHandler{
+		minVersion: serverCfg.Protocol.MinClientVersion,
+		features:   offered,
+		build:      buildVersion(),
+		sessions:   authSvc,
+	}
 	world := worldHandler{}
-	chat := chatHandler{}
 	battle := battleHandler{}
 
+	r.Use(
+		router.Recover(),
+		router.Trace(slowDispatchThreshold),
+		routerMetrics.Middleware(),
+		r.BattleGate(battle.InBattle),
+	)
 	r.RegisterAuth(auth)
 	r.RegisterWorld(world)
-	r.RegisterChat(chat)
+	r.RegisterChat(chatSvc)
 	r.RegisterBattle(battle)
+	r.RegisterSession(chatSvc)
+	r.RegisterDrain(battle)
+	mailboxes := routeMailboxes(r, serverCfg.Dispatch)
 
 	gwCfg := ws_gateway.Config{
-		ReadLimitBytes:         serverCfg.WS.ReadLimitBytes,
-		WriteQueueMaxFrames:    serverCfg.WS.WriteQueueMaxFrames,
+		ReadLimitBytes: serverCfg.WS.ReadLimitBytes,
+		WriteQueues: ws_gateway.QueueLimits{
+			Control: serverCfg.WS.WriteQueues.ControlMaxFrames,
+			Battle:  serverCfg.WS.WriteQueues.BattleMaxFrames,
+			World:   serverCfg.Overworld.Replication.MaxPendingOverworldDeltasPerClient,
+			Chat:    serverCfg.WS.WriteQueues.ChatMaxFrames,
+		},
 		OverworldDeltaCoalesce: serverCfg.WS.OverworldDeltaCoalesce,
+		Compression: ws_gateway.Compression{
+			Mode:            ws_gateway.CompressionMode(serverCfg.WS.Compression.Mode),
+			ThresholdBytes:  serverCfg.WS.Compression.ThresholdBytes,
+			PayloadDeflate:  serverCfg.WS.Compression.PayloadDeflate,
+			PayloadMinBytes: serverCfg.WS.Compression.PayloadMinBytes,
+		},
+		Limits:            gatewayLimits(serverCfg.WS.Limits),
+		ReadTimeout:       seconds(serverCfg.WS.Heartbeat.ReadTimeoutSeconds),
+		WriteTimeout:      seconds(serverCfg.WS.Heartbeat.WriteTimeoutSeconds),
+		HeartbeatInterval: seconds(serverCfg.WS.Heartbeat.IntervalSeconds),
+		PongTimeout:       seconds(serverCfg.WS.Heartbeat.PongTimeoutSeconds),
+		IdleTimeout:       seconds(serverCfg.WS.Heartbeat.IdleTimeoutSeconds),
+		Drain: ws_gateway.DrainConfig{
+			Timeout:        seconds(serverCfg.WS.Drain.TimeoutSeconds),
+			NoticeInterval: seconds(serverCfg.WS.Drain.NoticeIntervalSeconds),
+			FlushTimeout:   seconds(serverCfg.WS.Drain.FlushTimeoutSeconds),
+		},
+		Batching: ws_gateway.Batching{
+			MaxRecords: serverCfg.WS.Batching.MaxRecords,
+			MaxBytes:   serverCfg.WS.Batching.MaxBytes,
+		},
 	}
 	gateway, err := ws_gateway.New(gwCfg, r)
 	if err != nil {
 		return nil, err
 	}
+	authSvc.OnRevoke(disconnectRevoked(gateway))
 	return &App{
-		Router:  r,
-		Gateway: gateway,
+		Router:        r,
+		RouterMetrics: routerMetrics,
+		Gateway:       gateway,
+		Chat:          chatSvc,
+		Store:         store,
+		Auth:          authSvc,
+		API:           api,
+		Mailboxes:     mailboxes,
 	}, nil
 }
 
-type authHandler struct{}
+// ApplyServerConfig applies the server settings that may change while
+// running (see config.Manager): the gateway limits.
+func (a *App) ApplyServerConfig(serverCfg config.ServerConfig) error {
+	return a.Gateway.SetLimits(gatewayLimits(serverCfg.WS.Limits))
+}
 
-type worldHandler struct{}
+// Run drives background work owned by the app (mailbox workers, chat
+// persistence and retention, the expired-session reaper) until ctx is
+// cancelled.
+func (a *App) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, mb := range a.Mailboxes {
+		wg.Add(1)
+		go func(mb *router.Mailbox) {
+			defer wg.Done()
+			_ = mb.Run(ctx)
+		}(mb)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = a.Auth.Run(ctx)
+	}()
+	err := a.Chat.Run(ctx)
+	wg.Wait()
+	return err
+}
+
+// routeMailboxes moves world, battle and chat handlers onto mailboxes when
+// dispatch.mode is "mailbox". World gets a single owner; battle inputs are
+// ordered per battle so each instance processes turns serially.
+func routeMailboxes(r *router.Router, cfg config.DispatchConfig) map[string]*router.Mailbox {
+	if cfg.Mode != "mailbox" {
+		return nil
+	}
+	world := router.NewMailbox("world", router.MailboxConfig{Shards: cfg.World.Shards, QueueSize: cfg.World.QueueSize})
+	battle := router.NewMailbox("battle", router.MailboxConfig{Shards: cfg.Battle.Shards, QueueSize: cfg.Battle.QueueSize, Key: battleKey})
+	chatMB := router.NewMailbox("chat", router.MailboxConfig{Shards: cfg.Chat.Shards, QueueSize: cfg.Chat.QueueSize})
+	r.Route(protocol.MSG_WORLD_MOVE_INTENT, world)
+	r.Route(protocol.MSG_BATTLE_TURN_INPUT, battle)
+	r.Route(protocol.MSG_CHAT_SEND, chatMB)
+	return map[string]*router.Mailbox{"world": world, "battle": battle, "chat": chatMB}
+}
+
+// battleKey orders battle inputs by BattleTurnInput.battle_id (field 1),
+// falling back to the player when the field is missing or malformed.
+func battleKey(ctx router.Context, payload []byte) uint64 {
+	for len(payload) > 0 {
+		num, typ, n := protowire.ConsumeTag(payload)
+		if n < 0 {
+			break
+		}
+		payload = payload[n:]
+		if num == 1 && typ == protowire.VarintType {
+			v, n := protowire.ConsumeVarint(payload)
+			if n < 0 {
+				break
+			}
+			return v
+		}
+		n = protowire.ConsumeFieldValue(num, typ, payload)
+		if n < 0 {
+			break
+		}
+		payload = payload[n:]
+	}
+	return ctx.PlayerID
+}
+
+func seconds(v float64) time.Duration {
+	return time.Duration(v * float64(time.Second))
+}
+
+func gatewayLimits(cfg config.WSLimitsConfig) ws_gateway.Limits {
+	limits := ws_gateway.Limits{
+		AllowedOrigins: cfg.AllowedOrigins,
+		MaxConnsPerIP:  cfg.MaxConnsPerIP,
+		Handshake:      ws_gateway.RateLimit(cfg.Handshake),
+		Messages:       ws_gateway.RateLimit(cfg.Messages),
+	}
+	if len(cfg.ClassLimits) > 0 {
+		limits.ClassLimits = make(map[router.RateClass]ws_gateway.RateLimit, len(cfg.ClassLimits))
+		for name, limit := range cfg.ClassLimits {
+			class, ok := router.ParseRateClass(name)
+			if !ok {
+				continue
+			}
+			limits.ClassLimits[class] = ws_gateway.RateLimit(limit)
+		}
+	}
+	if len(cfg.MessageOverrides) > 0 {
+		limits.MessageOverrides = make(map[protocol.MsgType]ws_gateway.RateLimit, len(cfg.MessageOverrides))
+		for name, limit := range cfg.MessageOverrides {
+			msgType, ok := protocol.ParseMsgType(name)
+			if !ok {
+				continue
+			}
+			limits.MessageOverrides[msgType] = ws_gateway.RateLimit(limit)
+		}
+	}
+	return limits
+}
+
+// disconnectRevoked closes the sockets bound to revoked sessions: the one
+// token on logout, every token-bound socket of the user otherwise.
+func disconnectRevoked(gateway *ws_gateway.Server) func(auth.Revocation) {
+	return func(rv auth.Revocation) {
+		if rv.Token != "" {
+			gateway.DisconnectSession(rv.Token)
+			return
+		}
+		gateway.DisconnectPlayer(uint64(rv.UserID))
+	}
+}
+
+func chatFilter(cfg config.ChatConfig) chat.Filter {
+	pipeline := chat.Pipeline{}
+	if cfg.StripLinks {
+		pipeline = append(pipeline, chat.LinkFilter{})
+	}
+	if len(cfg.BannedWords) > 0 {
+		pipeline = append(pipeline, chat.NewWordFilter(cfg.BannedWords, cfg.RejectProfanity))
+	}
+	return pipeline
+}
+
+type authHandler struct {
+	minVersion uint32
+	features   protocol.Features
+	build      string
+	sessions   tokenValidator
+}
+
+var errHelloWithoutToken = errors.New("app: hello without token")
 
-type chatHandler struct{}
+// tokenValidator checks a HELLO token; *auth.Service implements it.
+type tokenValidator interface {
+	ValidateToken(ctx context.Context, token string) (persist.Session, error)
+}
+
+type worldHandler struct{}
 
 type battleHandler struct{}
 
-func (authHandler) HandleHello(ctx router.Context, payload []byte) error {
-	_ = payload
+// HandleHello negotiates the protocol version and optional features, records
+// them on the connection and replies with Welcome. Incompatible clients are
+// refused with ERR_UNSUPPORTED_VERSION, which closes the connection. The
+// token must be a live session and binds the connection to its user; a
+// missing, invalid or expired token is refused with ERR_UNAUTHENTICATED,
+// which closes the connection before it is bound.
+func (a authHandler) HandleHello(ctx router.Context, payload []byte) error {
 	if ctx.Sender == nil {
 		return fmt.Errorf("app: sender required")
 	}
-	return nil
+	hello, err := router.Decode[gen.Hello](payload)
+	if err != nil {
+		return router.Reject(protocol.ERR_MALFORMED, err)
+	}
+	version := protocol.EffectiveVersion(hello.GetProtocolVersion())
+	offered, _ := protocol.ParseFeatures(hello.GetFeatures())
+	token := string(hello.GetToken())
+	router.Release(hello)
+	if version < a.minVersion || version > protocol.ProtocolVersion {
+		return router.Reject(protocol.ERR_UNSUPPORTED_VERSION,
+			fmt.Errorf("app: protocol version %d not in [%d, %d]", version, a.minVersion, protocol.ProtocolVersion))
+	}
+	if token == "" {
+		return router.Reject(protocol.ERR_UNAUTHENTICATED, errHelloWithoutToken)
+	}
+	userID, err := a.authenticate(token)
+	if err != nil {
+		return err
+	}
+	playerID := uint64(userID)
+	features := a.features & offered
+	if binder, ok := ctx.Sender.(router.SessionBinder); ok {
+		binder.BindSession(router.Session{PlayerID: playerID, ProtocolVersion: version, Features: features, Token: token})
+	}
+	return router.SendTyped(ctx.Sender, &gen.Welcome{
+		PlayerId:        playerID,
+		ServerTimeS:     uint32(time.Now().Unix()),
+		ProtocolVersion: protocol.ProtocolVersion,
+		ServerBuild:     a.build,
+		Features:        features.Names(),
+	})
 }
 
-func (worldHandler) HandleMoveIntent(ctx router.Context, payload []byte) error {
-	_ = payload
-	if ctx.Sender == nil {
-		return fmt.Errorf("app: sender required")
+func (a authHandler) authenticate(token string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), helloAuthTimeout)
+	defer cancel()
+	session, err := a.sessions.ValidateToken(ctx, token)
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidToken) || errors.Is(err, auth.ErrTokenExpired) {
+			return 0, router.Reject(protocol.ERR_UNAUTHENTICATED, err)
+		}
+		return 0, err
 	}
-	_ = ctx.Sender.Close("world handler not implemented")
-	return router.ErrUnimplemented
+	return session.UserID, nil
 }
 
-func (chatHandler) HandleChatSend(ctx router.Context, payload []byte) error {
+// buildVersion identifies the server binary from its VCS stamp.
+func buildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			return setting.Value[:12]
+		}
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+func (worldHandler) HandleMoveIntent(ctx router.Context, payload []byte) error {
 	_ = payload
 	if ctx.Sender == nil {
 		return fmt.Errorf("app: sender required")
 	}
-	_ = ctx.Sender.Close("chat handler not implemented")
-	return router.ErrUnimplemented
+	return router.Reject(protocol.ERR_UNIMPLEMENTED, router.ErrUnimplemented)
+}
+
+// InBattle reports whether playerID is in a battle. Battles are not hosted
+// yet, so nobody is.
+func (battleHandler) InBattle(playerID uint64) bool {
+	_ = playerID
+	return false
+}
+
+// HandleDrain is where in-flight battles finish or checkpoint before
+// shutdown. Battles are not hosted yet, so there is nothing to wait for.
+func (battleHandler) HandleDrain(ctx context.Context) error {
+	return ctx.Err()
 }
 
 func (battleHandler) HandleTurnInput(ctx router.Context, payload []byte) error {
@@ -79,6 +375,5 @@ func (battleHandler) HandleTurnInput(ctx router.Context, payload []byte) error {
 	if ctx.Sender == nil {
 		return fmt.Errorf("app: sender required")
 	}
-	_ = ctx.Sender.Close("battle handler not implemented")
-	return router.ErrUnimplemented
+	return router.Reject(protocol.ERR_UNIMPLEMENTED, router.ErrUnimplemented)
 }
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/auth"
+	"example.com/mvp-repo/internal/persist"
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/router"
+)
+
+type fakeSessions map[string]int64
+
+func (f fakeSessions) ValidateToken(_ context.Context, token string) (persist.Session, error) {
+	userID, ok := f[token]
+	if !ok {
+		return persist.Session{}, auth.ErrInvalidToken
+	}
+	return persist.Session{UserID: userID}, nil
+}
+
+// helloSender records what HandleHello binds and sends.
+type helloSender struct {
+	bound *router.Session
+	sent  []protocol.MsgType
+}
+
+func (s *helloSender) Send(msgType protocol.MsgType, _ []byte) error {
+	s.sent = append(s.sent, msgType)
+	return nil
+}
+
+func (s *helloSender) Close(string) error { return nil }
+
+func (s *helloSender) BindSession(sess router.Session) { s.bound = &sess }
+
+func TestHelloRequiresToken(t *testing.T) {
+	a := authHandler{minVersion: protocol.ProtocolVersion, sessions: fakeSessions{"live": 42}}
+	for _, tc := range []struct {
+		name   string
+		token  string
+		player uint64
+		code   protocol.ErrorCode
+	}{
+		{name: "no token", code: protocol.ERR_UNAUTHENTICATED},
+		{name: "invalid token", token: "stale", code: protocol.ERR_UNAUTHENTICATED},
+		{name: "live token", token: "live", player: 42},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, err := proto.Marshal(&gen.Hello{ProtocolVersion: protocol.ProtocolVersion, Token: []byte(tc.token)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sender := &helloSender{}
+			err = a.HandleHello(router.Context{Sender: sender}, payload)
+			if tc.code != 0 {
+				var rej *router.Rejection
+				if !errors.As(err, &rej) || rej.Code != tc.code {
+					t.Fatalf("HandleHello = %v, want rejection %s", err, tc.code)
+				}
+				if sender.bound != nil || len(sender.sent) != 0 {
+					t.Fatalf("refused HELLO bound %+v and sent %v", sender.bound, sender.sent)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("HandleHello = %v", err)
+			}
+			if sender.bound == nil || sender.bound.PlayerID != tc.player || sender.bound.Token != tc.token {
+				t.Fatalf("bound %+v, want player %d with token %q", sender.bound, tc.player, tc.token)
+			}
+			if len(sender.sent) != 1 || sender.sent[0] != protocol.MSG_WELCOME {
+				t.Fatalf("sent %v, want [MSG_WELCOME]", sender.sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"example.com/mvp-repo/internal/config"
+	"example.com/mvp-repo/internal/persist"
+)
+
+// ErrDriverNotRegistered means the binary has no database/sql driver under
+// the configured name.
+var ErrDriverNotRegistered = errors.New("app: database driver not registered")
+
+// Persistence is the opened database and the repos built on it.
+type Persistence struct {
+	DB             *sql.DB
+	Dialect        persist.Dialect
+	Accounts       *persist.AccountsRepo
+	Sessions       *persist.SessionsRepo
+	PasswordResets *persist.PasswordResetsRepo
+	Loadouts       *persist.LoadoutsRepo
+	Progression    *persist.ProgressionRepo
+	Unlocks        *persist.UnlocksRepo
+	ChatMessages   *persist.ChatMessagesRepo
+	Mutes          *persist.MutesRepo
+	Blocks         *persist.BlocksRepo
+}
+
+// OpenPersistence opens the database selected by persistence.env, applies
+// pending migrations and builds the repos. The driver must be registered by
+// the binary (DECISION 0009).
+func OpenPersistence(ctx context.Context, cfg config.PersistenceConfig) (*Persistence, error) {
+	driver, dbCfg := cfg.Selected()
+	if !slices.Contains(sql.Drivers(), driver) {
+		return nil, fmt.Errorf("%w: %q (registered: %v)", ErrDriverNotRegistered, driver, sql.Drivers())
+	}
+	dialect := persist.DialectSQLite
+	if cfg.Env == "prod" {
+		dialect = persist.DialectPostgres
+	}
+	db, err := persist.Open(ctx, persist.Config{
+		Driver:          driver,
+		DSN:             dbCfg.DSN,
+		Dialect:         dialect,
+		MaxOpenConns:    dbCfg.MaxOpenConns,
+		MaxIdleConns:    dbCfg.MaxIdleConns,
+		ConnMaxLifetime: seconds(dbCfg.ConnMaxLifetimeSeconds),
+		ConnMaxIdleTime: seconds(dbCfg.ConnMaxIdleTimeSeconds),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("app: open %s database: %w", cfg.Env, err)
+	}
+	if err := persist.Migrate(ctx, db, dialect); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &Persistence{
+		DB:             db,
+		Dialect:        dialect,
+		Accounts:       persist.NewAccountsRepo(db, dialect),
+		Sessions:       persist.NewSessionsRepo(db, dialect),
+		PasswordResets: persist.NewPasswordResetsRepo(db, dialect),
+		Loadouts:       persist.NewLoadoutsRepo(db, dialect),
+		Progression:    persist.NewProgressionRepo(db, dialect),
+		Unlocks:        persist.NewUnlocksRepo(db, dialect),
+		ChatMessages:   persist.NewChatMessagesRepo(db, dialect),
+		Mutes:          persist.NewMutesRepo(db, dialect),
+		Blocks:         persist.NewBlocksRepo(db, dialect),
+	}, nil
+}
+
+// Close closes the database; call it after everything using the repos has
+// stopped.
+func (p *Persistence) Close() error {
+	return p.DB.Close()
+}
+
+// Ping reports database health for /health.
+func (p *Persistence) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	return persist.Ping(ctx, p.DB)
+}
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/router"
+)
+
+func TestBattleKey(t *testing.T) {
+	const player = 77
+	marshal := func(m *gen.BattleTurnInput) []byte {
+		b, err := proto.Marshal(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	tests := []struct {
+		name    string
+		payload []byte
+		want    uint64
+	}{
+		{"battle id", marshal(&gen.BattleTurnInput{BattleId: 9, TurnSeq: 3}), 9},
+		{"battle id after other fields", append(protowire.AppendVarint(protowire.AppendTag(nil, 2, protowire.VarintType), 3), marshal(&gen.BattleTurnInput{BattleId: 9})...), 9},
+		{"missing battle id", marshal(&gen.BattleTurnInput{TurnSeq: 3, MovePieceId: 4}), player},
+		{"empty payload", nil, player},
+		{"truncated varint", []byte{0x08, 0xff}, player},
+		{"bad tag", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, player},
+		{"battle id with wrong wire type", protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), []byte("x")), player},
+		{"truncated field before battle id", []byte{0x12, 0x05, 0x01}, player},
+	}
+	for _, tc := range tests {
+		if got := battleKey(router.Context{PlayerID: player}, tc.payload); got != tc.want {
+			t.Errorf("%s: battleKey = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// File: internal/auth/mailer.go
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers account mail. The implementations here are for local
+// development: both expose live reset tokens to whoever reads the output.
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// LogMailer writes each mail to the default slog logger at info level.
+type LogMailer struct{}
+
+func (LogMailer) Send(_ context.Context, mail Mail) error {
+	slog.Info("auth: mail", "to", mail.To, "subject", mail.Subject, "body", mail.Body)
+	return nil
+}
+
+// FileMailer appends each mail to a file, separated by a header line.
+type FileMailer struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileMailer(path string) (*FileMailer, error) {
+	if path == "" {
+		return nil, fmt.Errorf("auth: mail file path required")
+	}
+	return &FileMailer{path: path}, nil
+}
+
+func (m *FileMailer) Send(_ context.Context, mail Mail) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	file, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "--- %s\nTo: %s\nSubject: %s\n\n%s\n\n", time.Now().UTC().Format(time.RFC3339), mail.To, mail.Subject, mail.Body)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+// File: internal/auth/reset.go
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"example.com/mvp-repo/internal/persist"
+)
+
+var ErrInvalidResetToken = errors.New("auth: invalid or expired reset token")
+
+const (
+	// resetQueueSize bounds the reset requests waiting for Run; more are
+	// dropped (and logged) rather than slowing the request path.
+	resetQueueSize = 64
+	// resetSendTimeout bounds the lookup, token write and mail of one reset.
+	resetSendTimeout = 30 * time.Second
+)
+
+// RequestPasswordReset queues a reset for email and returns without touching
+// the database: Run looks the account up and mails it a single-use token.
+// Known and unknown emails take the same path and the same time, so callers
+// can answer every request the same way without revealing which emails are
+// registered. Issuing a token drops the account's earlier ones.
+func (s *Service) RequestPasswordReset(_ context.Context, email string) error {
+	if email == "" {
+		return ErrMissingIdentifiers
+	}
+	select {
+	case s.resetRequests <- email:
+	default:
+		slog.Warn("auth: password reset queue full, request dropped")
+	}
+	return nil
+}
+
+// issueReset handles one queued request with its own deadline.
+func (s *Service) issueReset(ctx context.Context, email string) {
+	ctx, cancel := context.WithTimeout(ctx, resetSendTimeout)
+	defer cancel()
+	if err := s.sendReset(ctx, email); err != nil {
+		slog.Error("auth: password reset", "err", err)
+	}
+}
+
+// sendReset mails a reset token if email belongs to an account; an unknown
+// email is not an error.
+func (s *Service) sendReset(ctx context.Context, email string) error {
+	account, err := s.accounts.GetByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, persist.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	token, err := s.tokens.NewToken()
+	if err != nil {
+		return err
+	}
+	if _, err := s.resets.DeleteByUser(ctx, account.UserID); err != nil {
+		return err
+	}
+	now := s.now()
+	expiresAt := now.Add(s.resetTTL)
+	if err := s.resets.Create(ctx, persist.PasswordReset{
+		TokenHash: HashToken(token),
+		UserID:    account.UserID,
+		CreatedAt: now.Unix(),
+		ExpiresAt: expiresAt.Unix(),
+	}); err != nil {
+		return err
+	}
+	return s.mailer.Send(ctx, Mail{
+		To:      account.Email,
+		Subject: "Reset your password",
+		Body:    s.resetBody(account.Username, token),
+	})
+}
+
+// CompletePasswordReset sets a new password using a token from
+// RequestPasswordReset and revokes every session of the account. The token
+// is consumed even if a later step fails.
+func (s *Service) CompletePasswordReset(ctx context.Context, token string, newPassword string) error {
+	if token == "" {
+		return ErrInvalidResetToken
+	}
+	hash, err := HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	userID, err := s.resets.Consume(ctx, HashToken(token), s.now().Unix())
+	if err != nil {
+		if errors.Is(err, persist.ErrNotFound) {
+			return ErrInvalidResetToken
+		}
+		return err
+	}
+	if err := s.accounts.UpdatePassHash(ctx, userID, []byte(hash)); err != nil {
+		return err
+	}
+	_, err = s.RevokeAllSessions(ctx, userID)
+	return err
+}
+
+func (s *Service) resetBody(username string, token string) string {
+	minutes := int(s.resetTTL.Minutes())
+	if s.resetURL == nil {
+		return fmt.Sprintf("Hi %s,\n\nUse this code within %d minutes to choose a new password:\n\n%s\n\nIf you did not ask for a reset, ignore this mail.", username, minutes, token)
+	}
+	link := *s.resetURL
+	q := link.Query()
+	q.Set("token", token)
+	link.RawQuery = q.Encode()
+	return fmt.Sprintf("Hi %s,\n\nOpen this link within %d minutes to choose a new password:\n\n%s\n\nIf you did not ask for a reset, ignore this mail.", username, minutes, link.String())
+}
@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+)
+
+var resetLink = regexp.MustCompile(`https://example\.com/reset\S*`)
+
+// requestReset asks for a reset and returns the token from the mail.
+func (s *testService) requestReset(t *testing.T, email string) string {
+	t.Helper()
+	if err := s.RequestPasswordReset(context.Background(), email); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case mail := <-s.mail:
+		link, err := url.Parse(resetLink.FindString(mail.Body))
+		if err != nil || link.Query().Get("token") == "" {
+			t.Fatalf("no reset link in %q", mail.Body)
+		}
+		return link.Query().Get("token")
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reset mail")
+		return ""
+	}
+}
+
+func TestRequestPasswordResetIsQueued(t *testing.T) {
+	s := newTestService(t, Config{ResetURL: "https://example.com/reset"})
+	s.register(t, "ada")
+	ctx := context.Background()
+
+	// Known and unknown emails return before any lookup or mail: nothing
+	// is sent until Run picks the request up.
+	for _, email := range []string{"ada@example.com", "nobody@example.com"} {
+		if err := s.RequestPasswordReset(ctx, email); err != nil {
+			t.Fatalf("RequestPasswordReset(%s) = %v", email, err)
+		}
+	}
+	if len(s.mail) != 0 {
+		t.Fatalf("mail sent on the request path")
+	}
+	if err := s.RequestPasswordReset(ctx, ""); !errors.Is(err, ErrMissingIdentifiers) {
+		t.Fatalf("empty email = %v, want ErrMissingIdentifiers", err)
+	}
+
+	s.run(t)
+	select {
+	case mail := <-s.mail:
+		if mail.To != "ada@example.com" {
+			t.Fatalf("mail to %s", mail.To)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("queued reset never mailed")
+	}
+	select {
+	case mail := <-s.mail:
+		t.Fatalf("unknown email got mail: %+v", mail)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestResetTokenIsSingleUse(t *testing.T) {
+	s := newTestService(t, Config{ResetURL: "https://example.com/reset"})
+	s.register(t, "ada")
+	s.run(t)
+	ctx := context.Background()
+
+	token := s.requestReset(t, "ada@example.com")
+	if err := s.CompletePasswordReset(ctx, token, "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CompletePasswordReset(ctx, token, "other-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("second use = %v, want ErrInvalidResetToken", err)
+	}
+	if _, err := s.LoginByUsername(ctx, "ada", "new-password", ClientInfo{}); err != nil {
+		t.Fatalf("login with reset password: %v", err)
+	}
+}
+
+func TestResetTokenExpires(t *testing.T) {
+	s := newTestService(t, Config{ResetURL: "https://example.com/reset", ResetTTL: 10 * time.Minute})
+	s.register(t, "ada")
+	s.run(t)
+	ctx := context.Background()
+
+	token := s.requestReset(t, "ada@example.com")
+	s.clock.Advance(10 * time.Minute)
+	if err := s.CompletePasswordReset(ctx, token, "new-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("expired token = %v, want ErrInvalidResetToken", err)
+	}
+	if _, err := s.LoginByUsername(ctx, "ada", "password-ada", ClientInfo{}); err != nil {
+		t.Fatalf("password changed by an expired token: %v", err)
+	}
+}
+
+func TestNewResetReplacesOlderToken(t *testing.T) {
+	s := newTestService(t, Config{ResetURL: "https://example.com/reset"})
+	s.register(t, "ada")
+	s.run(t)
+
+	old := s.requestReset(t, "ada@example.com")
+	s.requestReset(t, "ada@example.com")
+	if err := s.CompletePasswordReset(context.Background(), old, "new-password"); !errors.Is(err, ErrInvalidResetToken) {
+		t.Fatalf("replaced token = %v, want ErrInvalidResetToken", err)
+	}
+}
+
+func TestCompletePasswordResetRevokesEverySession(t *testing.T) {
+	s := newTestService(t, Config{ResetURL: "https://example.com/reset"})
+	first := s.register(t, "ada")
+	second := s.login(t, "ada")
+	other := s.register(t, "bob")
+	revocations := s.recordRevocations()
+	s.run(t)
+	ctx := context.Background()
+
+	token := s.requestReset(t, "ada@example.com")
+	if err := s.CompletePasswordReset(ctx, token, "new-password"); err != nil {
+		t.Fatal(err)
+	}
+	for _, session := range []IssuedSession{first, second} {
+		if _, err := s.ValidateToken(ctx, session.Token); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("session survived reset: %v", err)
+		}
+	}
+	if _, err := s.ValidateToken(ctx, other.Token); err != nil {
+		t.Fatalf("other account's session revoked: %v", err)
+	}
+	got := revocations()
+	if len(got) != 1 || got[0] != (Revocation{UserID: first.UserID}) {
+		t.Fatalf("revocations = %+v, want one for user %d", got, first.UserID)
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"crypto/subtle"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
 	"time"
 
 	"example.com/mvp-repo/internal/persist"
@@ -18,29 +22,67 @@ var (
 	ErrTokenExpired       = errors.New("auth: token expired")
 	ErrMissingIdentifiers = errors.New("auth: missing identifiers")
 	ErrTokenTTLRequired   = errors.New("auth: token ttl required")
+	ErrResetTTLRequired   = errors.New("auth: reset ttl required")
+	ErrMailerRequired     = errors.New("auth: mailer required")
 )
 
 type Service struct {
 	accounts *persist.AccountsRepo
 	sessions *persist.SessionsRepo
+	resets   *persist.PasswordResetsRepo
 	tokens   TokenGenerator
 	tokenTTL time.Duration
+	resetTTL time.Duration
+	resetURL *url.URL
+	mailer   Mailer
 	now      func() time.Time
+
+	resetRequests chan string
+
+	reapInterval time.Duration
+	reapBatch    int
+
+	mu       sync.Mutex
+	onRevoke []func(Revocation)
 }
 
 type Config struct {
 	TokenBytes int
 	TokenTTL   time.Duration
-	Now        func() time.Time
+	// ResetTTL bounds how long a password reset token stays usable.
+	ResetTTL time.Duration
+	// ResetURL, if set, is the page reset mail links to; the token is added
+	// as the "token" query parameter.
+	ResetURL string
+	Mailer   Mailer
+	// ReapInterval and ReapBatch pace Run's expired-session sweep; zero
+	// values use DefaultReapInterval and DefaultReapBatch.
+	ReapInterval time.Duration
+	ReapBatch    int
+	Now          func() time.Time
 }
 
-func NewService(accounts *persist.AccountsRepo, sessions *persist.SessionsRepo, cfg Config) (*Service, error) {
-	if accounts == nil || sessions == nil {
+func NewService(accounts *persist.AccountsRepo, sessions *persist.SessionsRepo, resets *persist.PasswordResetsRepo, cfg Config) (*Service, error) {
+	if accounts == nil || sessions == nil || resets == nil {
 		return nil, errors.New("auth: repos required")
 	}
 	if cfg.TokenTTL <= 0 {
 		return nil, ErrTokenTTLRequired
 	}
+	if cfg.ResetTTL <= 0 {
+		return nil, ErrResetTTLRequired
+	}
+	if cfg.Mailer == nil {
+		return nil, ErrMailerRequired
+	}
+	var resetURL *url.URL
+	if cfg.ResetURL != "" {
+		u, err := url.Parse(cfg.ResetURL)
+		if err != nil || !u.IsAbs() {
+			return nil, fmt.Errorf("auth: reset url %q must be absolute", cfg.ResetURL)
+		}
+		resetURL = u
+	}
 	tokens, err := NewTokenGenerator(cfg.TokenBytes)
 	if err != nil {
 		return nil, err
@@ -49,32 +91,47 @@ func NewService(accounts *persist.AccountsRepo, sessions *persist.SessionsRepo,
 	if now == nil {
 		now = time.Now
 	}
+	if cfg.ReapInterval <= 0 {
+		cfg.ReapInterval = DefaultReapInterval
+	}
+	if cfg.ReapBatch <= 0 {
+		cfg.ReapBatch = DefaultReapBatch
+	}
 	return &Service{
 		accounts: accounts,
 		sessions: sessions,
+		resets:   resets,
 		tokens:   tokens,
 		tokenTTL: cfg.TokenTTL,
+		resetTTL: cfg.ResetTTL,
+		resetURL: resetURL,
+		mailer:   cfg.Mailer,
 		now:      now,
+
+		resetRequests: make(chan string, resetQueueSize),
+
+		reapInterval: cfg.ReapInterval,
+		reapBatch:    cfg.ReapBatch,
 	}, nil
 }
 
-func (s *Service) Register(ctx context.Context, email string, username string, password string) (persist.Session, error) {
+func (s *Service) Register(ctx context.Context, email string, username string, password string, client ClientInfo) (IssuedSession, error) {
 	if email == "" || username == "" {
-		return persist.Session{}, ErrMissingIdentifiers
+		return IssuedSession{}, ErrMissingIdentifiers
 	}
 	if _, err := s.accounts.GetByEmail(ctx, email); err == nil {
-		return persist.Session{}, ErrAccountExists
+		return IssuedSession{}, ErrAccountExists
 	} else if !errors.Is(err, persist.ErrNotFound) {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
 	if _, err := s.accounts.GetByUsername(ctx, username); err == nil {
-		return persist.Session{}, ErrAccountExists
+		return IssuedSession{}, ErrAccountExists
 	} else if !errors.Is(err, persist.ErrNotFound) {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
 	hash, err := HashPassword(password)
 	if err != nil {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
 	now := s.now().Unix()
 	account := persist.Account{
@@ -86,80 +143,88 @@ func (s *Service) Register(ctx context.Context, email string, username string, p
 	}
 	userID, err := s.accounts.Create(ctx, account)
 	if err != nil {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
-	return s.newSession(ctx, userID)
+	return s.newSession(ctx, userID, client)
 }
 
-func (s *Service) LoginByEmail(ctx context.Context, email string, password string) (persist.Session, error) {
+func (s *Service) LoginByEmail(ctx context.Context, email string, password string, client ClientInfo) (IssuedSession, error) {
 	if email == "" {
-		return persist.Session{}, ErrMissingIdentifiers
+		return IssuedSession{}, ErrMissingIdentifiers
 	}
 	account, err := s.accounts.GetByEmail(ctx, email)
 	if err != nil {
 		if errors.Is(err, persist.ErrNotFound) {
-			return persist.Session{}, ErrInvalidCredentials
+			return IssuedSession{}, ErrInvalidCredentials
 		}
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
 	ok, err := VerifyPassword(password, string(account.PassHash))
 	if err != nil {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
 	if !ok {
-		return persist.Session{}, ErrInvalidCredentials
+		return IssuedSession{}, ErrInvalidCredentials
 	}
 	now := s.now().Unix()
 	if err := s.accounts.UpdateLastLogin(ctx, account.UserID, now); err != nil {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
-	return s.newSession(ctx, account.UserID)
+	return s.newSession(ctx, account.UserID, client)
 }
 
-func (s *Service) LoginByUsername(ctx context.Context, username string, password string) (persist.Session, error) {
+func (s *Service) LoginByUsername(ctx context.Context, username string, password string, client ClientInfo) (IssuedSession, error) {
 	if username == "" {
-		return persist.Session{}, ErrMissingIdentifiers
+		return IssuedSession{}, ErrMissingIdentifiers
 	}
 	account, err := s.accounts.GetByUsername(ctx, username)
 	if err != nil {
 		if errors.Is(err, persist.ErrNotFound) {
-			return persist.Session{}, ErrInvalidCredentials
+			return IssuedSession{}, ErrInvalidCredentials
 		}
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
 	ok, err := VerifyPassword(password, string(account.PassHash))
 	if err != nil {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
 	if !ok {
-		return persist.Session{}, ErrInvalidCredentials
+		return IssuedSession{}, ErrInvalidCredentials
 	}
 	now := s.now().Unix()
 	if err := s.accounts.UpdateLastLogin(ctx, account.UserID, now); err != nil {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
-	return s.newSession(ctx, account.UserID)
+	return s.newSession(ctx, account.UserID, client)
 }
 
 func (s *Service) ValidateToken(ctx context.Context, token string) (persist.Session, error) {
 	if token == "" {
 		return persist.Session{}, ErrInvalidToken
 	}
-	session, err := s.sessions.Get(ctx, token)
+	hash := HashToken(token)
+	session, err := s.sessions.Get(ctx, hash)
 	if err != nil {
 		if errors.Is(err, persist.ErrNotFound) {
 			return persist.Session{}, ErrInvalidToken
 		}
 		return persist.Session{}, err
 	}
-	if subtle.ConstantTimeCompare([]byte(session.Token), []byte(token)) != 1 {
+	if subtle.ConstantTimeCompare(session.TokenHash, hash) != 1 {
 		return persist.Session{}, ErrInvalidToken
 	}
 	now := s.now().Unix()
 	if session.ExpiresAt <= now {
-		_ = s.sessions.Delete(ctx, token)
+		_ = s.sessions.Delete(ctx, hash)
 		return persist.Session{}, ErrTokenExpired
 	}
+	if now-session.LastSeenAt >= int64(lastSeenResolution.Seconds()) {
+		if err := s.sessions.Touch(ctx, hash, now); err != nil {
+			slog.Warn("auth: session last seen", "err", err)
+		} else {
+			session.LastSeenAt = now
+		}
+	}
 	return session, nil
 }
 
@@ -167,29 +232,33 @@ func (s *Service) RevokeToken(ctx context.Context, token string) error {
 	if token == "" {
 		return ErrInvalidToken
 	}
-	if err := s.sessions.Delete(ctx, token); err != nil {
+	if err := s.sessions.Delete(ctx, HashToken(token)); err != nil {
 		if errors.Is(err, persist.ErrNotFound) {
 			return ErrInvalidToken
 		}
 		return err
 	}
+	s.revoked(Revocation{Token: token})
 	return nil
 }
 
-func (s *Service) newSession(ctx context.Context, userID int64) (persist.Session, error) {
+func (s *Service) newSession(ctx context.Context, userID int64, client ClientInfo) (IssuedSession, error) {
 	token, err := s.tokens.NewToken()
 	if err != nil {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
 	now := s.now().Unix()
 	session := persist.Session{
-		Token:     token,
-		UserID:    userID,
-		CreatedAt: now,
-		ExpiresAt: now + int64(s.tokenTTL.Seconds()),
+		TokenHash:  HashToken(token),
+		UserID:     userID,
+		CreatedAt:  now,
+		ExpiresAt:  now + int64(s.tokenTTL.Seconds()),
+		LastSeenAt: now,
+		IP:         client.IP,
+		UserAgent:  client.UserAgent,
 	}
 	if err := s.sessions.Create(ctx, session); err != nil {
-		return persist.Session{}, err
+		return IssuedSession{}, err
 	}
-	return session, nil
+	return IssuedSession{Token: token, Session: session}, nil
 }
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/mvp-repo/internal/persist"
+	"example.com/mvp-repo/internal/persist/persisttest"
+)
+
+// testClock is a settable Config.Now.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// mailbox is a Mailer that hands each mail to the test.
+type mailbox chan Mail
+
+func (m mailbox) Send(_ context.Context, mail Mail) error {
+	m <- mail
+	return nil
+}
+
+type testService struct {
+	*Service
+	db    *sql.DB
+	clock *testClock
+	mail  mailbox
+}
+
+// newTestService builds a Service on a fresh SQLite database with a manual
+// clock; cfg's zero fields get test defaults.
+func newTestService(t *testing.T, cfg Config) *testService {
+	t.Helper()
+	db := persisttest.Open(t)
+	clock := &testClock{now: time.Unix(1_700_000_000, 0)}
+	mail := make(mailbox, 16)
+	if cfg.TokenTTL == 0 {
+		cfg.TokenTTL = time.Hour
+	}
+	if cfg.ResetTTL == 0 {
+		cfg.ResetTTL = time.Hour
+	}
+	cfg.Mailer = mail
+	cfg.Now = clock.Now
+	dialect := persist.DialectSQLite
+	s, err := NewService(persist.NewAccountsRepo(db, dialect), persist.NewSessionsRepo(db, dialect), persist.NewPasswordResetsRepo(db, dialect), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testService{Service: s, db: db, clock: clock, mail: mail}
+}
+
+// run starts Run until the test ends.
+func (s *testService) run(t *testing.T) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = s.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func (s *testService) register(t *testing.T, name string) IssuedSession {
+	t.Helper()
+	session, err := s.Register(context.Background(), name+"@example.com", name, "password-"+name, ClientInfo{IP: "192.0.2.1", UserAgent: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+func (s *testService) login(t *testing.T, name string) IssuedSession {
+	t.Helper()
+	session, err := s.LoginByUsername(context.Background(), name, "password-"+name, ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session
+}
+
+// recordRevocations collects every OnRevoke call.
+func (s *testService) recordRevocations() func() []Revocation {
+	var mu sync.Mutex
+	var got []Revocation
+	s.OnRevoke(func(rv Revocation) {
+		mu.Lock()
+		got = append(got, rv)
+		mu.Unlock()
+	})
+	return func() []Revocation {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Revocation(nil), got...)
+	}
+}
@@ -0,0 +1,118 @@
+// File: internal/auth/sessions.go
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"example.com/mvp-repo/internal/persist"
+)
+
+const (
+	DefaultReapInterval = 5 * time.Minute
+	DefaultReapBatch    = 500
+)
+
+// lastSeenResolution limits ValidateToken to one last_seen_at write per
+// session per interval, so busy clients do not turn every request into a
+// DB write.
+const lastSeenResolution = time.Minute
+
+// IssuedSession is a session as handed to the client: the stored row plus
+// the bearer token, which exists only here and is never persisted.
+type IssuedSession struct {
+	Token string
+	persist.Session
+}
+
+// ClientInfo describes the client a session is issued to. It is stored with
+// the session for ListSessions and never used for authentication.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// Revocation reports sessions that stopped being valid before they expired.
+// Token is set when a single session was revoked; otherwise every session of
+// UserID was.
+type Revocation struct {
+	UserID int64
+	Token  string
+}
+
+// OnRevoke registers fn to run after sessions are revoked (logout, logout
+// everywhere, password reset). fn runs on the revoking goroutine and must not
+// block.
+func (s *Service) OnRevoke(fn func(Revocation)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onRevoke = append(s.onRevoke, fn)
+}
+
+func (s *Service) revoked(rv Revocation) {
+	s.mu.Lock()
+	hooks := s.onRevoke
+	s.mu.Unlock()
+	for _, fn := range hooks {
+		fn(rv)
+	}
+}
+
+// RevokeAllSessions ends every session of userID and reports how many there
+// were.
+func (s *Service) RevokeAllSessions(ctx context.Context, userID int64) (int64, error) {
+	n, err := s.sessions.DeleteByUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	s.revoked(Revocation{UserID: userID})
+	return n, nil
+}
+
+// ListSessions returns the unexpired sessions of userID, most recently seen
+// first.
+func (s *Service) ListSessions(ctx context.Context, userID int64) ([]persist.Session, error) {
+	return s.sessions.ListByUser(ctx, userID, s.now().Unix())
+}
+
+// Run issues queued password resets and deletes expired sessions every reap
+// interval until ctx is cancelled; resets still queued then are dropped.
+// ValidateToken also drops an expired session when it is presented; the
+// reaper covers the ones nobody presents again.
+func (s *Service) Run(ctx context.Context) error {
+	s.reap(ctx)
+	ticker := time.NewTicker(s.reapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			s.reap(ctx)
+		case email := <-s.resetRequests:
+			s.issueReset(ctx, email)
+		}
+	}
+}
+
+// reap deletes in batches of reapBatch so a large backlog never holds one
+// long write lock on the sessions table.
+func (s *Service) reap(ctx context.Context) {
+	now := s.now().Unix()
+	var total int64
+	for ctx.Err() == nil {
+		n, err := s.sessions.DeleteExpired(ctx, now, s.reapBatch)
+		if err != nil {
+			slog.Error("auth: reap sessions", "err", err)
+			break
+		}
+		total += n
+		if n < int64(s.reapBatch) {
+			break
+		}
+	}
+	if total > 0 {
+		slog.Debug("auth: reaped expired sessions", "count", total)
+	}
+}
@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func (s *testService) sessionRows(t *testing.T) int {
+	t.Helper()
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sessions`).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestSessionTokensAreStoredHashed(t *testing.T) {
+	s := newTestService(t, Config{})
+	issued := s.register(t, "ada")
+	ctx := context.Background()
+
+	rows, err := s.db.Query(`SELECT token_hash, ip, user_agent FROM sessions`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var hash []byte
+		var ip, agent string
+		if err := rows.Scan(&hash, &ip, &agent); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(hash, HashToken(issued.Token)) {
+			t.Fatalf("stored key %x, want SHA-256 of the token", hash)
+		}
+		for _, col := range [][]byte{hash, []byte(ip), []byte(agent)} {
+			if bytes.Contains(col, []byte(issued.Token)) {
+				t.Fatalf("raw token stored in sessions: %q", col)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM sessions WHERE token_hash = ? OR token_hash = ?`, issued.Token, []byte(issued.Token)).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Fatalf("raw token matched %d session rows", n)
+	}
+	if _, err := s.ValidateToken(ctx, issued.Token); err != nil {
+		t.Fatalf("ValidateToken(token) = %v", err)
+	}
+	// Someone holding a database dump has only the hash; it is not a token.
+	if _, err := s.ValidateToken(ctx, string(HashToken(issued.Token))); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("ValidateToken(hash) = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestReaperDeletesExpiredSessionsInBatches(t *testing.T) {
+	s := newTestService(t, Config{ReapInterval: 10 * time.Millisecond, ReapBatch: 2})
+	s.register(t, "ada")
+	for range 4 {
+		s.login(t, "ada")
+	}
+	s.clock.Advance(2 * time.Hour)
+	live := s.login(t, "ada")
+	if got := s.sessionRows(t); got != 6 {
+		t.Fatalf("%d sessions before reaping, want 6", got)
+	}
+
+	s.run(t)
+	deadline := time.Now().Add(5 * time.Second)
+	for s.sessionRows(t) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d sessions left, want 1", s.sessionRows(t))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if _, err := s.ValidateToken(context.Background(), live.Token); err != nil {
+		t.Fatalf("unexpired session reaped: %v", err)
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	s := newTestService(t, Config{TokenTTL: time.Hour})
+	first := s.register(t, "ada")
+	s.clock.Advance(30 * time.Minute)
+	second := s.login(t, "ada")
+	s.register(t, "bob")
+	ctx := context.Background()
+
+	got, err := s.ListSessions(ctx, first.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !bytes.Equal(got[0].TokenHash, second.TokenHash) || !bytes.Equal(got[1].TokenHash, first.TokenHash) {
+		t.Fatalf("ListSessions = %+v, want newest first, own sessions only", got)
+	}
+	if got[1].IP != "192.0.2.1" || got[1].UserAgent != "test" {
+		t.Fatalf("client info not kept: %+v", got[1])
+	}
+
+	// The first session expires; it drops out of the list before the
+	// reaper removes it.
+	s.clock.Advance(30 * time.Minute)
+	got, err = s.ListSessions(ctx, first.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || !bytes.Equal(got[0].TokenHash, second.TokenHash) {
+		t.Fatalf("ListSessions after expiry = %+v, want only the second session", got)
+	}
+}
+
+func TestRevocationsFireHooks(t *testing.T) {
+	s := newTestService(t, Config{})
+	first := s.register(t, "ada")
+	second := s.login(t, "ada")
+	third := s.login(t, "ada")
+	other := s.register(t, "bob")
+	revocations := s.recordRevocations()
+	ctx := context.Background()
+
+	if err := s.RevokeToken(ctx, first.Token); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RevokeToken(ctx, first.Token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("second RevokeToken = %v, want ErrInvalidToken", err)
+	}
+	n, err := s.RevokeAllSessions(ctx, first.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Fatalf("RevokeAllSessions = %d, want 2", n)
+	}
+	for _, session := range []IssuedSession{second, third} {
+		if _, err := s.ValidateToken(ctx, session.Token); !errors.Is(err, ErrInvalidToken) {
+			t.Fatalf("session survived RevokeAllSessions: %v", err)
+		}
+	}
+	if _, err := s.ValidateToken(ctx, other.Token); err != nil {
+		t.Fatalf("other account revoked: %v", err)
+	}
+
+	want := []Revocation{{Token: first.Token}, {UserID: first.UserID}}
+	got := revocations()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("revocations = %+v, want %+v", got, want)
+	}
+}
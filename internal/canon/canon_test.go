@@ -0,0 +1,87 @@
+package canon
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// repoPath resolves a repository-relative path from this package directory.
+func repoPath(rel string) string {
+	return filepath.Join("..", "..", filepath.FromSlash(rel))
+}
+
+func loadRepo(t *testing.T) Sources {
+	t.Helper()
+	src, err := Load(repoPath(DefaultProtoPath), repoPath(DefaultEnumsPath), repoPath(DefaultGameplayPath))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return src
+}
+
+// requireMismatches fails unless every want substring appears in some error.
+func requireMismatches(t *testing.T, errs []error, want ...string) {
+	t.Helper()
+	if len(errs) == 0 {
+		t.Fatal("Check found no mismatch")
+	}
+	var all strings.Builder
+	for _, err := range errs {
+		all.WriteString(err.Error())
+		all.WriteByte('\n')
+	}
+	for _, w := range want {
+		if !strings.Contains(all.String(), w) {
+			t.Errorf("no mismatch mentions %q; got:\n%s", w, all.String())
+		}
+	}
+}
+
+func TestRepoCanonIsConsistent(t *testing.T) {
+	for _, err := range Check(loadRepo(t)) {
+		t.Error(err)
+	}
+}
+
+func TestDriftedEnumsFixtureFails(t *testing.T) {
+	src, err := Load(repoPath(DefaultProtoPath), filepath.Join("testdata", "enums_drift.go"), repoPath(DefaultGameplayPath))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	requireMismatches(t, Check(src),
+		"ITEM_POT_OF_HUNGER is 6 in proto ItemId but 8 in protocol.ItemId",
+		"proto AbilityId has ABILITY_NECROMANCER = 8; protocol.AbilityId has no ABILITY_NECROMANCER",
+		`gameplay piece type "queen" has rank 4 but protocol.RANK_QUEEN = 6`,
+	)
+}
+
+func TestProtoEditWithoutRegenerateFails(t *testing.T) {
+	raw, err := os.ReadFile(repoPath(DefaultProtoPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw = bytes.Replace(raw, []byte("EV_MATCH_STATE = 7;"), []byte("EV_MATCH_STATE = 7;\n  EV_SURRENDER = 8;"), 1)
+	src := loadRepo(t)
+	if src.Proto, err = ParseProtoEnums(raw); err != nil {
+		t.Fatal(err)
+	}
+	requireMismatches(t, Check(src),
+		"proto TimelineEventType has EV_SURRENDER = 8; generated TimelineEventType has no EV_SURRENDER",
+		"proto TimelineEventType has EV_SURRENDER = 8; protocol.TimelineEventType has no EV_SURRENDER",
+	)
+}
+
+func TestGameplayDriftFails(t *testing.T) {
+	src := loadRepo(t)
+	src.Gameplay.Items[0].ID = 9
+	src.Gameplay.Abilities = src.Gameplay.Abilities[:len(src.Gameplay.Abilities)-1]
+	src.Gameplay.PieceTypes[0], src.Gameplay.PieceTypes[1] = src.Gameplay.PieceTypes[1], src.Gameplay.PieceTypes[0]
+	requireMismatches(t, Check(src),
+		`gameplay item key "multitaskers_schedule" has id 9`,
+		"proto AbilityId value ABILITY_NECROMANCER has no gameplay ability entry",
+		`gameplay piece type "knight" is listed at position 1`,
+	)
+}
@@ -0,0 +1,241 @@
+package canon
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"example.com/mvp-repo/internal/config"
+	"example.com/mvp-repo/internal/proto/gen"
+)
+
+// Sources are the parsed canon inputs. Gameplay must have passed
+// config.Validate so keys are unique.
+type Sources struct {
+	Proto    map[string]Enum
+	Go       map[string]Enum
+	Gameplay config.GameplayConfig
+}
+
+// enumPair names one enum in proto and in internal/protocol. A value's
+// canonical suffix is its name without the prefix; suffixes must match.
+type enumPair struct {
+	proto, protoPrefix string
+	goType, goPrefix   string
+}
+
+var enumPairs = []enumPair{
+	{proto: "ElementId", goType: "ElementId", goPrefix: "ELEMENT_"},
+	{proto: "ItemId", protoPrefix: "ITEM_", goType: "ItemId", goPrefix: "ITEM_"},
+	{proto: "AbilityId", protoPrefix: "ABILITY_", goType: "AbilityId", goPrefix: "ABILITY_"},
+	{proto: "Dir4", goType: "Dir4", goPrefix: "DIR_"},
+	{proto: "BattleActionType", goType: "BattleActionType", goPrefix: "BAT_ACT_"},
+	{proto: "TimelineEventType", protoPrefix: "EV_", goType: "TimelineEventType", goPrefix: "EV_"},
+}
+
+// Check returns every mismatch between the sources and the generated proto
+// descriptors.
+func Check(src Sources) []error {
+	var c checker
+	c.generated(src.Proto)
+	for _, pair := range enumPairs {
+		c.pair(pair, src.Proto[pair.proto], src.Go[pair.goType])
+	}
+	g := src.Gameplay
+	c.gameplay("element", elementEntries(g), g.Canon.ElementsCount, src, enumPairs[0])
+	c.gameplay("item", itemEntries(g), g.Canon.ItemsCount, src, enumPairs[1])
+	c.gameplay("ability", abilityEntries(g), g.Canon.AbilitiesCount, src, enumPairs[2])
+	c.pieceTypes(g.PieceTypes, src.Go["PieceType"], src.Go["Rank"])
+	return c.errs
+}
+
+type checker struct {
+	errs []error
+}
+
+func (c *checker) addf(format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf("canon: "+format, args...))
+}
+
+// generated compares proto source with the descriptors compiled into
+// internal/proto/gen, catching a proto edit that was not regenerated.
+func (c *checker) generated(source map[string]Enum) {
+	compiled := make(map[string]Enum)
+	enums := gen.File_game_proto.Enums()
+	for i := 0; i < enums.Len(); i++ {
+		e := enums.Get(i)
+		values := make(Enum)
+		for j := 0; j < e.Values().Len(); j++ {
+			v := e.Values().Get(j)
+			values[string(v.Name())] = int64(v.Number())
+		}
+		compiled[string(e.Name())] = values
+	}
+	for _, name := range sortedKeys(source) {
+		if _, ok := compiled[name]; !ok {
+			c.addf("proto enum %s missing from internal/proto/gen (regenerate)", name)
+			continue
+		}
+		c.sameEnum("proto "+name, source[name], "generated "+name, compiled[name], "", "")
+	}
+	for _, name := range sortedKeys(compiled) {
+		if _, ok := source[name]; !ok {
+			c.addf("generated enum %s no longer in proto source (regenerate)", name)
+		}
+	}
+}
+
+func (c *checker) pair(p enumPair, proto, goEnum Enum) {
+	if proto == nil {
+		c.addf("proto enum %s not found", p.proto)
+		return
+	}
+	if goEnum == nil {
+		c.addf("internal/protocol type %s has no constants", p.goType)
+		return
+	}
+	c.sameEnum("proto "+p.proto, proto, "protocol."+p.goType, goEnum, p.protoPrefix, p.goPrefix)
+}
+
+// sameEnum requires a one-to-one match of canonical suffixes and values,
+// ignoring unspecified placeholders.
+func (c *checker) sameEnum(aLabel string, a Enum, bLabel string, b Enum, aPrefix, bPrefix string) {
+	bySuffix := func(e Enum, prefix, label string) map[string]int64 {
+		out := make(map[string]int64, len(e))
+		for name, v := range e {
+			if unspecified(name) {
+				continue
+			}
+			suffix, ok := strings.CutPrefix(name, prefix)
+			if !ok {
+				c.addf("%s value %s lacks prefix %q", label, name, prefix)
+				continue
+			}
+			out[suffix] = v
+		}
+		return out
+	}
+	as := bySuffix(a, aPrefix, aLabel)
+	bs := bySuffix(b, bPrefix, bLabel)
+	for _, suffix := range sortedKeys(as) {
+		bv, ok := bs[suffix]
+		switch {
+		case !ok:
+			c.addf("%s has %s%s = %d; %s has no %s%s", aLabel, aPrefix, suffix, as[suffix], bLabel, bPrefix, suffix)
+		case bv != as[suffix]:
+			c.addf("%s%s is %d in %s but %d in %s", aPrefix, suffix, as[suffix], aLabel, bv, bLabel)
+		}
+	}
+	for _, suffix := range sortedKeys(bs) {
+		if _, ok := as[suffix]; !ok {
+			c.addf("%s has %s%s = %d; %s has no %s%s", bLabel, bPrefix, suffix, bs[suffix], aLabel, aPrefix, suffix)
+		}
+	}
+}
+
+type entry struct {
+	id  int
+	key string
+}
+
+// gameplay matches config entries to proto and Go values by key: key
+// "block_path" is ABILITY_BLOCK_PATH. Count mismatches are reported
+// separately from per-entry ones so a missing row is obvious.
+func (c *checker) gameplay(label string, entries []entry, count int, src Sources, p enumPair) {
+	proto := src.Proto[p.proto]
+	goEnum := src.Go[p.goType]
+	used := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		suffix := strings.ToUpper(e.key)
+		used[suffix] = true
+		if v, ok := proto[p.protoPrefix+suffix]; !ok {
+			c.addf("gameplay %s %d key %q has no proto %s value %s", label, e.id, e.key, p.proto, p.protoPrefix+suffix)
+		} else if v != int64(e.id) {
+			c.addf("gameplay %s key %q has id %d but proto %s%s = %d", label, e.key, e.id, p.protoPrefix, suffix, v)
+		}
+		if v, ok := goEnum[p.goPrefix+suffix]; !ok {
+			c.addf("gameplay %s %d key %q has no protocol constant %s", label, e.id, e.key, p.goPrefix+suffix)
+		} else if v != int64(e.id) {
+			c.addf("gameplay %s key %q has id %d but protocol.%s%s = %d", label, e.key, e.id, p.goPrefix, suffix, v)
+		}
+	}
+	defined := 0
+	for _, name := range sortedKeys(proto) {
+		if unspecified(name) {
+			continue
+		}
+		defined++
+		if !used[strings.TrimPrefix(name, p.protoPrefix)] {
+			c.addf("proto %s value %s has no gameplay %s entry", p.proto, name, label)
+		}
+	}
+	if count != defined {
+		c.addf("gameplay canon %s count %d but proto %s defines %d", label, count, p.proto, defined)
+	}
+}
+
+// pieceTypes checks gameplay piece_types against protocol.PieceType (ids in
+// list order, starting at 1) and protocol.Rank. Piece types are not in proto
+// (DECISION 0006).
+func (c *checker) pieceTypes(pieces []config.PieceTypeConfig, ids, ranks Enum) {
+	defined := 0
+	for name := range ids {
+		if !unspecified(name) {
+			defined++
+		}
+	}
+	if defined != len(pieces) {
+		c.addf("gameplay has %d piece_types but protocol.PieceType defines %d", len(pieces), defined)
+	}
+	for i, p := range pieces {
+		suffix := strings.ToUpper(p.Key)
+		if v, ok := ids["PIECE_"+suffix]; !ok {
+			c.addf("gameplay piece type %q has no protocol constant PIECE_%s", p.Key, suffix)
+		} else if v != int64(i+1) {
+			c.addf("gameplay piece type %q is listed at position %d but protocol.PIECE_%s = %d", p.Key, i+1, suffix, v)
+		}
+		if v, ok := ranks["RANK_"+suffix]; !ok {
+			c.addf("gameplay piece type %q has no protocol constant RANK_%s", p.Key, suffix)
+		} else if v != int64(p.Rank) {
+			c.addf("gameplay piece type %q has rank %d but protocol.RANK_%s = %d", p.Key, p.Rank, suffix, v)
+		}
+	}
+}
+
+func elementEntries(g config.GameplayConfig) []entry {
+	out := make([]entry, 0, len(g.Elements))
+	for _, e := range g.Elements {
+		out = append(out, entry{id: e.ID, key: e.Key})
+	}
+	return out
+}
+
+func itemEntries(g config.GameplayConfig) []entry {
+	out := make([]entry, 0, len(g.Items))
+	for _, it := range g.Items {
+		out = append(out, entry{id: it.ID, key: it.Key})
+	}
+	return out
+}
+
+func abilityEntries(g config.GameplayConfig) []entry {
+	out := make([]entry, 0, len(g.Abilities))
+	for _, a := range g.Abilities {
+		out = append(out, entry{id: a.ID, key: a.Key})
+	}
+	return out
+}
+
+// unspecified matches zero placeholders: proto *_UNSPECIFIED, Go *_UNSPEC.
+func unspecified(name string) bool {
+	return strings.HasSuffix(name, "_UNSPECIFIED") || strings.HasSuffix(name, "_UNSPEC")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
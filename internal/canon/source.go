@@ -0,0 +1,145 @@
+// Package canon cross-checks the canonical IDs that are written down three
+// times: proto/game.proto, internal/protocol/enums.go and
+// config/gameplay.json (plus the generated descriptors in internal/proto/gen).
+package canon
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"example.com/mvp-repo/internal/config"
+)
+
+// Default paths relative to the repository root.
+const (
+	DefaultProtoPath    = "proto/game.proto"
+	DefaultEnumsPath    = "internal/protocol/enums.go"
+	DefaultGameplayPath = "config/gameplay.json"
+)
+
+// Load reads and parses the three canon inputs. The gameplay config must pass
+// its own validation first; its problems are returned joined.
+func Load(protoPath, enumsPath, gameplayPath string) (Sources, error) {
+	var src Sources
+	raw, err := os.ReadFile(protoPath)
+	if err != nil {
+		return src, err
+	}
+	if src.Proto, err = ParseProtoEnums(raw); err != nil {
+		return src, err
+	}
+	raw, err = os.ReadFile(enumsPath)
+	if err != nil {
+		return src, err
+	}
+	if src.Go, err = ParseGoEnums(enumsPath, raw); err != nil {
+		return src, err
+	}
+	src.Gameplay, err = config.LoadGameplayConfig(gameplayPath)
+	return src, err
+}
+
+// Enum maps value names to numbers for one enum type.
+type Enum map[string]int64
+
+var (
+	protoEnumStart = regexp.MustCompile(`^enum\s+(\w+)\s*\{`)
+	protoEnumValue = regexp.MustCompile(`^(\w+)\s*=\s*(-?\d+)\s*;`)
+)
+
+// ParseProtoEnums reads top-level enum blocks from proto source. It only
+// understands the one-value-per-line layout used by game.proto.
+func ParseProtoEnums(src []byte) (map[string]Enum, error) {
+	enums := make(map[string]Enum)
+	var current Enum
+	var name string
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if i := strings.Index(text, "//"); i >= 0 {
+			text = text[:i]
+		}
+		text = strings.TrimSpace(text)
+		switch {
+		case current == nil:
+			if m := protoEnumStart.FindStringSubmatch(text); m != nil {
+				name = m[1]
+				if _, dup := enums[name]; dup {
+					return nil, fmt.Errorf("canon: proto line %d: enum %s declared twice", line, name)
+				}
+				current = make(Enum)
+			}
+		case text == "}":
+			enums[name] = current
+			current = nil
+		case text == "":
+		default:
+			m := protoEnumValue.FindStringSubmatch(text)
+			if m == nil {
+				return nil, fmt.Errorf("canon: proto line %d: cannot parse enum %s value %q", line, name, text)
+			}
+			v, err := strconv.ParseInt(m[2], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("canon: proto line %d: %v", line, err)
+			}
+			current[m[1]] = v
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return nil, fmt.Errorf("canon: proto enum %s not closed", name)
+	}
+	return enums, nil
+}
+
+// ParseGoEnums collects typed constants from Go source, grouped by their
+// declared type name. Every constant must have an explicit type and an
+// integer value; iota and untyped constants are rejected so nothing is
+// silently skipped.
+func ParseGoEnums(filename string, src []byte) (map[string]Enum, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+	enums := make(map[string]Enum)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			typ, ok := vs.Type.(*ast.Ident)
+			if !ok || len(vs.Values) != len(vs.Names) {
+				return nil, fmt.Errorf("canon: %s: constant %s needs an explicit type and value", fset.Position(vs.Pos()), vs.Names[0].Name)
+			}
+			for i, n := range vs.Names {
+				lit, ok := vs.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.INT {
+					return nil, fmt.Errorf("canon: %s: constant %s is not an integer literal", fset.Position(n.Pos()), n.Name)
+				}
+				v, ok := constant.Int64Val(constant.MakeFromLiteral(lit.Value, lit.Kind, 0))
+				if !ok {
+					return nil, fmt.Errorf("canon: %s: constant %s overflows int64", fset.Position(n.Pos()), n.Name)
+				}
+				if enums[typ.Name] == nil {
+					enums[typ.Name] = make(Enum)
+				}
+				enums[typ.Name][n.Name] = v
+			}
+		}
+	}
+	return enums, nil
+}
@@ -0,0 +1,28 @@
+// File: internal/chat/codec.go
+package chat
+
+import (
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/router"
+)
+
+func decodeChatSend(b []byte) (string, error) {
+	msg, err := router.Decode[gen.ChatSend](b)
+	if err != nil {
+		return "", err
+	}
+	text := msg.GetText()
+	router.Release(msg)
+	return text, nil
+}
+
+func appendChatEvent(dst []byte, fromPlayerID uint64, text string) []byte {
+	out, err := proto.MarshalOptions{}.MarshalAppend(dst, &gen.ChatEvent{FromPlayerId: fromPlayerID, Text: text})
+	if err != nil {
+		// Only invalid UTF-8 fails to marshal, and Send rejects it first.
+		return dst
+	}
+	return out
+}
@@ -0,0 +1,161 @@
+// File: internal/chat/filter.go
+package chat
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Filter transforms an outbound chat message. Returning ErrRejected drops the
+// message entirely; any other error is treated as a server fault.
+type Filter interface {
+	Filter(text string) (string, error)
+}
+
+// Pipeline applies filters in order, feeding each output into the next.
+type Pipeline []Filter
+
+func (p Pipeline) Filter(text string) (string, error) {
+	for _, f := range p {
+		out, err := f.Filter(text)
+		if err != nil {
+			return "", err
+		}
+		text = out
+	}
+	return text, nil
+}
+
+// leetMap folds common character substitutions back to the letters they imitate.
+var leetMap = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'8': 'b',
+	'9': 'g',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'l',
+	'+': 't',
+}
+
+// WordFilter masks banned words after lowercasing and leetspeak normalization.
+// When reject is set, a message containing a banned word is rejected instead.
+type WordFilter struct {
+	words  map[string]struct{}
+	reject bool
+}
+
+func NewWordFilter(words []string, reject bool) *WordFilter {
+	set := make(map[string]struct{}, len(words))
+	for _, w := range words {
+		norm := normalizeWord(w)
+		if norm == "" {
+			continue
+		}
+		set[norm] = struct{}{}
+	}
+	return &WordFilter{words: set, reject: reject}
+}
+
+func (f *WordFilter) Filter(text string) (string, error) {
+	if len(f.words) == 0 {
+		return text, nil
+	}
+	runes := []rune(text)
+	masked := false
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if lo, hi, ok := f.match(runes[start:end]); ok {
+			if f.reject {
+				return "", ErrRejected
+			}
+			for i := start + lo; i < start+hi; i++ {
+				runes[i] = '*'
+			}
+			masked = true
+		}
+		start = end
+	}
+	if !masked {
+		return text, nil
+	}
+	return string(runes), nil
+}
+
+// match reports the span of token that is a banned word. Symbols at the token
+// edges are retried as punctuation so "word!" still matches "word".
+func (f *WordFilter) match(token []rune) (int, int, bool) {
+	lo, hi := 0, len(token)
+	for hi > 0 && !isAlnum(token[hi-1]) {
+		hi--
+	}
+	for lo < hi && !isAlnum(token[lo]) {
+		lo++
+	}
+	spans := [3][2]int{{0, len(token)}, {0, hi}, {lo, hi}}
+	for i, span := range spans {
+		if i > 0 && span == spans[i-1] {
+			continue
+		}
+		if span[0] >= span[1] {
+			continue
+		}
+		if _, ok := f.words[normalizeWord(string(token[span[0]:span[1]]))]; ok {
+			return span[0], span[1], true
+		}
+	}
+	return 0, 0, false
+}
+
+func normalizeWord(word string) string {
+	var b strings.Builder
+	b.Grow(len(word))
+	for _, r := range strings.ToLower(word) {
+		if mapped, ok := leetMap[r]; ok {
+			r = mapped
+		}
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isWordRune(r rune) bool {
+	if isAlnum(r) {
+		return true
+	}
+	_, ok := leetMap[r]
+	return ok
+}
+
+func isAlnum(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+const linkReplacement = "[link removed]"
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)*\.(?:com|net|org|io|gg|co|me|tv|ru|xyz|info|biz|app|dev|ly)\b(?:/\S*)?`)
+
+// LinkFilter replaces URLs and bare domain names with a fixed placeholder.
+type LinkFilter struct{}
+
+func (LinkFilter) Filter(text string) (string, error) {
+	if !linkPattern.MatchString(text) {
+		return text, nil
+	}
+	return linkPattern.ReplaceAllString(text, linkReplacement), nil
+}
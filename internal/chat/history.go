@@ -0,0 +1,196 @@
+// File: internal/chat/history.go
+package chat
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"example.com/mvp-repo/internal/persist"
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/router"
+)
+
+// ChannelGlobal is the only chat channel in the MVP; every bound session is subscribed.
+const ChannelGlobal = "global"
+
+const (
+	DefaultHistorySize      = 100
+	DefaultPersistQueueSize = 256
+	DefaultPruneInterval    = 10 * time.Minute
+
+	flushTimeout = 5 * time.Second
+)
+
+type HistoryStore interface {
+	Insert(ctx context.Context, msg persist.ChatMessage) (int64, error)
+	ListRecent(ctx context.Context, channel string, limit int) ([]persist.ChatMessage, error)
+	DeleteBefore(ctx context.Context, cutoff int64) (int64, error)
+}
+
+type historyEntry struct {
+	fromPlayerID uint64
+	createdAt    int64
+	payload      []byte
+}
+
+// historyRing keeps the most recent encoded ChatEvents of one channel.
+type historyRing struct {
+	entries []historyEntry
+	next    int
+	size    int
+}
+
+func newHistoryRing(capacity int) *historyRing {
+	return &historyRing{entries: make([]historyEntry, capacity)}
+}
+
+func (r *historyRing) push(e historyEntry) {
+	if len(r.entries) == 0 {
+		return
+	}
+	r.entries[r.next] = e
+	r.next = (r.next + 1) % len(r.entries)
+	if r.size < len(r.entries) {
+		r.size++
+	}
+}
+
+// appendRecent appends up to n of the newest entries created at or after
+// since to dst, oldest first.
+func (r *historyRing) appendRecent(dst []historyEntry, n int, since int64) []historyEntry {
+	if n > r.size {
+		n = r.size
+	}
+	if n <= 0 {
+		return dst
+	}
+	start := (r.next - n + len(r.entries)) % len(r.entries)
+	for i := 0; i < n; i++ {
+		e := r.entries[(start+i)%len(r.entries)]
+		if e.createdAt < since {
+			continue
+		}
+		dst = append(dst, e)
+	}
+	return dst
+}
+
+func (s *Service) ring(channel string) *historyRing {
+	r, ok := s.history[channel]
+	if !ok {
+		r = newHistoryRing(s.historySize)
+		s.history[channel] = r
+	}
+	return r
+}
+
+// backfill sends the newest history of each subscribed channel to a member
+// that just joined, skipping senders the member has blocked.
+func (s *Service) backfill(sender router.Sender, entries []historyEntry, blocked map[uint64]struct{}) {
+	for _, e := range entries {
+		if _, ok := blocked[e.fromPlayerID]; ok {
+			continue
+		}
+		if err := sender.Send(protocol.MSG_CHAT_EVENT, e.payload); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Service) retentionCutoff() int64 {
+	if s.retention <= 0 {
+		return 0
+	}
+	return s.now().Add(-s.retention).Unix()
+}
+
+func (s *Service) enqueuePersist(msg persist.ChatMessage) {
+	if s.persistCh == nil {
+		return
+	}
+	select {
+	case s.persistCh <- msg:
+	default:
+		slog.Warn("chat: history queue full, dropping message", "from", msg.FromUserID)
+	}
+}
+
+// Run warms the history rings from the store, then persists accepted messages
+// and prunes expired history until ctx is cancelled.
+func (s *Service) Run(ctx context.Context) error {
+	if s.store == nil {
+		<-ctx.Done()
+		return nil
+	}
+	if err := s.loadHistory(ctx); err != nil {
+		return err
+	}
+	s.prune(ctx)
+
+	ticker := time.NewTicker(s.pruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			s.flush()
+			return nil
+		case msg := <-s.persistCh:
+			if _, err := s.store.Insert(ctx, msg); err != nil {
+				slog.Error("chat: persist message", "err", err)
+			}
+		case <-ticker.C:
+			s.prune(ctx)
+		}
+	}
+}
+
+func (s *Service) loadHistory(ctx context.Context) error {
+	msgs, err := s.store.ListRecent(ctx, ChannelGlobal, s.historySize)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := s.ring(ChannelGlobal)
+	for _, msg := range msgs {
+		from := uint64(msg.FromUserID)
+		r.push(historyEntry{
+			fromPlayerID: from,
+			createdAt:    msg.CreatedAt,
+			payload:      appendChatEvent(nil, from, msg.Text),
+		})
+	}
+	return nil
+}
+
+func (s *Service) prune(ctx context.Context) {
+	cutoff := s.retentionCutoff()
+	if cutoff == 0 {
+		return
+	}
+	removed, err := s.store.DeleteBefore(ctx, cutoff)
+	if err != nil {
+		slog.Error("chat: prune history", "err", err)
+		return
+	}
+	if removed > 0 {
+		slog.Debug("chat: pruned history", "removed", removed, "before", cutoff)
+	}
+}
+
+func (s *Service) flush() {
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	for {
+		select {
+		case msg := <-s.persistCh:
+			if _, err := s.store.Insert(ctx, msg); err != nil {
+				slog.Error("chat: persist message", "err", err)
+				return
+			}
+		default:
+			return
+		}
+	}
+}
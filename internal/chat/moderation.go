@@ -0,0 +1,96 @@
+// File: internal/chat/moderation.go
+package chat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"example.com/mvp-repo/internal/persist"
+)
+
+var ErrStoreUnavailable = errors.New("chat: moderation store unavailable")
+
+type MuteStore interface {
+	Get(ctx context.Context, userID int64) (persist.Mute, error)
+	Upsert(ctx context.Context, mute persist.Mute) error
+	Delete(ctx context.Context, userID int64) error
+}
+
+type BlockStore interface {
+	Add(ctx context.Context, block persist.Block) error
+	Remove(ctx context.Context, blockerUserID int64, blockedUserID int64) error
+	ListByBlocker(ctx context.Context, blockerUserID int64) ([]persist.Block, error)
+}
+
+// Mute silences playerID until the given time. The mute is persisted and takes
+// effect immediately if the player is online.
+func (s *Service) Mute(ctx context.Context, playerID uint64, until time.Time, reason string) error {
+	if s.mutes == nil {
+		return ErrStoreUnavailable
+	}
+	mute := persist.Mute{
+		UserID:     int64(playerID),
+		MutedUntil: until.Unix(),
+		Reason:     reason,
+		CreatedAt:  s.now().Unix(),
+	}
+	if err := s.mutes.Upsert(ctx, mute); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, online := s.members[playerID]; online {
+		s.mutedUntil[playerID] = mute.MutedUntil
+	}
+	return nil
+}
+
+func (s *Service) Unmute(ctx context.Context, playerID uint64) error {
+	if s.mutes == nil {
+		return ErrStoreUnavailable
+	}
+	if err := s.mutes.Delete(ctx, int64(playerID)); err != nil && !errors.Is(err, persist.ErrNotFound) {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.mutedUntil, playerID)
+	return nil
+}
+
+// Block stops chat from blockedID reaching blockerID.
+func (s *Service) Block(ctx context.Context, blockerID uint64, blockedID uint64) error {
+	if s.blocks == nil {
+		return ErrStoreUnavailable
+	}
+	block := persist.Block{
+		BlockerUserID: int64(blockerID),
+		BlockedUserID: int64(blockedID),
+		CreatedAt:     s.now().Unix(),
+	}
+	if err := s.blocks.Add(ctx, block); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.members[blockerID] {
+		m.blocked[blockedID] = struct{}{}
+	}
+	return nil
+}
+
+func (s *Service) Unblock(ctx context.Context, blockerID uint64, blockedID uint64) error {
+	if s.blocks == nil {
+		return ErrStoreUnavailable
+	}
+	if err := s.blocks.Remove(ctx, int64(blockerID), int64(blockedID)); err != nil && !errors.Is(err, persist.ErrNotFound) {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, m := range s.members[blockerID] {
+		delete(m.blocked, blockedID)
+	}
+	return nil
+}
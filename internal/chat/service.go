@@ -0,0 +1,312 @@
+// File: internal/chat/service.go
+package chat
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"example.com/mvp-repo/internal/persist"
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/router"
+)
+
+const (
+	DefaultMaxTextBytes = 256
+
+	storeTimeout = 2 * time.Second
+)
+
+var (
+	ErrSenderRequired = errors.New("chat: sender required")
+	ErrEmptyMessage   = errors.New("chat: empty message")
+	ErrMessageTooLong = errors.New("chat: message too long")
+	ErrInvalidText    = errors.New("chat: invalid utf-8 text")
+	ErrRejected       = errors.New("chat: message rejected")
+	ErrMuted          = errors.New("chat: sender muted")
+)
+
+type Config struct {
+	MaxTextBytes int
+	Filter       Filter
+	Mutes        MuteStore
+	Blocks       BlockStore
+	// History persists accepted messages; nil keeps history in memory only.
+	History          HistoryStore
+	HistorySize      int
+	BackfillMessages int
+	Retention        time.Duration
+	PruneInterval    time.Duration
+	PersistQueueSize int
+	Now              func() time.Time
+}
+
+// Service broadcasts chat to every bound session on the global channel,
+// applying the filter pipeline, mutes and per-recipient block lists.
+type Service struct {
+	maxTextBytes int
+	filter       Filter
+	mutes        MuteStore
+	blocks       BlockStore
+	store        HistoryStore
+	now          func() time.Time
+
+	historySize      int
+	backfillMessages int
+	retention        time.Duration
+	pruneInterval    time.Duration
+	persistCh        chan persist.ChatMessage
+
+	mu sync.RWMutex
+	// members holds one entry per connection: a player with several sockets
+	// has several members, and each leaves on its own.
+	members    map[uint64]map[router.Sender]*member
+	mutedUntil map[uint64]int64
+	history    map[string]*historyRing
+}
+
+type member struct {
+	sender   router.Sender
+	blocked  map[uint64]struct{}
+	channels []string
+}
+
+func NewService(cfg Config) *Service {
+	if cfg.MaxTextBytes <= 0 {
+		cfg.MaxTextBytes = DefaultMaxTextBytes
+	}
+	if cfg.Filter == nil {
+		cfg.Filter = Pipeline(nil)
+	}
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = DefaultHistorySize
+	}
+	if cfg.BackfillMessages < 0 || cfg.BackfillMessages > cfg.HistorySize {
+		cfg.BackfillMessages = cfg.HistorySize
+	}
+	if cfg.PruneInterval <= 0 {
+		cfg.PruneInterval = DefaultPruneInterval
+	}
+	if cfg.PersistQueueSize <= 0 {
+		cfg.PersistQueueSize = DefaultPersistQueueSize
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	s := &Service{
+		maxTextBytes:     cfg.MaxTextBytes,
+		filter:           cfg.Filter,
+		mutes:            cfg.Mutes,
+		blocks:           cfg.Blocks,
+		store:            cfg.History,
+		now:              now,
+		historySize:      cfg.HistorySize,
+		backfillMessages: cfg.BackfillMessages,
+		retention:        cfg.Retention,
+		pruneInterval:    cfg.PruneInterval,
+		members:          make(map[uint64]map[router.Sender]*member),
+		mutedUntil:       make(map[uint64]int64),
+		history:          make(map[string]*historyRing),
+	}
+	if cfg.History != nil {
+		s.persistCh = make(chan persist.ChatMessage, cfg.PersistQueueSize)
+	}
+	return s
+}
+
+func (s *Service) HandleBind(ctx router.Context) error {
+	if ctx.Sender == nil {
+		return ErrSenderRequired
+	}
+	storeCtx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	return s.Join(storeCtx, ctx.PlayerID, ctx.Sender)
+}
+
+func (s *Service) HandleUnbind(ctx router.Context) {
+	s.Leave(ctx.PlayerID, ctx.Sender)
+}
+
+func (s *Service) HandleChatSend(ctx router.Context, payload []byte) error {
+	if ctx.Sender == nil {
+		return ErrSenderRequired
+	}
+	text, err := decodeChatSend(payload)
+	if err != nil {
+		return router.Reject(protocol.ERR_MALFORMED, err)
+	}
+	return rejection(s.Send(ctx.PlayerID, text))
+}
+
+// rejection maps user-facing send failures to MSG_ERROR replies; anything
+// else is returned unchanged.
+func rejection(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrEmptyMessage):
+		return router.Reject(protocol.ERR_CHAT_EMPTY, err)
+	case errors.Is(err, ErrMessageTooLong):
+		return router.Reject(protocol.ERR_CHAT_TOO_LONG, err)
+	case errors.Is(err, ErrInvalidText):
+		return router.Reject(protocol.ERR_CHAT_INVALID_TEXT, err)
+	case errors.Is(err, ErrRejected):
+		return router.Reject(protocol.ERR_CHAT_REJECTED, err)
+	case errors.Is(err, ErrMuted):
+		return router.Reject(protocol.ERR_CHAT_MUTED, err)
+	default:
+		return err
+	}
+}
+
+// Join registers a bound session as a chat recipient, loads its moderation
+// state (mute expiry and block list) from the stores and backfills the most
+// recent history of each subscribed channel.
+func (s *Service) Join(ctx context.Context, playerID uint64, sender router.Sender) error {
+	if sender == nil {
+		return ErrSenderRequired
+	}
+	m := &member{
+		sender:   sender,
+		blocked:  make(map[uint64]struct{}),
+		channels: []string{ChannelGlobal},
+	}
+	if s.blocks != nil {
+		blocks, err := s.blocks.ListByBlocker(ctx, int64(playerID))
+		if err != nil {
+			return err
+		}
+		for _, b := range blocks {
+			m.blocked[uint64(b.BlockedUserID)] = struct{}{}
+		}
+	}
+	var mutedUntil int64
+	if s.mutes != nil {
+		mute, err := s.mutes.Get(ctx, int64(playerID))
+		switch {
+		case err == nil:
+			mutedUntil = mute.MutedUntil
+		case !errors.Is(err, persist.ErrNotFound):
+			return err
+		}
+	}
+
+	var recent []historyEntry
+	since := s.retentionCutoff()
+	s.mu.Lock()
+	conns := s.members[playerID]
+	if conns == nil {
+		conns = make(map[router.Sender]*member)
+		s.members[playerID] = conns
+	}
+	conns[sender] = m
+	if mutedUntil > s.now().Unix() {
+		s.mutedUntil[playerID] = mutedUntil
+	}
+	for _, channel := range m.channels {
+		if r, ok := s.history[channel]; ok {
+			recent = r.appendRecent(recent, s.backfillMessages, since)
+		}
+	}
+	s.mu.Unlock()
+
+	s.backfill(sender, recent, m.blocked)
+	return nil
+}
+
+// Leave removes the member for sender. The player's moderation state is
+// dropped with their last connection.
+func (s *Service) Leave(playerID uint64, sender router.Sender) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conns := s.members[playerID]
+	delete(conns, sender)
+	if len(conns) == 0 {
+		delete(s.members, playerID)
+		delete(s.mutedUntil, playerID)
+	}
+}
+
+// Send filters text from playerID and delivers a ChatEvent to every member
+// that has not blocked the sender.
+func (s *Service) Send(playerID uint64, text string) error {
+	if s.isMuted(playerID) {
+		return ErrMuted
+	}
+	if len(text) > s.maxTextBytes {
+		return ErrMessageTooLong
+	}
+	if !utf8.ValidString(text) {
+		return ErrInvalidText
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ErrEmptyMessage
+	}
+	filtered, err := s.filter.Filter(text)
+	if err != nil {
+		return err
+	}
+	filtered = strings.TrimSpace(filtered)
+	if filtered == "" {
+		return ErrEmptyMessage
+	}
+
+	createdAt := s.now().Unix()
+	payload := appendChatEvent(make([]byte, 0, len(filtered)+16), playerID, filtered)
+	for _, recipient := range s.record(ChannelGlobal, playerID, createdAt, payload) {
+		_ = recipient.Send(protocol.MSG_CHAT_EVENT, payload)
+	}
+	s.enqueuePersist(persist.ChatMessage{
+		Channel:    ChannelGlobal,
+		FromUserID: int64(playerID),
+		Text:       filtered,
+		CreatedAt:  createdAt,
+	})
+	return nil
+}
+
+// record appends the message to the channel history and returns the members
+// that should receive it.
+func (s *Service) record(channel string, fromPlayerID uint64, createdAt int64, payload []byte) []router.Sender {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ring(channel).push(historyEntry{
+		fromPlayerID: fromPlayerID,
+		createdAt:    createdAt,
+		payload:      payload,
+	})
+	out := make([]router.Sender, 0, len(s.members))
+	for _, conns := range s.members {
+		for _, m := range conns {
+			if _, blocked := m.blocked[fromPlayerID]; blocked {
+				continue
+			}
+			out = append(out, m.sender)
+		}
+	}
+	return out
+}
+
+func (s *Service) isMuted(playerID uint64) bool {
+	now := s.now().Unix()
+	s.mu.RLock()
+	until, ok := s.mutedUntil[playerID]
+	s.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	if until > now {
+		return true
+	}
+	s.mu.Lock()
+	if s.mutedUntil[playerID] == until {
+		delete(s.mutedUntil, playerID)
+	}
+	s.mu.Unlock()
+	return false
+}
@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"example.com/mvp-repo/internal/persist"
+	"example.com/mvp-repo/internal/protocol"
+)
+
+type recordingSender struct {
+	mu     sync.Mutex
+	events int
+}
+
+func (r *recordingSender) Send(msgType protocol.MsgType, payload []byte) error {
+	if msgType == protocol.MSG_CHAT_EVENT {
+		r.mu.Lock()
+		r.events++
+		r.mu.Unlock()
+	}
+	return nil
+}
+
+func (r *recordingSender) Close(reason string) error { return nil }
+
+func (r *recordingSender) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events
+}
+
+func TestMembersAreKeyedByConnection(t *testing.T) {
+	s := NewService(Config{})
+	ctx := context.Background()
+	first, second, other := &recordingSender{}, &recordingSender{}, &recordingSender{}
+	for _, join := range []struct {
+		player uint64
+		sender *recordingSender
+	}{{7, first}, {7, second}, {8, other}} {
+		if err := s.Join(ctx, join.player, join.sender); err != nil {
+			t.Fatalf("join %d: %v", join.player, err)
+		}
+	}
+
+	if err := s.Send(8, "hello"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if first.count() != 1 || second.count() != 1 || other.count() != 1 {
+		t.Fatalf("events = %d/%d/%d, want 1/1/1", first.count(), second.count(), other.count())
+	}
+
+	s.Leave(7, first)
+	if err := s.Send(8, "again"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if first.count() != 1 {
+		t.Errorf("left connection got %d events, want 1", first.count())
+	}
+	if second.count() != 2 {
+		t.Errorf("remaining connection of the same player got %d events, want 2", second.count())
+	}
+}
+
+type memoryBlocks struct{}
+
+func (memoryBlocks) Add(ctx context.Context, block persist.Block) error { return nil }
+
+func (memoryBlocks) Remove(ctx context.Context, blockerUserID int64, blockedUserID int64) error {
+	return nil
+}
+
+func (memoryBlocks) ListByBlocker(ctx context.Context, blockerUserID int64) ([]persist.Block, error) {
+	return nil, nil
+}
+
+func TestBlockAppliesToEveryConnection(t *testing.T) {
+	s := NewService(Config{Blocks: memoryBlocks{}})
+	ctx := context.Background()
+	a, b := &recordingSender{}, &recordingSender{}
+	if err := s.Join(ctx, 1, a); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Join(ctx, 1, b); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Block(ctx, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Send(2, "spam"); err != nil {
+		t.Fatal(err)
+	}
+	if a.count() != 0 || b.count() != 0 {
+		t.Fatalf("blocked sender reached %d/%d connections", a.count(), b.count())
+	}
+}
@@ -4,30 +4,127 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"strings"
+
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/router"
 )
 
 type ServerConfig struct {
 	SchemaVersion int               `json:"schema_version"`
 	HTTP          HTTPConfig        `json:"http"`
 	WS            WSConfig          `json:"ws"`
+	Protocol      ProtocolConfig    `json:"protocol"`
+	Dispatch      DispatchConfig    `json:"dispatch"`
 	Overworld     OverworldConfig   `json:"overworld"`
 	Battle        BattleConfig      `json:"battle"`
+	Chat          ChatConfig        `json:"chat"`
 	Auth          AuthConfig        `json:"auth"`
 	Persistence   PersistenceConfig `json:"persistence"`
 }
 
+// DispatchConfig selects where handlers run. "inline" runs them on each
+// connection's read goroutine; "mailbox" queues world, battle and chat
+// messages to per-subsystem workers with per-key ordering.
+type DispatchConfig struct {
+	Mode   string        `json:"mode"`
+	World  MailboxConfig `json:"world"`
+	Battle MailboxConfig `json:"battle"`
+	Chat   MailboxConfig `json:"chat"`
+}
+
+type MailboxConfig struct {
+	Shards    int `json:"shards"`
+	QueueSize int `json:"queue_size"`
+}
+
+// ProtocolConfig controls Hello/Welcome negotiation. features lists the
+// optional features the server offers (see protocol.Features).
+type ProtocolConfig struct {
+	MinClientVersion uint32   `json:"min_client_version"`
+	Features         []string `json:"features"`
+}
+
 type HTTPConfig struct {
 	ListenAddr string `json:"listen_addr"`
 }
 
 type WSConfig struct {
-	ListenAddr             string    `json:"listen_addr"`
-	Path                   string    `json:"path"`
-	ReadLimitBytes         uint32    `json:"read_limit_bytes"`
-	WriteQueueMaxFrames    int       `json:"write_queue_max_frames"`
-	OverworldDeltaCoalesce bool      `json:"overworld_delta_coalesce"`
-	TLS                    TLSConfig `json:"tls"`
+	ListenAddr             string            `json:"listen_addr"`
+	Path                   string            `json:"path"`
+	ReadLimitBytes         uint32            `json:"read_limit_bytes"`
+	WriteQueues            WriteQueuesConfig `json:"write_queues"`
+	OverworldDeltaCoalesce bool              `json:"overworld_delta_coalesce"`
+	Compression            CompressionConfig `json:"compression"`
+	Limits                 WSLimitsConfig    `json:"limits"`
+	Heartbeat              HeartbeatConfig   `json:"heartbeat"`
+	Drain                  DrainConfig       `json:"drain"`
+	Batching               BatchingConfig    `json:"batching"`
+	TLS                    TLSConfig         `json:"tls"`
+}
+
+// HeartbeatConfig drives server pings and reaping. Idle detection (no inbound
+// traffic) is separate from write_timeout (slow consumer).
+type HeartbeatConfig struct {
+	IntervalSeconds     float64 `json:"interval_seconds"`
+	PongTimeoutSeconds  float64 `json:"pong_timeout_seconds"`
+	IdleTimeoutSeconds  float64 `json:"idle_timeout_seconds"`
+	ReadTimeoutSeconds  float64 `json:"read_timeout_seconds"`
+	WriteTimeoutSeconds float64 `json:"write_timeout_seconds"`
+}
+
+// WriteQueuesConfig bounds the per-connection outbound lanes. The overworld lane
+// is bounded by overworld.replication.max_pending_overworld_deltas_per_client.
+type WriteQueuesConfig struct {
+	ControlMaxFrames int `json:"control_max_frames"`
+	BattleMaxFrames  int `json:"battle_max_frames"`
+	ChatMaxFrames    int `json:"chat_max_frames"`
+}
+
+// CompressionConfig controls permessage-deflate negotiation ("disabled",
+// "context_takeover", "no_context_takeover") and the application-level
+// compressed-frame flag for large snapshot and timeline payloads.
+type CompressionConfig struct {
+	Mode            string `json:"mode"`
+	ThresholdBytes  int    `json:"threshold_bytes"`
+	PayloadDeflate  bool   `json:"payload_deflate"`
+	PayloadMinBytes int    `json:"payload_min_bytes"`
+}
+
+// WSLimitsConfig guards the upgrade endpoint. class_limits is keyed by rate
+// class ("control", "world", "chat", "battle") and gives each class one
+// shared budget; message_overrides is keyed by canonical msg type name (e.g.
+// "MSG_CHAT_SEND") and takes precedence.
+type WSLimitsConfig struct {
+	AllowedOrigins   []string                   `json:"allowed_origins"`
+	MaxConnsPerIP    int                        `json:"max_conns_per_ip"`
+	Handshake        RateLimitConfig            `json:"handshake"`
+	Messages         RateLimitConfig            `json:"messages"`
+	ClassLimits      map[string]RateLimitConfig `json:"class_limits"`
+	MessageOverrides map[string]RateLimitConfig `json:"message_overrides"`
+}
+
+type RateLimitConfig struct {
+	PerSecond float64 `json:"per_second"`
+	Burst     int     `json:"burst"`
+}
+
+// DrainConfig bounds graceful shutdown: in-flight battles get timeout_seconds
+// (with a client countdown every notice_interval_seconds), then connections get
+// flush_timeout_seconds to write queued frames.
+type DrainConfig struct {
+	TimeoutSeconds        float64 `json:"timeout_seconds"`
+	NoticeIntervalSeconds float64 `json:"notice_interval_seconds"`
+	FlushTimeoutSeconds   float64 `json:"flush_timeout_seconds"`
+}
+
+// BatchingConfig bounds outbound MSG_BATCH envelopes for sessions that
+// negotiated the "batch" feature. max_records <= 1 disables packing.
+type BatchingConfig struct {
+	MaxRecords int `json:"max_records"`
+	MaxBytes   int `json:"max_bytes"`
 }
 
 type TLSConfig struct {
@@ -65,17 +162,89 @@ type RNGConfig struct {
 	PRNG string `json:"prng"`
 }
 
+type ChatConfig struct {
+	MaxTextBytes    int               `json:"max_text_bytes"`
+	StripLinks      bool              `json:"strip_links"`
+	RejectProfanity bool              `json:"reject_profanity"`
+	BannedWords     []string          `json:"banned_words"`
+	History         ChatHistoryConfig `json:"history"`
+}
+
+type ChatHistoryConfig struct {
+	RingSize             int `json:"ring_size"`
+	BackfillMessages     int `json:"backfill_messages"`
+	RetentionHours       int `json:"retention_hours"`
+	PruneIntervalSeconds int `json:"prune_interval_seconds"`
+}
+
 type AuthConfig struct {
-	SessionTokenBytes int `json:"session_token_bytes"`
-	SessionTTLSeconds int `json:"session_ttl_seconds"`
+	SessionTokenBytes int                 `json:"session_token_bytes"`
+	SessionTTLSeconds int                 `json:"session_ttl_seconds"`
+	SessionReaper     SessionReaperConfig `json:"session_reaper"`
+	PasswordReset     PasswordResetConfig `json:"password_reset"`
+}
+
+// SessionReaperConfig paces the background sweep of expired sessions: every
+// interval_seconds it deletes them batch_size rows at a time.
+type SessionReaperConfig struct {
+	IntervalSeconds int `json:"interval_seconds"`
+	BatchSize       int `json:"batch_size"`
 }
 
+// PasswordResetConfig controls reset mail. link_url, if set, is the page the
+// mail links to (the token is added as ?token=); otherwise the mail carries
+// the bare token. mailer is "log" (standard logger) or "file" (appended to
+// mail_file); both are development mailers.
+type PasswordResetConfig struct {
+	TTLSeconds int    `json:"ttl_seconds"`
+	LinkURL    string `json:"link_url"`
+	Mailer     string `json:"mailer"`
+	MailFile   string `json:"mail_file"`
+}
+
+// PersistenceConfig selects the database by env: "dev" opens dev_driver with
+// dev (SQLite dialect), "prod" opens prod_driver with prod (Postgres
+// dialect). Driver names are database/sql registrations.
 type PersistenceConfig struct {
-	DevDriver  string `json:"dev_driver"`
-	ProdDriver string `json:"prod_driver"`
+	Env        string         `json:"env"`
+	DevDriver  string         `json:"dev_driver"`
+	ProdDriver string         `json:"prod_driver"`
+	Dev        DatabaseConfig `json:"dev"`
+	Prod       DatabaseConfig `json:"prod"`
+}
+
+// DatabaseConfig is one environment's DSN and pool settings; zero pool values
+// keep the database/sql defaults.
+type DatabaseConfig struct {
+	DSN                    string  `json:"dsn"`
+	MaxOpenConns           int     `json:"max_open_conns"`
+	MaxIdleConns           int     `json:"max_idle_conns"`
+	ConnMaxLifetimeSeconds float64 `json:"conn_max_lifetime_seconds"`
+	ConnMaxIdleTimeSeconds float64 `json:"conn_max_idle_time_seconds"`
+}
+
+// Selected returns the driver and database settings for Env.
+func (p PersistenceConfig) Selected() (string, DatabaseConfig) {
+	if p.Env == "prod" {
+		return p.ProdDriver, p.Prod
+	}
+	return p.DevDriver, p.Dev
 }
 
 func LoadServerConfig(path string) (ServerConfig, error) {
+	cfg, err := ReadServerConfig(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ReadServerConfig decodes path strictly without validating, so overrides
+// can be applied before Validate.
+func ReadServerConfig(path string) (ServerConfig, error) {
 	var cfg ServerConfig
 	file, err := os.Open(path)
 	if err != nil {
@@ -91,9 +260,6 @@ func LoadServerConfig(path string) (ServerConfig, error) {
 	if dec.More() {
 		return cfg, errors.New("server config: unexpected trailing data")
 	}
-	if err := cfg.Validate(); err != nil {
-		return cfg, err
-	}
 	return cfg, nil
 }
 
@@ -113,14 +279,60 @@ func (cfg ServerConfig) Validate() error {
 	if cfg.WS.ReadLimitBytes == 0 {
 		return fmt.Errorf("server config: ws.read_limit_bytes must be > 0")
 	}
-	if cfg.WS.WriteQueueMaxFrames <= 0 {
-		return fmt.Errorf("server config: ws.write_queue_max_frames must be > 0")
+	if cfg.WS.WriteQueues.ControlMaxFrames <= 0 {
+		return fmt.Errorf("server config: ws.write_queues.control_max_frames must be > 0")
+	}
+	if cfg.WS.WriteQueues.BattleMaxFrames <= 0 {
+		return fmt.Errorf("server config: ws.write_queues.battle_max_frames must be > 0")
+	}
+	if cfg.WS.WriteQueues.ChatMaxFrames <= 0 {
+		return fmt.Errorf("server config: ws.write_queues.chat_max_frames must be > 0")
+	}
+	switch cfg.WS.Compression.Mode {
+	case "disabled", "context_takeover", "no_context_takeover":
+	default:
+		return fmt.Errorf("server config: ws.compression.mode must be disabled, context_takeover or no_context_takeover")
+	}
+	if cfg.WS.Compression.ThresholdBytes < 0 || cfg.WS.Compression.PayloadMinBytes < 0 {
+		return fmt.Errorf("server config: ws.compression thresholds must be >= 0")
+	}
+	if err := cfg.WS.Limits.validate(); err != nil {
+		return err
+	}
+	if err := cfg.WS.Heartbeat.validate(); err != nil {
+		return err
+	}
+	if cfg.WS.Batching.MaxRecords > 1 && (cfg.WS.Batching.MaxBytes <= 0 || cfg.WS.Batching.MaxBytes > int(cfg.WS.ReadLimitBytes)) {
+		return fmt.Errorf("server config: ws.batching.max_bytes must be in (0, ws.read_limit_bytes]")
+	}
+	if cfg.WS.Drain.TimeoutSeconds < 0 || cfg.WS.Drain.NoticeIntervalSeconds < 0 || cfg.WS.Drain.FlushTimeoutSeconds < 0 {
+		return fmt.Errorf("server config: ws.drain values must be >= 0")
 	}
 	if cfg.WS.TLS.Enabled {
 		if cfg.WS.TLS.CertFile == "" || cfg.WS.TLS.KeyFile == "" {
 			return fmt.Errorf("server config: ws.tls.cert_file and ws.tls.key_file are required when tls.enabled")
 		}
 	}
+	if cfg.Protocol.MinClientVersion < protocol.MinProtocolVersion || cfg.Protocol.MinClientVersion > protocol.ProtocolVersion {
+		return fmt.Errorf("server config: protocol.min_client_version must be in [%d, %d]", protocol.MinProtocolVersion, protocol.ProtocolVersion)
+	}
+	if _, unknown := protocol.ParseFeatures(cfg.Protocol.Features); len(unknown) > 0 {
+		return fmt.Errorf("server config: protocol.features has unknown entries %v", unknown)
+	}
+	switch cfg.Dispatch.Mode {
+	case "inline":
+	case "mailbox":
+		for name, mb := range map[string]MailboxConfig{"world": cfg.Dispatch.World, "battle": cfg.Dispatch.Battle, "chat": cfg.Dispatch.Chat} {
+			if mb.Shards <= 0 || mb.QueueSize <= 0 {
+				return fmt.Errorf("server config: dispatch.%s shards and queue_size must be > 0", name)
+			}
+		}
+		if cfg.Dispatch.World.Shards != 1 {
+			return fmt.Errorf("server config: dispatch.world.shards must be 1 (the world has a single owner)")
+		}
+	default:
+		return fmt.Errorf("server config: dispatch.mode must be inline or mailbox")
+	}
 	if cfg.Overworld.TickHz <= 0 {
 		return fmt.Errorf("server config: overworld.tick_hz must be > 0")
 	}
@@ -133,14 +345,144 @@ func (cfg ServerConfig) Validate() error {
 	if cfg.Overworld.Replication.MaxPendingOverworldDeltasPerClient <= 0 {
 		return fmt.Errorf("server config: overworld.replication.max_pending_overworld_deltas_per_client must be > 0")
 	}
+	if cfg.Chat.MaxTextBytes <= 0 {
+		return fmt.Errorf("server config: chat.max_text_bytes must be > 0")
+	}
+	for _, word := range cfg.Chat.BannedWords {
+		if strings.TrimSpace(word) == "" {
+			return fmt.Errorf("server config: chat.banned_words must not contain empty entries")
+		}
+	}
+	if cfg.Chat.History.RingSize <= 0 {
+		return fmt.Errorf("server config: chat.history.ring_size must be > 0")
+	}
+	if cfg.Chat.History.BackfillMessages < 0 || cfg.Chat.History.BackfillMessages > cfg.Chat.History.RingSize {
+		return fmt.Errorf("server config: chat.history.backfill_messages must be between 0 and ring_size")
+	}
+	if cfg.Chat.History.RetentionHours < 0 {
+		return fmt.Errorf("server config: chat.history.retention_hours must be >= 0")
+	}
+	if cfg.Chat.History.PruneIntervalSeconds <= 0 {
+		return fmt.Errorf("server config: chat.history.prune_interval_seconds must be > 0")
+	}
 	if cfg.Auth.SessionTokenBytes <= 0 {
 		return fmt.Errorf("server config: auth.session_token_bytes must be > 0")
 	}
 	if cfg.Auth.SessionTTLSeconds <= 0 {
 		return fmt.Errorf("server config: auth.session_ttl_seconds must be > 0")
 	}
-	if cfg.Persistence.DevDriver == "" || cfg.Persistence.ProdDriver == "" {
+	if cfg.Auth.SessionReaper.IntervalSeconds <= 0 {
+		return fmt.Errorf("server config: auth.session_reaper.interval_seconds must be > 0")
+	}
+	if cfg.Auth.SessionReaper.BatchSize <= 0 {
+		return fmt.Errorf("server config: auth.session_reaper.batch_size must be > 0")
+	}
+	if err := cfg.Auth.PasswordReset.validate(); err != nil {
+		return err
+	}
+	if err := cfg.Persistence.validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r PasswordResetConfig) validate() error {
+	if r.TTLSeconds <= 0 {
+		return fmt.Errorf("server config: auth.password_reset.ttl_seconds must be > 0")
+	}
+	if r.LinkURL != "" {
+		if u, err := url.Parse(r.LinkURL); err != nil || !u.IsAbs() {
+			return fmt.Errorf("server config: auth.password_reset.link_url must be an absolute URL")
+		}
+	}
+	switch r.Mailer {
+	case "log":
+	case "file":
+		if r.MailFile == "" {
+			return fmt.Errorf("server config: auth.password_reset.mail_file is required for the file mailer")
+		}
+	default:
+		return fmt.Errorf("server config: auth.password_reset.mailer must be log or file")
+	}
+	return nil
+}
+
+func (p PersistenceConfig) validate() error {
+	if p.DevDriver == "" || p.ProdDriver == "" {
 		return fmt.Errorf("server config: persistence.dev_driver and persistence.prod_driver are required")
 	}
+	switch p.Env {
+	case "dev", "prod":
+	default:
+		return fmt.Errorf("server config: persistence.env must be dev or prod")
+	}
+	_, db := p.Selected()
+	if db.DSN == "" {
+		return fmt.Errorf("server config: persistence.%s.dsn is required", p.Env)
+	}
+	if db.MaxOpenConns < 0 || db.MaxIdleConns < 0 || db.ConnMaxLifetimeSeconds < 0 || db.ConnMaxIdleTimeSeconds < 0 {
+		return fmt.Errorf("server config: persistence.%s pool values must be >= 0", p.Env)
+	}
+	return nil
+}
+
+func (l WSLimitsConfig) validate() error {
+	if l.MaxConnsPerIP < 0 {
+		return fmt.Errorf("server config: ws.limits.max_conns_per_ip must be >= 0")
+	}
+	if err := l.Handshake.validate("ws.limits.handshake"); err != nil {
+		return err
+	}
+	if err := l.Messages.validate("ws.limits.messages"); err != nil {
+		return err
+	}
+	for name, limit := range l.ClassLimits {
+		if _, ok := router.ParseRateClass(name); !ok {
+			return fmt.Errorf("server config: ws.limits.class_limits: unknown rate class %q", name)
+		}
+		if err := limit.validate("ws.limits.class_limits." + name); err != nil {
+			return err
+		}
+	}
+	for name, limit := range l.MessageOverrides {
+		if _, ok := protocol.ParseMsgType(name); !ok {
+			return fmt.Errorf("server config: ws.limits.message_overrides: unknown msg type %q", name)
+		}
+		if err := limit.validate("ws.limits.message_overrides." + name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l RateLimitConfig) validate(path string) error {
+	if l.PerSecond < 0 {
+		return fmt.Errorf("server config: %s.per_second must be >= 0", path)
+	}
+	if l.PerSecond > 0 && l.Burst < 1 {
+		return fmt.Errorf("server config: %s.burst must be >= 1", path)
+	}
+	return nil
+}
+
+// validate rejects negative values; zero selects the gateway default.
+func (h HeartbeatConfig) validate() error {
+	for name, v := range map[string]float64{
+		"interval_seconds":      h.IntervalSeconds,
+		"pong_timeout_seconds":  h.PongTimeoutSeconds,
+		"idle_timeout_seconds":  h.IdleTimeoutSeconds,
+		"read_timeout_seconds":  h.ReadTimeoutSeconds,
+		"write_timeout_seconds": h.WriteTimeoutSeconds,
+	} {
+		if v < 0 {
+			return fmt.Errorf("server config: ws.heartbeat.%s must be >= 0", name)
+		}
+	}
+	if h.IntervalSeconds > 0 && h.PongTimeoutSeconds >= h.IntervalSeconds {
+		return fmt.Errorf("server config: ws.heartbeat.pong_timeout_seconds must be < interval_seconds")
+	}
+	if h.IntervalSeconds > 0 && h.IdleTimeoutSeconds > 0 && h.IdleTimeoutSeconds <= h.IntervalSeconds {
+		return fmt.Errorf("server config: ws.heartbeat.idle_timeout_seconds must be > interval_seconds")
+	}
 	return nil
 }
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Change is one leaf that differs between two config versions. Path uses the
+// JSON names ("ws.limits.messages.per_second", "items[3].slot_cost").
+type Change struct {
+	Path     string
+	Old, New any
+}
+
+// String formats the change for logs; DSN values have their credentials
+// redacted.
+func (c Change) String() string {
+	before, after := c.Old, c.New
+	if strings.HasSuffix(c.Path, ".dsn") {
+		before, after = redactValue(before), redactValue(after)
+	}
+	return fmt.Sprintf("%s: %s -> %s", c.Path, formatValue(before), formatValue(after))
+}
+
+// Diff lists the JSON-visible fields that differ between a and b, which must
+// have the same type. Structs and maps are walked; slices of equal length are
+// compared element by element, otherwise reported whole. Raw effect blocks
+// are compared as compacted JSON, so whitespace edits are not changes.
+func Diff(a, b any) []Change {
+	var out []Change
+	diffValue(&out, "", reflect.ValueOf(a), reflect.ValueOf(b))
+	return out
+}
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+func diffValue(out *[]Change, path string, a, b reflect.Value) {
+	if a.Type() == rawMessageType {
+		ra, rb := compactJSON(a.Bytes()), compactJSON(b.Bytes())
+		if !bytes.Equal(ra, rb) {
+			*out = append(*out, Change{Path: path, Old: ra, New: rb})
+		}
+		return
+	}
+	switch a.Kind() {
+	case reflect.Pointer:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				*out = append(*out, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+			}
+			return
+		}
+		diffValue(out, path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := jsonName(t.Field(i))
+			if !ok {
+				continue
+			}
+			diffValue(out, joinPath(path, name), a.Field(i), b.Field(i))
+		}
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		for _, k := range append(a.MapKeys(), b.MapKeys()...) {
+			keys[fmt.Sprint(k.Interface())] = k
+		}
+		for _, name := range slices.Sorted(maps.Keys(keys)) {
+			k := keys[name]
+			av, bv := a.MapIndex(k), b.MapIndex(k)
+			switch {
+			case !av.IsValid():
+				*out = append(*out, Change{Path: joinPath(path, name), New: bv.Interface()})
+			case !bv.IsValid():
+				*out = append(*out, Change{Path: joinPath(path, name), Old: av.Interface()})
+			default:
+				diffValue(out, joinPath(path, name), av, bv)
+			}
+		}
+	case reflect.Slice:
+		if a.Len() != b.Len() {
+			*out = append(*out, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+			return
+		}
+		for i := 0; i < a.Len(); i++ {
+			diffValue(out, fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i))
+		}
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			*out = append(*out, Change{Path: path, Old: a.Interface(), New: b.Interface()})
+		}
+	}
+}
+
+// jsonName returns the encoded name of an exported field, or false for
+// fields the JSON form does not carry (decoded effect schemas).
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return f.Name, true
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func compactJSON(raw []byte) json.RawMessage {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return raw
+	}
+	return buf.Bytes()
+}
+
+func redactValue(v any) any {
+	if s, ok := v.(string); ok {
+		return RedactDSN(s)
+	}
+	return v
+}
+
+func formatValue(v any) string {
+	if v == nil {
+		return "<unset>"
+	}
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,257 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Typed effect schemas. Each element, ability and item key in gameplay.json
+// has exactly one schema; its JSON object is decoded strictly so a misspelt
+// rule is an error instead of a silently absent one.
+
+// ElementPassives is one of the *<Element>Passives types.
+type ElementPassives interface{ elementPassives() }
+
+// AbilityRules is one of the *<Ability>Rules types.
+type AbilityRules interface{ abilityRules() }
+
+// ItemEffects is one of the *<Item>Effects types.
+type ItemEffects interface{ itemEffects() }
+
+type WaterPassives struct {
+	ConsumableMultiplier                   int `json:"consumable_multiplier"`
+	MultiplierNegatedWhenOpponentElementID int `json:"multiplier_negated_when_opponent_element_id"`
+}
+
+type FirePassives struct {
+	OffensiveAbilitiesResolveFirst                bool `json:"offensive_abilities_resolve_first"`
+	OffensiveAbilitiesFizzleWhenOpponentElementID int  `json:"offensive_abilities_fizzle_when_opponent_element_id"`
+}
+
+type EarthPassives struct {
+	NullifyRemoteOffensiveCapture             bool `json:"nullify_remote_offensive_capture"`
+	NullificationNegatedWhenOpponentElementID int  `json:"nullification_negated_when_opponent_element_id"`
+}
+
+type AirWindPassives struct {
+	NegateDefensiveAbilities             bool `json:"negate_defensive_abilities"`
+	SlidingPiecesIgnoreBlockers          bool `json:"sliding_pieces_ignore_blockers"`
+	PassivesNegatedWhenOpponentElementID int  `json:"passives_negated_when_opponent_element_id"`
+}
+
+type LightningPassives struct {
+	ArmyAbilitiesSlottableInPieceTypeSlots bool    `json:"army_abilities_slottable_in_piece_type_slots"`
+	AbilityMisfireAgainstOpponentElementID int     `json:"ability_misfire_against_opponent_element_id"`
+	AbilityMisfireChance                   float64 `json:"ability_misfire_chance"`
+}
+
+func (*WaterPassives) elementPassives()     {}
+func (*FirePassives) elementPassives()      {}
+func (*EarthPassives) elementPassives()     {}
+func (*AirWindPassives) elementPassives()   {}
+func (*LightningPassives) elementPassives() {}
+
+type BlockPathRules struct {
+	PostMoveChoice                           string `json:"post_move_choice"`
+	PersistsUntilPieceMovesAgain             bool   `json:"persists_until_piece_moves_again"`
+	KnightsIgnoredForDirectionalCaptureTests bool   `json:"knights_ignored_for_directional_capture_tests"`
+}
+
+type StalwartRules struct {
+	IllegalIfCapturerRankLtVictimRank bool `json:"illegal_if_capturer_rank_lt_victim_rank"`
+}
+
+type BelligerentRules struct {
+	IllegalIfCapturerRankGtVictimRank bool `json:"illegal_if_capturer_rank_gt_victim_rank"`
+}
+
+type RedoRules struct {
+	TriggerOnCapture                 bool `json:"trigger_on_capture"`
+	RewindPlies                      int  `json:"rewind_plies"`
+	SpentChargePersistsAfterRewind   bool `json:"spent_charge_persists_after_rewind"`
+	DefensiveNegationDisablesTrigger bool `json:"defensive_negation_disables_trigger"`
+}
+
+type DoubleKillRules struct {
+	TriggerAfterSuccessfulPrimaryCapture                    bool   `json:"trigger_after_successful_primary_capture"`
+	RemoveOneNeighboringEnemyPieceRankLteCapturedVictimRank bool   `json:"remove_one_neighboring_enemy_piece_rank_lte_captured_victim_rank"`
+	Neighborhood                                            string `json:"neighborhood"`
+	DeterministicTiebreak                                   string `json:"deterministic_tiebreak"`
+}
+
+type QuantumKillRules struct {
+	TriggerAfterSuccessfulPrimaryCapture               bool   `json:"trigger_after_successful_primary_capture"`
+	RemoveOneRandomEnemyPieceRankLteCapturedVictimRank bool   `json:"remove_one_random_enemy_piece_rank_lte_captured_victim_rank"`
+	RNG                                                string `json:"rng"`
+	TimelineMustDiscloseChosenVictim                   bool   `json:"timeline_must_disclose_chosen_victim"`
+}
+
+type ChainKillRules struct {
+	IsPrimaryAction                      bool `json:"is_primary_action"`
+	RemoteCapture                        bool `json:"remote_capture"`
+	RequiresAdjacentAlliedPiggybackPiece bool `json:"requires_adjacent_allied_piggyback_piece"`
+	CapturerDoesNotMove                  bool `json:"capturer_does_not_move"`
+}
+
+type NecromancerRules struct {
+	TriggerAfterCapturingHigherRankPiece                    bool   `json:"trigger_after_capturing_higher_rank_piece"`
+	RestoreOneCapturedFriendlyPieceRankLtCapturedVictimRank bool   `json:"restore_one_captured_friendly_piece_rank_lt_captured_victim_rank"`
+	RestoreAtOriginalCaptureSquareIfEmpty                   bool   `json:"restore_at_original_capture_square_if_empty"`
+	DeterministicChoice                                     string `json:"deterministic_choice"`
+}
+
+func (*BlockPathRules) abilityRules()   {}
+func (*StalwartRules) abilityRules()    {}
+func (*BelligerentRules) abilityRules() {}
+func (*RedoRules) abilityRules()        {}
+func (*DoubleKillRules) abilityRules()  {}
+func (*QuantumKillRules) abilityRules() {}
+func (*ChainKillRules) abilityRules()   {}
+func (*NecromancerRules) abilityRules() {}
+
+// Charge models for consumable abilities.
+const (
+	ChargesPerPiece = "per_piece"
+	ChargesSidePool = "side_pool"
+)
+
+// AbilityCharges is shared by all consumable abilities; the default matching
+// Model is the one that applies.
+type AbilityCharges struct {
+	Model                               string `json:"model"`
+	DefaultPerPiece                     int    `json:"default_per_piece"`
+	DefaultSidePool                     int    `json:"default_side_pool"`
+	WaterMultiplierAppliesAtBattleStart bool   `json:"water_multiplier_applies_at_battle_start"`
+}
+
+type MultitaskersScheduleEffects struct {
+	AllowArmyAbilityInPieceTypeSlotsForNonLightning bool `json:"allow_army_ability_in_piece_type_slots_for_non_lightning"`
+}
+
+type PoisonedDaggerEffects struct {
+	OnYourPieceCapturedRemoveCapturerIfCapturerRankLteVictimRank bool `json:"on_your_piece_captured_remove_capturer_if_capturer_rank_lte_victim_rank"`
+	KingIsImmuneToItemRemoval                                    bool `json:"king_is_immune_to_item_removal"`
+}
+
+// ArmySlotsEffects is shared by the adept gloves and the headmaster ring.
+type ArmySlotsEffects struct {
+	ArmyAbilitySlotsBonus int `json:"army_ability_slots_bonus"`
+}
+
+type PotOfHungerEffects struct {
+	XPMultiplierOnWin float64 `json:"xp_multiplier_on_win"`
+}
+
+type SolarNecklaceEffects struct {
+	TopUpConsumableCharge bool `json:"top_up_consumable_charge"`
+	TopUpAmount           int  `json:"top_up_amount"`
+	MaxTopUpsPerMatch     int  `json:"max_top_ups_per_match"`
+}
+
+func (*MultitaskersScheduleEffects) itemEffects() {}
+func (*PoisonedDaggerEffects) itemEffects()       {}
+func (*ArmySlotsEffects) itemEffects()            {}
+func (*PotOfHungerEffects) itemEffects()          {}
+func (*SolarNecklaceEffects) itemEffects()        {}
+
+var elementSchemas = map[string]func() ElementPassives{
+	"water":     func() ElementPassives { return new(WaterPassives) },
+	"fire":      func() ElementPassives { return new(FirePassives) },
+	"earth":     func() ElementPassives { return new(EarthPassives) },
+	"air_wind":  func() ElementPassives { return new(AirWindPassives) },
+	"lightning": func() ElementPassives { return new(LightningPassives) },
+}
+
+var abilitySchemas = map[string]func() AbilityRules{
+	"block_path":   func() AbilityRules { return new(BlockPathRules) },
+	"stalwart":     func() AbilityRules { return new(StalwartRules) },
+	"belligerent":  func() AbilityRules { return new(BelligerentRules) },
+	"redo":         func() AbilityRules { return new(RedoRules) },
+	"double_kill":  func() AbilityRules { return new(DoubleKillRules) },
+	"quantum_kill": func() AbilityRules { return new(QuantumKillRules) },
+	"chain_kill":   func() AbilityRules { return new(ChainKillRules) },
+	"necromancer":  func() AbilityRules { return new(NecromancerRules) },
+}
+
+var itemSchemas = map[string]func() ItemEffects{
+	"multitaskers_schedule": func() ItemEffects { return new(MultitaskersScheduleEffects) },
+	"poisoned_dagger":       func() ItemEffects { return new(PoisonedDaggerEffects) },
+	"dual_adepts_gloves":    func() ItemEffects { return new(ArmySlotsEffects) },
+	"triple_adepts_gloves":  func() ItemEffects { return new(ArmySlotsEffects) },
+	"headmaster_ring":       func() ItemEffects { return new(ArmySlotsEffects) },
+	"pot_of_hunger":         func() ItemEffects { return new(PotOfHungerEffects) },
+	"solar_necklace":        func() ItemEffects { return new(SolarNecklaceEffects) },
+}
+
+// decodeStrict decodes a JSON object into dst, rejecting unknown keys. An
+// absent object (nil or null) leaves dst zero.
+func decodeStrict(raw json.RawMessage, dst any) error {
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("unexpected trailing data")
+	}
+	return nil
+}
+
+// resolveEffects decodes every raw effect block into its typed schema.
+func (cfg *GameplayConfig) resolveEffects(p *problems) {
+	for i := range cfg.Elements {
+		e := &cfg.Elements[i]
+		schema, ok := elementSchemas[e.Key]
+		if !ok {
+			p.addf("element %d: no passives schema for key %q", e.ID, e.Key)
+			continue
+		}
+		e.Passives = schema()
+		if err := decodeStrict(e.RawPassives, e.Passives); err != nil {
+			p.addf("element %d (%s) passives: %v", e.ID, e.Key, err)
+		}
+	}
+	for i := range cfg.Abilities {
+		a := &cfg.Abilities[i]
+		schema, ok := abilitySchemas[a.Key]
+		if !ok {
+			p.addf("ability %d: no rules schema for key %q", a.ID, a.Key)
+		} else {
+			a.Rules = schema()
+			if err := decodeStrict(a.RawRules, a.Rules); err != nil {
+				p.addf("ability %d (%s) rules: %v", a.ID, a.Key, err)
+			}
+		}
+		if len(a.RawCharges) > 0 {
+			a.Charges = new(AbilityCharges)
+			if err := decodeStrict(a.RawCharges, a.Charges); err != nil {
+				p.addf("ability %d (%s) charges: %v", a.ID, a.Key, err)
+			}
+		}
+	}
+	for i := range cfg.Items {
+		it := &cfg.Items[i]
+		schema, ok := itemSchemas[it.Key]
+		if !ok {
+			p.addf("item %d: no effects schema for key %q", it.ID, it.Key)
+			continue
+		}
+		it.Effects = schema()
+		if err := decodeStrict(it.RawEffects, it.Effects); err != nil {
+			p.addf("item %d (%s) effects: %v", it.ID, it.Key, err)
+		}
+	}
+}
+
+// problems collects every validation failure so lint output is complete.
+type problems []error
+
+func (p *problems) addf(format string, args ...any) {
+	*p = append(*p, fmt.Errorf("gameplay config: "+format, args...))
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts every server config environment variable. The rest of the
+// name is the JSON path upper-cased with '_' separators:
+// ws.limits.messages.per_second is MVP_WS_LIMITS_MESSAGES_PER_SECOND.
+const EnvPrefix = "MVP_"
+
+// EnvVar names the environment variable that overrides one ServerConfig
+// field.
+type EnvVar struct {
+	Name string
+	Path string
+}
+
+// ServerEnvVars lists one variable per ServerConfig leaf, in field order.
+func ServerEnvVars() []EnvVar {
+	var out []EnvVar
+	walkEnv(reflect.ValueOf(&ServerConfig{}).Elem(), "", func(v EnvVar, _ reflect.Value) {
+		out = append(out, v)
+	})
+	return out
+}
+
+// ApplyEnv overrides cfg fields from the variables lookup reports as set
+// (normally os.LookupEnv). Scalars use their Go syntax, string lists are
+// comma-separated and maps are a JSON object that replaces the whole map.
+// The result is not validated.
+func ApplyEnv(cfg *ServerConfig, lookup func(string) (string, bool)) error {
+	var err error
+	walkEnv(reflect.ValueOf(cfg).Elem(), "", func(v EnvVar, field reflect.Value) {
+		raw, ok := lookup(v.Name)
+		if !ok || err != nil {
+			return
+		}
+		if perr := setFromEnv(field, raw); perr != nil {
+			err = fmt.Errorf("server config: %s (%s): %v", v.Name, v.Path, perr)
+		}
+	})
+	return err
+}
+
+func walkEnv(v reflect.Value, path string, visit func(EnvVar, reflect.Value)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, ok := jsonName(t.Field(i))
+		if !ok {
+			continue
+		}
+		fieldPath := joinPath(path, name)
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			walkEnv(field, fieldPath, visit)
+			continue
+		}
+		visit(EnvVar{Name: envName(fieldPath), Path: fieldPath}, field)
+	}
+}
+
+func envName(path string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(path, ".", "_"))
+}
+
+func setFromEnv(field reflect.Value, raw string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", field.Type())
+		}
+		list := []string{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	case reflect.Map:
+		m := reflect.New(field.Type())
+		dec := json.NewDecoder(strings.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(m.Interface()); err != nil {
+			return err
+		}
+		field.Set(m.Elem())
+	default:
+		return fmt.Errorf("unsupported type %s", field.Type())
+	}
+	return nil
+}
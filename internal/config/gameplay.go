@@ -3,8 +3,8 @@ package config
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"os"
+	"slices"
 )
 
 type GameplayConfig struct {
@@ -15,6 +15,7 @@ type GameplayConfig struct {
 	AbilitySets   AbilitySetsConfig `json:"ability_sets"`
 	Abilities     []AbilityConfig   `json:"abilities"`
 	Items         []ItemConfig      `json:"items"`
+	LoadoutRules  LoadoutRules      `json:"loadout_rules"`
 }
 
 type GameplayCanon struct {
@@ -29,11 +30,14 @@ type PieceTypeConfig struct {
 	Rank int    `json:"rank"`
 }
 
+// Effect blocks are kept raw as read and decoded into their typed schema
+// (effects.go) by Validate.
 type ElementConfig struct {
-	ID       int            `json:"id"`
-	Key      string         `json:"key"`
-	Name     string         `json:"name"`
-	Passives map[string]any `json:"passives"`
+	ID          int             `json:"id"`
+	Key         string          `json:"key"`
+	Name        string          `json:"name"`
+	RawPassives json.RawMessage `json:"passives"`
+	Passives    ElementPassives `json:"-"`
 }
 
 type AbilitySetsConfig struct {
@@ -43,26 +47,66 @@ type AbilitySetsConfig struct {
 }
 
 type AbilityConfig struct {
-	ID         int            `json:"id"`
-	Key        string         `json:"key"`
-	Name       string         `json:"name"`
-	Scope      string         `json:"scope"`
-	Category   string         `json:"category"`
-	Consumable bool           `json:"consumable"`
-	Charges    map[string]any `json:"charges"`
-	Rules      map[string]any `json:"rules"`
+	ID         int             `json:"id"`
+	Key        string          `json:"key"`
+	Name       string          `json:"name"`
+	Scope      string          `json:"scope"`
+	Category   string          `json:"category"`
+	Consumable bool            `json:"consumable"`
+	RawCharges json.RawMessage `json:"charges"`
+	RawRules   json.RawMessage `json:"rules"`
+	// Charges is nil for non-consumable abilities.
+	Charges *AbilityCharges `json:"-"`
+	Rules   AbilityRules    `json:"-"`
 }
 
 type ItemConfig struct {
-	ID                  int            `json:"id"`
-	Key                 string         `json:"key"`
-	Name                string         `json:"name"`
-	SlotCost            int            `json:"slot_cost"`
-	Effects             map[string]any `json:"effects"`
-	IncompatibleItemIDs []int          `json:"incompatible_item_ids"`
+	ID                  int             `json:"id"`
+	Key                 string          `json:"key"`
+	Name                string          `json:"name"`
+	SlotCost            int             `json:"slot_cost"`
+	RawEffects          json.RawMessage `json:"effects"`
+	IncompatibleItemIDs []int           `json:"incompatible_item_ids"`
+	Effects             ItemEffects     `json:"-"`
 }
 
+// LoadoutRules bounds what a loadout may hold. IDs refer to elements and
+// items of the same config.
+type LoadoutRules struct {
+	ItemSlotsTotal           int                  `json:"item_slots_total"`
+	BaseArmyAbilitySlots     int                  `json:"base_army_ability_slots"`
+	MaxArmyAbilitySlotsTotal int                  `json:"max_army_ability_slots_total"`
+	PieceTypeSlots           []string             `json:"piece_type_slots"`
+	ArmyAbilityPlacement     ArmyAbilityPlacement `json:"army_ability_placement"`
+}
+
+type ArmyAbilityPlacement struct {
+	ArmySlotsAlwaysAllowArmyAbilities  bool               `json:"army_slots_always_allow_army_abilities"`
+	PieceTypeSlotsAllowArmyAbilitiesIf PlacementCondition `json:"piece_type_slots_allow_army_abilities_if"`
+}
+
+// PlacementCondition is met when the army's element is ElementIDIs or one of
+// its items is OrItemIDIs. A nil field never matches.
+type PlacementCondition struct {
+	ElementIDIs *int `json:"element_id_is"`
+	OrItemIDIs  *int `json:"or_item_id_is"`
+}
+
+// LoadGameplayConfig reads and validates path; the error joins every
+// problem found.
 func LoadGameplayConfig(path string) (GameplayConfig, error) {
+	cfg, err := ReadGameplayConfig(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ReadGameplayConfig strictly decodes path without validating it.
+func ReadGameplayConfig(path string) (GameplayConfig, error) {
 	var cfg GameplayConfig
 	file, err := os.Open(path)
 	if err != nil {
@@ -78,69 +122,346 @@ func LoadGameplayConfig(path string) (GameplayConfig, error) {
 	if dec.More() {
 		return cfg, errors.New("gameplay config: unexpected trailing data")
 	}
-	if err := cfg.Validate(); err != nil {
-		return cfg, err
-	}
 	return cfg, nil
 }
 
-func (cfg GameplayConfig) Validate() error {
+// Validate decodes the typed effect schemas and reports all problems joined;
+// see Lint.
+func (cfg *GameplayConfig) Validate() error {
+	return errors.Join(cfg.Lint()...)
+}
+
+// Lint decodes the typed effect schemas and runs every check, returning all
+// problems instead of stopping at the first.
+func (cfg *GameplayConfig) Lint() []error {
+	var p problems
 	if cfg.SchemaVersion != 1 {
-		return fmt.Errorf("gameplay config: schema_version must be 1")
+		p.addf("schema_version must be 1")
 	}
 	if cfg.Canon.ElementsCount <= 0 || cfg.Canon.ItemsCount <= 0 || cfg.Canon.AbilitiesCount <= 0 {
-		return fmt.Errorf("gameplay config: canon counts must be > 0")
+		p.addf("canon counts must be > 0")
+		return p
 	}
 	if len(cfg.Elements) != cfg.Canon.ElementsCount {
-		return fmt.Errorf("gameplay config: elements count mismatch")
+		p.addf("elements count mismatch")
 	}
 	if len(cfg.Items) != cfg.Canon.ItemsCount {
-		return fmt.Errorf("gameplay config: items count mismatch")
+		p.addf("items count mismatch")
 	}
 	if len(cfg.Abilities) != cfg.Canon.AbilitiesCount {
-		return fmt.Errorf("gameplay config: abilities count mismatch")
+		p.addf("abilities count mismatch")
 	}
+	cfg.validatePieceTypes(&p)
+	validateSequentialIDs(&p, cfg.Elements, cfg.Canon.ElementsCount, 0, "elements")
+	validateSequentialIDs(&p, cfg.Items, cfg.Canon.ItemsCount, 1, "items")
+	validateSequentialIDs(&p, cfg.Abilities, cfg.Canon.AbilitiesCount, 1, "abilities")
+	validateUniqueKeys(&p, cfg.Elements, "elements")
+	validateUniqueKeys(&p, cfg.Items, "items")
+	validateUniqueKeys(&p, cfg.Abilities, "abilities")
+	cfg.resolveEffects(&p)
+	cfg.validateElements(&p)
+	cfg.validateAbilities(&p)
+	cfg.validateAbilitySets(&p)
+	cfg.validateItems(&p)
+	cfg.validateLoadoutRules(&p)
+	return p
+}
+
+func (cfg GameplayConfig) validatePieceTypes(p *problems) {
 	if len(cfg.PieceTypes) == 0 {
-		return fmt.Errorf("gameplay config: piece_types must be non-empty")
+		p.addf("piece_types must be non-empty")
+	}
+	seen := make(map[string]bool, len(cfg.PieceTypes))
+	for _, pt := range cfg.PieceTypes {
+		if seen[pt.Key] {
+			p.addf("piece type %q duplicated", pt.Key)
+		}
+		seen[pt.Key] = true
+		if pt.Rank <= 0 {
+			p.addf("piece type %q rank must be > 0", pt.Key)
+		}
 	}
-	if err := validateSequentialIDs(cfg.Elements, cfg.Canon.ElementsCount, 0, "elements"); err != nil {
-		return err
+}
+
+func (cfg GameplayConfig) validateElements(p *problems) {
+	for _, e := range cfg.Elements {
+		var refs []int
+		switch passives := e.Passives.(type) {
+		case *WaterPassives:
+			if passives.ConsumableMultiplier < 1 {
+				p.addf("element %d consumable_multiplier must be >= 1", e.ID)
+			}
+			refs = append(refs, passives.MultiplierNegatedWhenOpponentElementID)
+		case *FirePassives:
+			refs = append(refs, passives.OffensiveAbilitiesFizzleWhenOpponentElementID)
+		case *EarthPassives:
+			refs = append(refs, passives.NullificationNegatedWhenOpponentElementID)
+		case *AirWindPassives:
+			refs = append(refs, passives.PassivesNegatedWhenOpponentElementID)
+		case *LightningPassives:
+			if passives.AbilityMisfireChance < 0 || passives.AbilityMisfireChance > 1 {
+				p.addf("element %d ability_misfire_chance must be within [0, 1]", e.ID)
+			}
+			refs = append(refs, passives.AbilityMisfireAgainstOpponentElementID)
+		}
+		for _, ref := range refs {
+			if ref == e.ID || !cfg.hasElement(ref) {
+				p.addf("element %d passives reference invalid opponent element %d", e.ID, ref)
+			}
+		}
 	}
-	if err := validateSequentialIDs(cfg.Items, cfg.Canon.ItemsCount, 1, "items"); err != nil {
-		return err
+}
+
+func (cfg GameplayConfig) validateAbilities(p *problems) {
+	for _, a := range cfg.Abilities {
+		switch a.Scope {
+		case "piece_type", "army_wide":
+		default:
+			p.addf("ability %d scope %q must be piece_type or army_wide", a.ID, a.Scope)
+		}
+		switch a.Category {
+		case "defensive", "offensive":
+		default:
+			p.addf("ability %d category %q must be defensive or offensive", a.ID, a.Category)
+		}
+		if a.Consumable != (len(a.RawCharges) > 0) {
+			p.addf("ability %d charges must be present exactly when consumable", a.ID)
+		}
+		if a.Charges == nil {
+			continue
+		}
+		switch a.Charges.Model {
+		case ChargesPerPiece:
+			if a.Charges.DefaultPerPiece <= 0 || a.Charges.DefaultSidePool != 0 {
+				p.addf("ability %d per_piece charges need default_per_piece > 0 and no default_side_pool", a.ID)
+			}
+		case ChargesSidePool:
+			if a.Charges.DefaultSidePool <= 0 || a.Charges.DefaultPerPiece != 0 {
+				p.addf("ability %d side_pool charges need default_side_pool > 0 and no default_per_piece", a.ID)
+			}
+		default:
+			p.addf("ability %d charges model %q must be per_piece or side_pool", a.ID, a.Charges.Model)
+		}
+	}
+}
+
+// validateAbilitySets checks that the sets reference real abilities and agree
+// with each ability's category.
+func (cfg GameplayConfig) validateAbilitySets(p *problems) {
+	sets := []struct {
+		name     string
+		ids      []int
+		category string
+	}{
+		{"defensive_ability_ids", cfg.AbilitySets.DefensiveAbilityIDs, "defensive"},
+		{"offensive_ability_ids", cfg.AbilitySets.OffensiveAbilityIDs, "offensive"},
+		{"remote_offensive_capture_ability_ids", cfg.AbilitySets.RemoteOffensiveCaptureIDs, "offensive"},
 	}
-	if err := validateSequentialIDs(cfg.Abilities, cfg.Canon.AbilitiesCount, 1, "abilities"); err != nil {
-		return err
+	for _, set := range sets {
+		for _, id := range set.ids {
+			a, ok := cfg.ability(id)
+			if !ok {
+				p.addf("ability_sets.%s references unknown ability %d", set.name, id)
+				continue
+			}
+			if a.Category != set.category {
+				p.addf("ability_sets.%s lists ability %d whose category is %q", set.name, id, a.Category)
+			}
+		}
+	}
+	for _, a := range cfg.Abilities {
+		var ids []int
+		switch a.Category {
+		case "defensive":
+			ids = cfg.AbilitySets.DefensiveAbilityIDs
+		case "offensive":
+			ids = cfg.AbilitySets.OffensiveAbilityIDs
+		default:
+			continue
+		}
+		if !slices.Contains(ids, a.ID) {
+			p.addf("ability %d (%s) missing from ability_sets.%s_ability_ids", a.ID, a.Key, a.Category)
+		}
 	}
-	return nil
+}
+
+func (cfg GameplayConfig) validateItems(p *problems) {
+	for _, item := range cfg.Items {
+		if item.SlotCost <= 0 {
+			p.addf("item %d slot_cost must be > 0", item.ID)
+		}
+		switch effects := item.Effects.(type) {
+		case *ArmySlotsEffects:
+			if effects.ArmyAbilitySlotsBonus <= 0 {
+				p.addf("item %d army_ability_slots_bonus must be > 0", item.ID)
+			}
+		case *PotOfHungerEffects:
+			if effects.XPMultiplierOnWin < 1 {
+				p.addf("item %d xp_multiplier_on_win must be >= 1", item.ID)
+			}
+		case *SolarNecklaceEffects:
+			if effects.TopUpConsumableCharge && (effects.TopUpAmount <= 0 || effects.MaxTopUpsPerMatch <= 0) {
+				p.addf("item %d top_up_amount and max_top_ups_per_match must be > 0", item.ID)
+			}
+		}
+		for _, other := range item.IncompatibleItemIDs {
+			if other == item.ID {
+				p.addf("item %d incompatible with itself", item.ID)
+				continue
+			}
+			peer, ok := cfg.item(other)
+			if !ok {
+				p.addf("item %d incompatible_item_ids references unknown item %d", item.ID, other)
+				continue
+			}
+			if !slices.Contains(peer.IncompatibleItemIDs, item.ID) {
+				p.addf("item %d lists item %d as incompatible but not the reverse", item.ID, other)
+			}
+		}
+	}
+}
+
+func (cfg GameplayConfig) validateLoadoutRules(p *problems) {
+	rules := cfg.LoadoutRules
+	if rules.ItemSlotsTotal <= 0 {
+		p.addf("loadout_rules.item_slots_total must be > 0")
+	}
+	if rules.BaseArmyAbilitySlots <= 0 || rules.BaseArmyAbilitySlots > rules.MaxArmyAbilitySlotsTotal {
+		p.addf("loadout_rules.base_army_ability_slots must be between 1 and max_army_ability_slots_total")
+	}
+	if len(rules.PieceTypeSlots) == 0 {
+		p.addf("loadout_rules.piece_type_slots must be non-empty")
+	}
+	seen := make(map[string]bool, len(rules.PieceTypeSlots))
+	for _, key := range rules.PieceTypeSlots {
+		if seen[key] {
+			p.addf("loadout_rules.piece_type_slots duplicates %q", key)
+		}
+		seen[key] = true
+		if !cfg.hasPieceType(key) {
+			p.addf("loadout_rules.piece_type_slots references unknown piece type %q", key)
+		}
+	}
+	for _, item := range cfg.Items {
+		if rules.ItemSlotsTotal > 0 && item.SlotCost > rules.ItemSlotsTotal {
+			p.addf("item %d slot_cost exceeds loadout_rules.item_slots_total", item.ID)
+		}
+	}
+	cond := rules.ArmyAbilityPlacement.PieceTypeSlotsAllowArmyAbilitiesIf
+	if cond.ElementIDIs != nil && !cfg.hasElement(*cond.ElementIDIs) {
+		p.addf("loadout_rules element_id_is references unknown element %d", *cond.ElementIDIs)
+	}
+	if cond.OrItemIDIs != nil && !cfg.hasItem(*cond.OrItemIDIs) {
+		p.addf("loadout_rules or_item_id_is references unknown item %d", *cond.OrItemIDIs)
+	}
+	// The placement condition restates an element passive and an item
+	// effect; they must not drift apart.
+	for _, e := range cfg.Elements {
+		passives, _ := e.Passives.(*LightningPassives)
+		flagged := passives != nil && passives.ArmyAbilitiesSlottableInPieceTypeSlots
+		if flagged != (cond.ElementIDIs != nil && *cond.ElementIDIs == e.ID) {
+			p.addf("element %d piece-type slot passive disagrees with loadout_rules", e.ID)
+		}
+	}
+	for _, item := range cfg.Items {
+		effects, _ := item.Effects.(*MultitaskersScheduleEffects)
+		flagged := effects != nil && effects.AllowArmyAbilityInPieceTypeSlotsForNonLightning
+		if flagged != (cond.OrItemIDIs != nil && *cond.OrItemIDIs == item.ID) {
+			p.addf("item %d piece-type slot effect disagrees with loadout_rules", item.ID)
+		}
+	}
+	if !rules.ArmyAbilityPlacement.ArmySlotsAlwaysAllowArmyAbilities {
+		p.addf("loadout_rules.army_ability_placement.army_slots_always_allow_army_abilities must be true")
+	}
+}
+
+func (cfg GameplayConfig) hasElement(id int) bool {
+	for _, e := range cfg.Elements {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (cfg GameplayConfig) hasItem(id int) bool {
+	_, ok := cfg.item(id)
+	return ok
+}
+
+func (cfg GameplayConfig) item(id int) (ItemConfig, bool) {
+	for _, item := range cfg.Items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return ItemConfig{}, false
+}
+
+func (cfg GameplayConfig) ability(id int) (AbilityConfig, bool) {
+	for _, a := range cfg.Abilities {
+		if a.ID == id {
+			return a, true
+		}
+	}
+	return AbilityConfig{}, false
+}
+
+func (cfg GameplayConfig) hasPieceType(key string) bool {
+	for _, p := range cfg.PieceTypes {
+		if p.Key == key {
+			return true
+		}
+	}
+	return false
 }
 
 type idProvider interface {
 	getID() int
+	getKey() string
 }
 
-func validateSequentialIDs[T idProvider](items []T, count int, minID int, label string) error {
+func validateSequentialIDs[T idProvider](p *problems, items []T, count int, minID int, label string) {
 	seen := make([]bool, count)
 	maxID := minID + count - 1
 	for _, item := range items {
 		id := item.getID()
 		if id < minID || id > maxID {
-			return fmt.Errorf("gameplay config: %s id %d out of range", label, id)
+			p.addf("%s id %d out of range", label, id)
+			continue
 		}
 		index := id - minID
 		if seen[index] {
-			return fmt.Errorf("gameplay config: %s id %d duplicated", label, id)
+			p.addf("%s id %d duplicated", label, id)
 		}
 		seen[index] = true
 	}
 	for i, ok := range seen {
 		if !ok {
-			return fmt.Errorf("gameplay config: %s id %d missing", label, i+minID)
+			p.addf("%s id %d missing", label, i+minID)
 		}
 	}
-	return nil
+}
+
+func validateUniqueKeys[T idProvider](p *problems, items []T, label string) {
+	seen := make(map[string]int, len(items))
+	for _, item := range items {
+		key := item.getKey()
+		if key == "" {
+			p.addf("%s id %d has an empty key", label, item.getID())
+			continue
+		}
+		if prev, dup := seen[key]; dup {
+			p.addf("%s ids %d and %d share key %q", label, prev, item.getID(), key)
+		}
+		seen[key] = item.getID()
+	}
 }
 
 func (e ElementConfig) getID() int { return e.ID }
 func (a AbilityConfig) getID() int { return a.ID }
 func (i ItemConfig) getID() int    { return i.ID }
+
+func (e ElementConfig) getKey() string { return e.Key }
+func (a AbilityConfig) getKey() string { return a.Key }
+func (i ItemConfig) getKey() string    { return i.Key }
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces credentials in printed DSNs.
+const redacted = "REDACTED"
+
+// secretParams are the DSN parameters that carry credentials, in either the
+// URL query or the libpq key=value form.
+var secretParams = []string{"password", "sslpassword"}
+
+// kvSecret matches a credential in a libpq key=value DSN; the value is either
+// single-quoted (with backslash escapes) or runs to the next space or '&'.
+var kvSecret = regexp.MustCompile(`\b(` + strings.Join(secretParams, "|") + `)\s*=\s*('(?:[^'\\]|\\.)*'|[^\s&]*)`)
+
+// RedactDSN returns dsn with its credentials replaced: the userinfo of a URL
+// DSN ("postgres://user:pw@host/db") and any password parameter. The rest is
+// kept so the output still shows which database is configured.
+func RedactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.Scheme != "" && u.Opaque == "" {
+		if u.User != nil {
+			u.User = url.User(redacted)
+		}
+		q := u.Query()
+		for _, p := range secretParams {
+			if q.Has(p) {
+				q.Set(p, redacted)
+				u.RawQuery = q.Encode()
+			}
+		}
+		return u.String()
+	}
+	return kvSecret.ReplaceAllString(dsn, "${1}="+redacted)
+}
+
+// Redacted returns a copy of c safe to print: the persistence DSNs have their
+// credentials removed.
+func (c ServerConfig) Redacted() ServerConfig {
+	c.Persistence.Dev.DSN = RedactDSN(c.Persistence.Dev.DSN)
+	c.Persistence.Prod.DSN = RedactDSN(c.Persistence.Prod.DSN)
+	return c
+}
@@ -0,0 +1,27 @@
+package config
+
+import "testing"
+
+func TestRedactDSN(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"file:dev.db?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)", "file:dev.db?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"},
+		{"postgres://mvp:hunter2@db:5432/mvp?sslmode=require", "postgres://REDACTED@db:5432/mvp?sslmode=require"},
+		{"postgres://db/mvp?user=mvp&password=hunter2", "postgres://db/mvp?password=REDACTED&user=mvp"},
+		{"host=db user=mvp password=hunter2 dbname=mvp", "host=db user=mvp password=REDACTED dbname=mvp"},
+		{"host=db password='hunter 2\\'x' sslpassword = k dbname=mvp", "host=db password=REDACTED sslpassword=REDACTED dbname=mvp"},
+		{"", ""},
+	}
+	for _, c := range cases {
+		if got := RedactDSN(c.in); got != c.want {
+			t.Errorf("RedactDSN(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestChangeStringRedactsDSN(t *testing.T) {
+	c := Change{Path: "persistence.prod.dsn", Old: "postgres://a:old@db/mvp", New: "postgres://a:new@db/mvp"}
+	want := `persistence.prod.dsn: "postgres://REDACTED@db/mvp" -> "postgres://REDACTED@db/mvp"`
+	if got := c.String(); got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,226 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Gameplay is one published version of the gameplay config. Published
+// versions are never modified, so a reader that needs stable rules can hold
+// on to one; Manager.Gameplay() always returns the newest.
+type Gameplay struct {
+	Version  uint64
+	LoadedAt time.Time
+	Config   *GameplayConfig
+}
+
+// liveServerPaths are the server settings applied without a restart; keep in
+// step with reloadServer. Any other changed field is logged and ignored until
+// the process restarts.
+var liveServerPaths = []string{
+	"ws.limits",
+}
+
+// Manager owns the server and gameplay configs after startup. Reload reads
+// both files, validates them and swaps in what changed; a file that fails to
+// load or validate leaves the current version in place.
+type Manager struct {
+	serverPath   string
+	gameplayPath string
+	override     func(*ServerConfig) error
+
+	server   atomic.Pointer[ServerConfig]
+	gameplay atomic.Pointer[Gameplay]
+
+	mu       sync.Mutex
+	version  uint64
+	stamps   map[string]fileStamp
+	onServer []func(ServerConfig)
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewManager loads and validates both configs. override, if non-nil, is
+// applied to every server config read (startup and reload) before it is
+// validated, so environment and flag overrides survive a reload.
+func NewManager(serverPath, gameplayPath string, override func(*ServerConfig) error) (*Manager, error) {
+	m := &Manager{
+		serverPath:   serverPath,
+		gameplayPath: gameplayPath,
+		override:     override,
+		stamps:       make(map[string]fileStamp),
+	}
+	m.stampFiles()
+	server, err := m.loadServer()
+	if err != nil {
+		return nil, fmt.Errorf("load server config: %w", err)
+	}
+	gameplay, err := LoadGameplayConfig(gameplayPath)
+	if err != nil {
+		return nil, fmt.Errorf("load gameplay config: %w", err)
+	}
+	m.server.Store(&server)
+	m.version = 1
+	m.gameplay.Store(&Gameplay{Version: m.version, LoadedAt: time.Now(), Config: &gameplay})
+	return m, nil
+}
+
+// Server returns the server config in force, including live-applied changes.
+func (m *Manager) Server() ServerConfig {
+	return *m.server.Load()
+}
+
+// Gameplay returns the newest gameplay version. Callers that need a stable
+// view (a battle) must keep the returned pointer rather than calling again.
+func (m *Manager) Gameplay() *Gameplay {
+	return m.gameplay.Load()
+}
+
+// OnServerChange registers fn to run after a reload changes a live server
+// setting. fn runs on the reloading goroutine with the new config.
+func (m *Manager) OnServerChange(fn func(ServerConfig)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onServer = append(m.onServer, fn)
+}
+
+// Reload re-reads both files. Each file is applied independently; the
+// returned error joins the load failures.
+func (m *Manager) Reload() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stampFiles()
+	var errs []error
+	if err := m.reloadServer(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := m.reloadGameplay(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func (m *Manager) loadServer() (ServerConfig, error) {
+	cfg, err := ReadServerConfig(m.serverPath)
+	if err != nil {
+		return cfg, err
+	}
+	if m.override != nil {
+		if err := m.override(&cfg); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+func (m *Manager) reloadServer() error {
+	next, err := m.loadServer()
+	if err != nil {
+		return fmt.Errorf("reload %s: %w", m.serverPath, err)
+	}
+	current := m.Server()
+	applied := current
+	applied.WS.Limits = next.WS.Limits
+	for _, c := range Diff(current, next) {
+		if isLiveServerPath(c.Path) {
+			slog.Info("config: server setting changed", "file", m.serverPath, "change", c.String())
+		} else {
+			slog.Warn("config: server setting needs restart, not applied", "file", m.serverPath, "change", c.String())
+		}
+	}
+	if len(Diff(current, applied)) == 0 {
+		return nil
+	}
+	m.server.Store(&applied)
+	for _, fn := range m.onServer {
+		fn(applied)
+	}
+	return nil
+}
+
+func (m *Manager) reloadGameplay() error {
+	next, err := LoadGameplayConfig(m.gameplayPath)
+	if err != nil {
+		return fmt.Errorf("reload %s: %w", m.gameplayPath, err)
+	}
+	current := m.Gameplay()
+	changes := Diff(*current.Config, next)
+	if len(changes) == 0 {
+		return nil
+	}
+	for _, c := range changes {
+		slog.Info("config: gameplay setting changed", "file", m.gameplayPath, "change", c.String())
+	}
+	m.version++
+	m.gameplay.Store(&Gameplay{Version: m.version, LoadedAt: time.Now(), Config: &next})
+	slog.Info("config: gameplay version published", "version", m.version, "previous", current.Version)
+	return nil
+}
+
+// Watch polls both files every interval and reloads when either one's size or
+// modification time changes. It returns when ctx is done.
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if !m.filesChanged() {
+				continue
+			}
+			if err := m.Reload(); err != nil {
+				slog.Error("config: reload failed", "err", err)
+			}
+		}
+	}
+}
+
+func (m *Manager) filesChanged() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, path := range []string{m.serverPath, m.gameplayPath} {
+		if stat(path) != m.stamps[path] {
+			return true
+		}
+	}
+	return false
+}
+
+// stampFiles records what the files look like as they are read, so Watch
+// does not reload a version it has already seen. Callers hold mu.
+func (m *Manager) stampFiles() {
+	for _, path := range []string{m.serverPath, m.gameplayPath} {
+		m.stamps[path] = stat(path)
+	}
+}
+
+// stat returns the zero stamp for a missing file; a file that disappears
+// and comes back therefore triggers a reload.
+func stat(path string) fileStamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{modTime: info.ModTime(), size: info.Size()}
+}
+
+func isLiveServerPath(path string) bool {
+	for _, live := range liveServerPaths {
+		if path == live || strings.HasPrefix(path, live+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReloadAppliesOnlyLiveServerPaths(t *testing.T) {
+	raw, err := os.ReadFile("../../config/server.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverPath := filepath.Join(t.TempDir(), "server.json")
+	if err := os.WriteFile(serverPath, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewManager(serverPath, "../../config/gameplay.json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var notified []ServerConfig
+	m.OnServerChange(func(cfg ServerConfig) { notified = append(notified, cfg) })
+	before := m.Server()
+
+	edited := strings.Replace(string(raw), `"max_conns_per_ip": 8`, `"max_conns_per_ip": 3`, 1)
+	edited = strings.Replace(edited, `"radius_cells": 1`, `"radius_cells": 2`, 1)
+	if edited == string(raw) {
+		t.Fatal("fixture edits did not apply")
+	}
+	if err := os.WriteFile(serverPath, []byte(edited), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	after := m.Server()
+	if after.WS.Limits.MaxConnsPerIP != 3 {
+		t.Errorf("ws.limits.max_conns_per_ip = %d, want 3 (live)", after.WS.Limits.MaxConnsPerIP)
+	}
+	if after.Overworld.GridAOI.RadiusCells != before.Overworld.GridAOI.RadiusCells {
+		t.Errorf("overworld.grid_aoi.radius_cells = %d, want %d (restart only)", after.Overworld.GridAOI.RadiusCells, before.Overworld.GridAOI.RadiusCells)
+	}
+	if len(notified) != 1 {
+		t.Errorf("OnServerChange ran %d times, want 1", len(notified))
+	}
+}
@@ -2,12 +2,15 @@
 package httpapi
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
+	"log/slog"
+	"net"
 	"net/http"
 	"strings"
 
 	"example.com/mvp-repo/internal/auth"
-	"example.com/mvp-repo/internal/persist"
 )
 
 type registerRequest struct {
@@ -26,6 +29,11 @@ type resetRequest struct {
 	Email string `json:"email"`
 }
 
+type resetCompleteRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
 type sessionResponse struct {
 	Token     string `json:"token"`
 	UserID    int64  `json:"user_id"`
@@ -33,6 +41,25 @@ type sessionResponse struct {
 	ExpiresAt int64  `json:"expires_at"`
 }
 
+// sessionInfo is one entry of GET /api/auth/sessions. ID identifies the
+// session without exposing its token.
+type sessionInfo struct {
+	ID         string `json:"id"`
+	Current    bool   `json:"current"`
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	ExpiresAt  int64  `json:"expires_at"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+}
+
+type sessionsResponse struct {
+	Sessions []sessionInfo `json:"sessions"`
+}
+
+// maxUserAgentBytes caps the User-Agent stored with a session.
+const maxUserAgentBytes = 256
+
 func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
@@ -49,7 +76,7 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "missing_fields", "email, username, and password are required")
 		return
 	}
-	session, err := s.auth.Register(r.Context(), req.Email, req.Username, req.Password)
+	session, err := s.auth.Register(r.Context(), req.Email, req.Username, req.Password, clientInfo(r))
 	if err != nil {
 		switch {
 		case errors.Is(err, auth.ErrAccountExists):
@@ -86,7 +113,7 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	var (
-		session persist.Session
+		session auth.IssuedSession
 		err     error
 	)
 	switch {
@@ -94,9 +121,9 @@ func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid_credentials", "provide only email or username")
 		return
 	case req.Email != "":
-		session, err = s.auth.LoginByEmail(r.Context(), req.Email, req.Password)
+		session, err = s.auth.LoginByEmail(r.Context(), req.Email, req.Password, clientInfo(r))
 	case req.Username != "":
-		session, err = s.auth.LoginByUsername(r.Context(), req.Username, req.Password)
+		session, err = s.auth.LoginByUsername(r.Context(), req.Username, req.Password, clientInfo(r))
 	default:
 		writeError(w, http.StatusBadRequest, "missing_fields", "email or username is required")
 		return
@@ -135,11 +162,147 @@ func (s *Server) handleReset(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "missing_fields", "email is required")
 		return
 	}
-	if err := s.auth.ResetPassword(r.Context(), req.Email); err != nil {
-		writeError(w, http.StatusInternalServerError, "server_error", "unable to reset password")
-		return
+	// The answer never depends on whether the account exists or the mail
+	// went out, and RequestPasswordReset only queues the work, so neither
+	// the body nor the timing can be used to probe for emails.
+	if err := s.auth.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		slog.Error("httpapi: password reset request", "err", err)
 	}
 	writeData(w, http.StatusOK, map[string]string{
 		"status": "reset_requested",
 	})
 }
+
+func (s *Server) handleResetComplete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	var req resetCompleteRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json payload")
+		return
+	}
+	req.Token = strings.TrimSpace(req.Token)
+	if req.Token == "" || req.Password == "" {
+		writeError(w, http.StatusBadRequest, "missing_fields", "token and password are required")
+		return
+	}
+	if err := s.auth.CompletePasswordReset(r.Context(), req.Token, req.Password); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidResetToken):
+			writeError(w, http.StatusBadRequest, "invalid_reset_token", "invalid or expired reset token")
+		case errors.Is(err, auth.ErrInvalidPassword):
+			writeError(w, http.StatusBadRequest, "invalid_password", "invalid password")
+		default:
+			writeError(w, http.StatusInternalServerError, "server_error", "unable to reset password")
+		}
+		return
+	}
+	writeData(w, http.StatusOK, map[string]string{
+		"status": "password_reset",
+	})
+}
+
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	token := bearerToken(r)
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "missing_token", "authorization token required")
+		return
+	}
+	if err := s.auth.RevokeToken(r.Context(), token); err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	writeData(w, http.StatusOK, map[string]string{
+		"status": "logged_out",
+	})
+}
+
+func (s *Server) handleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	token := bearerToken(r)
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "missing_token", "authorization token required")
+		return
+	}
+	session, err := s.auth.ValidateToken(r.Context(), token)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	revoked, err := s.auth.RevokeAllSessions(r.Context(), session.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to revoke sessions")
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{
+		"status":  "logged_out",
+		"revoked": revoked,
+	})
+}
+
+func (s *Server) handleSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	token := bearerToken(r)
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "missing_token", "authorization token required")
+		return
+	}
+	session, err := s.auth.ValidateToken(r.Context(), token)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	sessions, err := s.auth.ListSessions(r.Context(), session.UserID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to list sessions")
+		return
+	}
+	resp := sessionsResponse{Sessions: make([]sessionInfo, 0, len(sessions))}
+	for _, sess := range sessions {
+		resp.Sessions = append(resp.Sessions, sessionInfo{
+			ID:         sessionID(sess.TokenHash),
+			Current:    bytes.Equal(sess.TokenHash, session.TokenHash),
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+			ExpiresAt:  sess.ExpiresAt,
+			IP:         sess.IP,
+			UserAgent:  sess.UserAgent,
+		})
+	}
+	writeData(w, http.StatusOK, resp)
+}
+
+// sessionID is a short, stable handle for a session derived from its token
+// hash; it cannot be turned back into the token.
+func sessionID(tokenHash []byte) string {
+	if len(tokenHash) > 8 {
+		tokenHash = tokenHash[:8]
+	}
+	return hex.EncodeToString(tokenHash)
+}
+
+// clientInfo describes the caller for the session it is about to receive.
+// The IP is the TCP peer; proxies in front of the API are not trusted.
+func clientInfo(r *http.Request) auth.ClientInfo {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	ua := r.UserAgent()
+	if len(ua) > maxUserAgentBytes {
+		ua = strings.ToValidUTF8(ua[:maxUserAgentBytes], "")
+	}
+	return auth.ClientInfo{IP: ip, UserAgent: ua}
+}
@@ -0,0 +1,136 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"example.com/mvp-repo/internal/auth"
+)
+
+func TestSessionEndpointsRequireBearer(t *testing.T) {
+	api := newTestAPI(t)
+	for _, route := range []struct{ method, path string }{
+		{http.MethodPost, "/api/auth/logout"},
+		{http.MethodPost, "/api/auth/logout-all"},
+		{http.MethodGet, "/api/auth/sessions"},
+	} {
+		status, resp := api.do(t, route.method, route.path, "", nil)
+		if status != http.StatusUnauthorized || resp.Error == nil || resp.Error.Code != "missing_token" {
+			t.Errorf("%s %s without token: %d %+v, want 401 missing_token", route.method, route.path, status, resp.Error)
+		}
+		status, resp = api.do(t, route.method, route.path, "not-a-session", nil)
+		if status != http.StatusUnauthorized || resp.Error == nil || resp.Error.Code != "invalid_token" {
+			t.Errorf("%s %s with unknown token: %d %+v, want 401 invalid_token", route.method, route.path, status, resp.Error)
+		}
+	}
+}
+
+func TestLogoutRevokesOnlyTheBearer(t *testing.T) {
+	api := newTestAPI(t)
+	ada := api.register(t, "ada")
+	adaPhone := api.login(t, "ada")
+	bob := api.register(t, "bob")
+	var revoked []auth.Revocation
+	api.auth.OnRevoke(func(rv auth.Revocation) { revoked = append(revoked, rv) })
+
+	// Logout has no way to name a token other than the bearer; a body is
+	// ignored, so bob's session cannot be ended with ada's credentials.
+	status, resp := api.do(t, http.MethodPost, "/api/auth/logout", ada, map[string]string{"token": bob})
+	if status != http.StatusOK {
+		t.Fatalf("logout: %d %+v", status, resp.Error)
+	}
+	api.wantValid(t, bob, true)
+	api.wantValid(t, adaPhone, true)
+	api.wantValid(t, ada, false)
+	if len(revoked) != 1 || revoked[0] != (auth.Revocation{Token: ada}) {
+		t.Fatalf("revocations = %+v, want ada's token only", revoked)
+	}
+
+	status, resp = api.do(t, http.MethodPost, "/api/auth/logout", ada, nil)
+	if status != http.StatusUnauthorized || resp.Error == nil || resp.Error.Code != "invalid_token" {
+		t.Fatalf("second logout: %d %+v, want 401 invalid_token", status, resp.Error)
+	}
+}
+
+func TestLogoutAllReportsRevokedCount(t *testing.T) {
+	api := newTestAPI(t)
+	ada := api.register(t, "ada")
+	api.login(t, "ada")
+	api.login(t, "ada")
+	bob := api.register(t, "bob")
+
+	status, resp := api.do(t, http.MethodPost, "/api/auth/logout-all", ada, nil)
+	if status != http.StatusOK {
+		t.Fatalf("logout-all: %d %+v", status, resp.Error)
+	}
+	var body struct {
+		Status  string `json:"status"`
+		Revoked int64  `json:"revoked"`
+	}
+	if err := json.Unmarshal(resp.Data, &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != "logged_out" || body.Revoked != 3 {
+		t.Fatalf("logout-all = %+v, want logged_out with 3 revoked", body)
+	}
+	api.wantValid(t, ada, false)
+	api.wantValid(t, bob, true)
+}
+
+func TestSessionsMarksCurrent(t *testing.T) {
+	api := newTestAPI(t)
+	ada := api.register(t, "ada")
+	adaPhone := api.login(t, "ada")
+	api.register(t, "bob")
+
+	status, resp := api.do(t, http.MethodGet, "/api/auth/sessions", adaPhone, nil)
+	if status != http.StatusOK {
+		t.Fatalf("sessions: %d %+v", status, resp.Error)
+	}
+	var body sessionsResponse
+	if err := json.Unmarshal(resp.Data, &body); err != nil {
+		t.Fatal(err)
+	}
+	if len(body.Sessions) != 2 {
+		t.Fatalf("sessions = %+v, want ada's two", body.Sessions)
+	}
+	current := 0
+	for _, s := range body.Sessions {
+		if s.ID == "" || s.ID == ada || s.ID == adaPhone {
+			t.Fatalf("session id %q exposes or lacks a handle", s.ID)
+		}
+		if s.Current {
+			current++
+			if s.ID != sessionID(auth.HashToken(adaPhone)) {
+				t.Fatalf("current session is %s, want the bearer's", s.ID)
+			}
+		}
+	}
+	if current != 1 {
+		t.Fatalf("%d sessions marked current, want 1", current)
+	}
+	if raw := string(resp.Data); containsAny(raw, ada, adaPhone) {
+		t.Fatalf("sessions response leaks a token: %s", raw)
+	}
+}
+
+// wantValid checks whether token still authenticates.
+func (a *testAPI) wantValid(t *testing.T, token string, valid bool) {
+	t.Helper()
+	_, err := a.auth.ValidateToken(context.Background(), token)
+	if (err == nil) != valid {
+		t.Fatalf("token valid = %v (err %v), want %v", err == nil, err, valid)
+	}
+}
+
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if sub != "" && strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+// File: internal/httpapi/chat_handlers.go
+package httpapi
+
+import (
+	"context"
+	"net/http"
+)
+
+type blockRequest struct {
+	UserID int64 `json:"user_id"`
+}
+
+// handleChatBlock stops chat from user_id reaching the caller. Blocking an
+// already blocked user succeeds.
+func (s *Server) handleChatBlock(w http.ResponseWriter, r *http.Request) {
+	s.handleBlockList(w, r, s.chat.Block, "blocked")
+}
+
+// handleChatUnblock lifts the caller's block on user_id. Unblocking a user
+// who is not blocked succeeds.
+func (s *Server) handleChatUnblock(w http.ResponseWriter, r *http.Request) {
+	s.handleBlockList(w, r, s.chat.Unblock, "unblocked")
+}
+
+func (s *Server) handleBlockList(w http.ResponseWriter, r *http.Request, apply func(ctx context.Context, blockerID uint64, blockedID uint64) error, status string) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		return
+	}
+	token := bearerToken(r)
+	if token == "" {
+		writeError(w, http.StatusUnauthorized, "missing_token", "authorization token required")
+		return
+	}
+	session, err := s.auth.ValidateToken(r.Context(), token)
+	if err != nil {
+		writeAuthError(w, err)
+		return
+	}
+	var req blockRequest
+	if err := s.decodeJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json payload")
+		return
+	}
+	if req.UserID <= 0 {
+		writeError(w, http.StatusBadRequest, "missing_fields", "user_id is required")
+		return
+	}
+	if req.UserID == session.UserID {
+		writeError(w, http.StatusBadRequest, "invalid_user", "cannot block yourself")
+		return
+	}
+	if err := apply(r.Context(), uint64(session.UserID), uint64(req.UserID)); err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to update block list")
+		return
+	}
+	writeData(w, http.StatusOK, map[string]any{
+		"status":  status,
+		"user_id": req.UserID,
+	})
+}
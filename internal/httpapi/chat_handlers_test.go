@@ -0,0 +1,142 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// chatInbox counts the CHAT_EVENTs delivered to one connection.
+type chatInbox struct {
+	mu     sync.Mutex
+	events int
+}
+
+func (c *chatInbox) Send(msgType protocol.MsgType, payload []byte) error {
+	if msgType == protocol.MSG_CHAT_EVENT {
+		c.mu.Lock()
+		c.events++
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+func (c *chatInbox) Close(reason string) error { return nil }
+
+func (c *chatInbox) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.events
+}
+
+// userID returns the account behind token.
+func (a *testAPI) userID(t *testing.T, token string) int64 {
+	t.Helper()
+	session, err := a.auth.ValidateToken(context.Background(), token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session.UserID
+}
+
+// blocked lists the users blocker has blocked, as stored.
+func (a *testAPI) blocked(t *testing.T, blocker int64) []int64 {
+	t.Helper()
+	blocks, err := a.blocks.ListByBlocker(context.Background(), blocker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int64, 0, len(blocks))
+	for _, b := range blocks {
+		ids = append(ids, b.BlockedUserID)
+	}
+	return ids
+}
+
+func TestChatBlockEndpointsRequireBearer(t *testing.T) {
+	api := newTestAPI(t)
+	for _, path := range []string{"/api/chat/block", "/api/chat/unblock"} {
+		status, resp := api.do(t, http.MethodPost, path, "", blockRequest{UserID: 2})
+		if status != http.StatusUnauthorized || resp.Error == nil || resp.Error.Code != "missing_token" {
+			t.Errorf("%s without token: %d %+v, want 401 missing_token", path, status, resp.Error)
+		}
+		status, resp = api.do(t, http.MethodPost, path, "not-a-session", blockRequest{UserID: 2})
+		if status != http.StatusUnauthorized || resp.Error == nil || resp.Error.Code != "invalid_token" {
+			t.Errorf("%s with unknown token: %d %+v, want 401 invalid_token", path, status, resp.Error)
+		}
+	}
+}
+
+func TestChatBlockRejectsBadTargets(t *testing.T) {
+	api := newTestAPI(t)
+	ada := api.register(t, "ada")
+	for _, tc := range []struct {
+		name string
+		body any
+		code string
+	}{
+		{"missing user", map[string]any{}, "missing_fields"},
+		{"negative user", blockRequest{UserID: -1}, "missing_fields"},
+		{"self", blockRequest{UserID: api.userID(t, ada)}, "invalid_user"},
+		{"unknown field", `{"user_id":2,"reason":"x"}`, "invalid_json"},
+	} {
+		status, resp := api.do(t, http.MethodPost, "/api/chat/block", ada, tc.body)
+		if status != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != tc.code {
+			t.Errorf("%s: %d %+v, want 400 %s", tc.name, status, resp.Error, tc.code)
+		}
+	}
+	if got := api.blocked(t, api.userID(t, ada)); len(got) != 0 {
+		t.Fatalf("rejected requests stored blocks %v", got)
+	}
+}
+
+func TestChatBlockStopsDeliveryUntilUnblocked(t *testing.T) {
+	api := newTestAPI(t)
+	ctx := context.Background()
+	ada := api.register(t, "ada")
+	bob := api.register(t, "bob")
+	adaID, bobID := api.userID(t, ada), api.userID(t, bob)
+	inbox := &chatInbox{}
+	if err := api.chat.Join(ctx, uint64(adaID), inbox); err != nil {
+		t.Fatal(err)
+	}
+
+	// Blocking twice is not an error and stores one row.
+	for range 2 {
+		status, resp := api.do(t, http.MethodPost, "/api/chat/block", ada, blockRequest{UserID: bobID})
+		if status != http.StatusOK {
+			t.Fatalf("block: %d %+v", status, resp.Error)
+		}
+	}
+	if got := api.blocked(t, adaID); len(got) != 1 || got[0] != bobID {
+		t.Fatalf("stored blocks = %v, want [%d]", got, bobID)
+	}
+	if got := api.blocked(t, bobID); len(got) != 0 {
+		t.Fatalf("block was stored for the blocked user too: %v", got)
+	}
+	if err := api.chat.Send(uint64(bobID), "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if inbox.count() != 0 {
+		t.Fatalf("blocked sender reached the online blocker")
+	}
+
+	for range 2 {
+		status, resp := api.do(t, http.MethodPost, "/api/chat/unblock", ada, blockRequest{UserID: bobID})
+		if status != http.StatusOK {
+			t.Fatalf("unblock: %d %+v", status, resp.Error)
+		}
+	}
+	if got := api.blocked(t, adaID); len(got) != 0 {
+		t.Fatalf("stored blocks after unblock = %v", got)
+	}
+	if err := api.chat.Send(uint64(bobID), "hello again"); err != nil {
+		t.Fatal(err)
+	}
+	if inbox.count() != 1 {
+		t.Fatalf("events after unblock = %d, want 1", inbox.count())
+	}
+}
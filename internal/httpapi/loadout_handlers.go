@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"example.com/mvp-repo/internal/auth"
+	"example.com/mvp-repo/internal/loadout"
 	"example.com/mvp-repo/internal/persist"
 )
 
@@ -28,6 +29,13 @@ type LoadoutInput struct {
 	Item4         int64 `json:"item_4"`
 }
 
+// loadoutRequest is the POST body. ElementID shadows LoadoutInput's so an
+// omitted element is detected instead of decoding to 0, which is Water.
+type loadoutRequest struct {
+	LoadoutInput
+	ElementID *int64 `json:"element_id"`
+}
+
 type loadoutResponse struct {
 	UserID        int64 `json:"user_id"`
 	ElementID     int64 `json:"element_id"`
@@ -93,12 +101,22 @@ func (s *Server) handleLoadoutPost(w http.ResponseWriter, r *http.Request) {
 		writeAuthError(w, err)
 		return
 	}
-	var req LoadoutInput
+	var req loadoutRequest
 	if err := s.decodeJSON(w, r, &req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid_json", "invalid json payload")
 		return
 	}
-	loadout, err := s.loadouts.Update(r.Context(), session.UserID, req)
+	if req.ElementID == nil {
+		writeError(w, http.StatusBadRequest, "missing_fields", "element_id is required")
+		return
+	}
+	req.LoadoutInput.ElementID = *req.ElementID
+	normalized, err := s.validator.Normalize(req.LoadoutInput.toLoadout())
+	if err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	loadout, err := s.loadouts.Update(r.Context(), session.UserID, fromLoadout(normalized))
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "server_error", "unable to update loadout")
 		return
@@ -106,6 +124,26 @@ func (s *Server) handleLoadoutPost(w http.ResponseWriter, r *http.Request) {
 	writeData(w, http.StatusOK, toLoadoutResponse(loadout))
 }
 
+func writeValidationError(w http.ResponseWriter, err error) {
+	var verr *loadout.ValidationError
+	if !errors.As(err, &verr) {
+		writeError(w, http.StatusInternalServerError, "server_error", "unable to validate loadout")
+		return
+	}
+	fields := make([]apiFieldError, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		fields = append(fields, apiFieldError{Field: f.Field, Code: f.Code, Message: f.Message})
+	}
+	writeJSON(w, http.StatusUnprocessableEntity, apiResponse{
+		OK: false,
+		Error: &apiError{
+			Code:    "invalid_loadout",
+			Message: "loadout violates gameplay rules",
+			Fields:  fields,
+		},
+	})
+}
+
 func writeAuthError(w http.ResponseWriter, err error) {
 	switch {
 	case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrTokenExpired):
@@ -127,6 +165,37 @@ func bearerToken(r *http.Request) string {
 	return strings.TrimSpace(header[len(prefix):])
 }
 
+func (in LoadoutInput) toLoadout() loadout.Loadout {
+	return loadout.Loadout{
+		ElementID:     in.ElementID,
+		ArmyAbilities: [loadout.ArmyAbilityColumns]int64{in.ArmyAbility1, in.ArmyAbility2, in.ArmyAbility3, in.ArmyAbility4},
+		PieceAbilities: [len(loadout.PieceTypes)]int64{
+			in.AbilityPawn, in.AbilityKnight, in.AbilityBishop, in.AbilityRook, in.AbilityQueen, in.AbilityKing,
+		},
+		Items: [loadout.ItemColumns]int64{in.Item1, in.Item2, in.Item3, in.Item4},
+	}
+}
+
+func fromLoadout(l loadout.Loadout) LoadoutInput {
+	return LoadoutInput{
+		ElementID:     l.ElementID,
+		ArmyAbility1:  l.ArmyAbilities[0],
+		ArmyAbility2:  l.ArmyAbilities[1],
+		ArmyAbility3:  l.ArmyAbilities[2],
+		ArmyAbility4:  l.ArmyAbilities[3],
+		AbilityPawn:   l.PieceAbilities[0],
+		AbilityKnight: l.PieceAbilities[1],
+		AbilityBishop: l.PieceAbilities[2],
+		AbilityRook:   l.PieceAbilities[3],
+		AbilityQueen:  l.PieceAbilities[4],
+		AbilityKing:   l.PieceAbilities[5],
+		Item1:         l.Items[0],
+		Item2:         l.Items[1],
+		Item3:         l.Items[2],
+		Item4:         l.Items[3],
+	}
+}
+
 func toLoadoutResponse(loadout persist.Loadout) loadoutResponse {
 	return loadoutResponse{
 		UserID:        loadout.UserID,
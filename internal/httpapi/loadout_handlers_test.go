@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestLoadoutPostRequiresElement(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.register(t, "ada")
+
+	status, resp := api.do(t, http.MethodPost, "/api/loadout", token, `{"ability_pawn": 1}`)
+	if status != http.StatusBadRequest || resp.Error == nil || resp.Error.Code != "missing_fields" {
+		t.Fatalf("omitted element_id: %d %+v, want 400 missing_fields", status, resp.Error)
+	}
+	if status, _ := api.do(t, http.MethodGet, "/api/loadout", token, nil); status != http.StatusNotFound {
+		t.Fatalf("rejected POST stored a loadout: GET = %d", status)
+	}
+
+	// Water is element 0 and must still be accepted when sent explicitly.
+	status, resp = api.do(t, http.MethodPost, "/api/loadout", token, `{"element_id": 0, "ability_pawn": 1}`)
+	if status != http.StatusOK {
+		t.Fatalf("explicit water: %d %+v", status, resp.Error)
+	}
+}
+
+func TestLoadoutPostReportsFieldErrors(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.register(t, "ada")
+
+	status, resp := api.do(t, http.MethodPost, "/api/loadout", token, `{"element_id": 1, "army_ability_1": 1, "ability_knight": 5}`)
+	if status != http.StatusUnprocessableEntity || resp.Error == nil || resp.Error.Code != "invalid_loadout" {
+		t.Fatalf("status %d %+v, want 422 invalid_loadout", status, resp.Error)
+	}
+	want := map[string]string{"army_ability_1": "wrong_ability_scope", "ability_knight": "piece_type_slots_not_allowed"}
+	if len(resp.Error.Fields) != len(want) {
+		t.Fatalf("fields = %+v, want %v", resp.Error.Fields, want)
+	}
+	for _, f := range resp.Error.Fields {
+		if want[f.Field] != f.Code {
+			t.Errorf("field %s code %s, want %s", f.Field, f.Code, want[f.Field])
+		}
+	}
+}
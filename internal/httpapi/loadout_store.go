@@ -0,0 +1,56 @@
+// File: internal/httpapi/loadout_store.go
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"example.com/mvp-repo/internal/persist"
+)
+
+var ErrLoadoutsRepoRequired = errors.New("httpapi: loadouts repo required")
+
+// RepoLoadouts implements LoadoutService on persist.LoadoutsRepo. Input is
+// stored as given; callers validate it first (handleLoadoutPost does).
+type RepoLoadouts struct {
+	repo *persist.LoadoutsRepo
+	now  func() time.Time
+}
+
+func NewRepoLoadouts(repo *persist.LoadoutsRepo) (*RepoLoadouts, error) {
+	if repo == nil {
+		return nil, ErrLoadoutsRepoRequired
+	}
+	return &RepoLoadouts{repo: repo, now: time.Now}, nil
+}
+
+func (l *RepoLoadouts) Get(ctx context.Context, userID int64) (persist.Loadout, error) {
+	return l.repo.Get(ctx, userID)
+}
+
+func (l *RepoLoadouts) Update(ctx context.Context, userID int64, input LoadoutInput) (persist.Loadout, error) {
+	loadout := persist.Loadout{
+		UserID:        userID,
+		ElementID:     input.ElementID,
+		ArmyAbility1:  input.ArmyAbility1,
+		ArmyAbility2:  input.ArmyAbility2,
+		ArmyAbility3:  input.ArmyAbility3,
+		ArmyAbility4:  input.ArmyAbility4,
+		AbilityPawn:   input.AbilityPawn,
+		AbilityKnight: input.AbilityKnight,
+		AbilityBishop: input.AbilityBishop,
+		AbilityRook:   input.AbilityRook,
+		AbilityQueen:  input.AbilityQueen,
+		AbilityKing:   input.AbilityKing,
+		Item1:         input.Item1,
+		Item2:         input.Item2,
+		Item3:         input.Item3,
+		Item4:         input.Item4,
+		UpdatedAt:     l.now().Unix(),
+	}
+	if err := l.repo.Upsert(ctx, loadout); err != nil {
+		return persist.Loadout{}, err
+	}
+	return loadout, nil
+}
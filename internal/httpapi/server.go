@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"time"
 
+	"example.com/mvp-repo/internal/auth"
+	"example.com/mvp-repo/internal/loadout"
 	"example.com/mvp-repo/internal/persist"
 )
 
@@ -17,6 +19,8 @@ const defaultMaxBodyBytes int64 = 1 << 20
 var (
 	ErrAuthServiceRequired    = errors.New("httpapi: auth service required")
 	ErrLoadoutServiceRequired = errors.New("httpapi: loadout service required")
+	ErrValidatorRequired      = errors.New("httpapi: loadout validator required")
+	ErrChatServiceRequired    = errors.New("httpapi: chat service required")
 	ErrListenAddrRequired     = errors.New("httpapi: listen addr required")
 )
 
@@ -31,17 +35,23 @@ type Config struct {
 type Server struct {
 	auth         AuthService
 	loadouts     LoadoutService
+	validator    LoadoutValidator
+	chat         ChatModeration
 	maxBodyBytes int64
 	mux          *http.ServeMux
 	server       *http.Server
 }
 
 type AuthService interface {
-	Register(ctx context.Context, email string, username string, password string) (persist.Session, error)
-	LoginByEmail(ctx context.Context, email string, password string) (persist.Session, error)
-	LoginByUsername(ctx context.Context, username string, password string) (persist.Session, error)
-	ResetPassword(ctx context.Context, email string) error
+	Register(ctx context.Context, email string, username string, password string, client auth.ClientInfo) (auth.IssuedSession, error)
+	LoginByEmail(ctx context.Context, email string, password string, client auth.ClientInfo) (auth.IssuedSession, error)
+	LoginByUsername(ctx context.Context, username string, password string, client auth.ClientInfo) (auth.IssuedSession, error)
+	RequestPasswordReset(ctx context.Context, email string) error
+	CompletePasswordReset(ctx context.Context, token string, newPassword string) error
 	ValidateToken(ctx context.Context, token string) (persist.Session, error)
+	RevokeToken(ctx context.Context, token string) error
+	RevokeAllSessions(ctx context.Context, userID int64) (int64, error)
+	ListSessions(ctx context.Context, userID int64) ([]persist.Session, error)
 }
 
 type LoadoutService interface {
@@ -49,13 +59,32 @@ type LoadoutService interface {
 	Update(ctx context.Context, userID int64, input LoadoutInput) (persist.Loadout, error)
 }
 
-func NewServer(cfg Config, auth AuthService, loadouts LoadoutService) (*Server, error) {
+// LoadoutValidator checks a submitted loadout against gameplay rules and
+// returns its normalized form; *loadout.Validator implements it.
+type LoadoutValidator interface {
+	Normalize(in loadout.Loadout) (loadout.Loadout, error)
+}
+
+// ChatModeration manages the caller's own chat block list; *chat.Service
+// implements it. Player IDs are account user IDs.
+type ChatModeration interface {
+	Block(ctx context.Context, blockerID uint64, blockedID uint64) error
+	Unblock(ctx context.Context, blockerID uint64, blockedID uint64) error
+}
+
+func NewServer(cfg Config, auth AuthService, loadouts LoadoutService, validator LoadoutValidator, chat ChatModeration) (*Server, error) {
 	if auth == nil {
 		return nil, ErrAuthServiceRequired
 	}
 	if loadouts == nil {
 		return nil, ErrLoadoutServiceRequired
 	}
+	if validator == nil {
+		return nil, ErrValidatorRequired
+	}
+	if chat == nil {
+		return nil, ErrChatServiceRequired
+	}
 	if cfg.ReadTimeout == 0 {
 		cfg.ReadTimeout = 5 * time.Second
 	}
@@ -75,13 +104,21 @@ func NewServer(cfg Config, auth AuthService, loadouts LoadoutService) (*Server,
 	s := &Server{
 		auth:         auth,
 		loadouts:     loadouts,
+		validator:    validator,
+		chat:         chat,
 		maxBodyBytes: cfg.MaxBodyBytes,
 		mux:          mux,
 	}
 	mux.HandleFunc("/api/auth/register", s.handleRegister)
 	mux.HandleFunc("/api/auth/login", s.handleLogin)
 	mux.HandleFunc("/api/auth/reset", s.handleReset)
+	mux.HandleFunc("/api/auth/reset/complete", s.handleResetComplete)
+	mux.HandleFunc("/api/auth/logout", s.handleLogout)
+	mux.HandleFunc("/api/auth/logout-all", s.handleLogoutAll)
+	mux.HandleFunc("/api/auth/sessions", s.handleSessions)
 	mux.HandleFunc("/api/loadout", s.handleLoadout)
+	mux.HandleFunc("/api/chat/block", s.handleChatBlock)
+	mux.HandleFunc("/api/chat/unblock", s.handleChatUnblock)
 	server := &http.Server{
 		Handler:           mux,
 		ReadTimeout:       cfg.ReadTimeout,
@@ -110,6 +147,13 @@ func (s *Server) Handler() http.Handler {
 }
 
 type apiError struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Fields  []apiFieldError `json:"fields,omitempty"`
+}
+
+type apiFieldError struct {
+	Field   string `json:"field"`
 	Code    string `json:"code"`
 	Message string `json:"message"`
 }
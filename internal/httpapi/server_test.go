@@ -0,0 +1,135 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"example.com/mvp-repo/internal/auth"
+	"example.com/mvp-repo/internal/chat"
+	"example.com/mvp-repo/internal/config"
+	"example.com/mvp-repo/internal/loadout"
+	"example.com/mvp-repo/internal/persist"
+	"example.com/mvp-repo/internal/persist/persisttest"
+)
+
+// testMailer keeps every mail so tests can read reset tokens.
+type testMailer struct {
+	mu   sync.Mutex
+	sent []auth.Mail
+}
+
+func (m *testMailer) Send(_ context.Context, mail auth.Mail) error {
+	m.mu.Lock()
+	m.sent = append(m.sent, mail)
+	m.mu.Unlock()
+	return nil
+}
+
+type testAPI struct {
+	handler http.Handler
+	auth    *auth.Service
+	chat    *chat.Service
+	blocks  *persist.BlocksRepo
+	mailer  *testMailer
+}
+
+// newTestAPI serves the API on a fresh SQLite database with the shipped
+// gameplay rules.
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+	db := persisttest.Open(t)
+	dialect := persist.DialectSQLite
+	mailer := &testMailer{}
+	authSvc, err := auth.NewService(persist.NewAccountsRepo(db, dialect), persist.NewSessionsRepo(db, dialect), persist.NewPasswordResetsRepo(db, dialect), auth.Config{
+		TokenTTL: time.Hour,
+		ResetTTL: time.Hour,
+		Mailer:   mailer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loadouts, err := NewRepoLoadouts(persist.NewLoadoutsRepo(db, dialect))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gameplay, err := config.LoadGameplayConfig("../../config/gameplay.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := loadout.New(gameplay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := persist.NewBlocksRepo(db, dialect)
+	chatSvc := chat.NewService(chat.Config{Mutes: persist.NewMutesRepo(db, dialect), Blocks: blocks})
+	api, err := NewServer(Config{}, authSvc, loadouts, validator, chatSvc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testAPI{handler: api.Handler(), auth: authSvc, chat: chatSvc, blocks: blocks, mailer: mailer}
+}
+
+type testResponse struct {
+	OK    bool            `json:"ok"`
+	Data  json.RawMessage `json:"data"`
+	Error *apiError       `json:"error"`
+}
+
+// do sends body (JSON-encoded unless nil) with token as the bearer and
+// decodes the envelope.
+func (a *testAPI) do(t *testing.T, method, path, token string, body any) (int, testResponse) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if raw, ok := body.(string); ok {
+			buf.WriteString(raw)
+		} else if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	a.handler.ServeHTTP(rec, req)
+	var resp testResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+// register creates an account and returns its session token.
+func (a *testAPI) register(t *testing.T, name string) string {
+	t.Helper()
+	status, resp := a.do(t, http.MethodPost, "/api/auth/register", "", registerRequest{Email: name + "@example.com", Username: name, Password: "password-" + name})
+	if status != http.StatusCreated {
+		t.Fatalf("register %s: %d %+v", name, status, resp.Error)
+	}
+	var session sessionResponse
+	if err := json.Unmarshal(resp.Data, &session); err != nil {
+		t.Fatal(err)
+	}
+	return session.Token
+}
+
+// login opens another session for an account made by register.
+func (a *testAPI) login(t *testing.T, name string) string {
+	t.Helper()
+	status, resp := a.do(t, http.MethodPost, "/api/auth/login", "", loginRequest{Username: name, Password: "password-" + name})
+	if status != http.StatusOK {
+		t.Fatalf("login %s: %d %+v", name, status, resp.Error)
+	}
+	var session sessionResponse
+	if err := json.Unmarshal(resp.Data, &session); err != nil {
+		t.Fatal(err)
+	}
+	return session.Token
+}
@@ -0,0 +1,71 @@
+// File: internal/loadout/errors.go
+package loadout
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidLoadout = errors.New("loadout: invalid loadout")
+	ErrInvalidRules   = errors.New("loadout: invalid gameplay rules")
+)
+
+// Field error codes, stable for API clients.
+const (
+	CodeUnknownElement      = "unknown_element"
+	CodeUnknownAbility      = "unknown_ability"
+	CodeUnknownItem         = "unknown_item"
+	CodeDuplicateAbility    = "duplicate_ability"
+	CodeDuplicateItem       = "duplicate_item"
+	CodeIncompatibleItem    = "incompatible_item"
+	CodeItemSlotsExceeded   = "item_slots_exceeded"
+	CodeArmySlotsExceeded   = "army_slots_exceeded"
+	CodePieceTypeNotAllowed = "piece_type_slots_not_allowed"
+	CodeWrongAbilityScope   = "wrong_ability_scope"
+)
+
+// FieldError describes one rejected field. Field uses the persisted column
+// names (e.g. "army_ability_2", "ability_knight", "item_3").
+type FieldError struct {
+	Field   string
+	Code    string
+	Message string
+}
+
+// ValidationError collects every field error found in a loadout. It wraps
+// ErrInvalidLoadout.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(ErrInvalidLoadout.Error())
+	for i, f := range e.Fields {
+		if i == 0 {
+			b.WriteString(": ")
+		} else {
+			b.WriteString("; ")
+		}
+		b.WriteString(f.Field)
+		b.WriteString(" ")
+		b.WriteString(f.Code)
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidLoadout
+}
+
+func (e *ValidationError) add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+func (e *ValidationError) err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,35 @@
+// File: internal/loadout/normalize.go
+package loadout
+
+import "slices"
+
+// compact sorts the filled slots ascending and moves empty slots to the end,
+// so equal loadouts have one stored form. Piece-type slots are positional and
+// are not compacted.
+func compact(ids []int64) {
+	slices.SortFunc(ids, func(a, b int64) int {
+		switch {
+		case a == b:
+			return 0
+		case a == 0:
+			return 1
+		case b == 0:
+			return -1
+		case a < b:
+			return -1
+		default:
+			return 1
+		}
+	})
+}
+
+// filled returns the non-empty IDs of ids in order.
+func filled(ids []int64) []int64 {
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,98 @@
+// File: internal/loadout/slots.go
+package loadout
+
+import (
+	"fmt"
+	"slices"
+
+	"example.com/mvp-repo/internal/config"
+)
+
+type itemRule struct {
+	slotCost       int
+	armySlotsBonus int
+	incompatible   []int64
+}
+
+// Validator checks loadouts against one gameplay config. It is immutable and
+// safe for concurrent use.
+type Validator struct {
+	elements map[int64]struct{}
+	// abilities maps each ability to its scope (ScopePieceType or
+	// ScopeArmyWide).
+	abilities      map[int64]string
+	items          map[int64]itemRule
+	itemSlotsTotal int
+	baseArmySlots  int
+	maxArmySlots   int
+	// pieceElement and pieceItem unlock piece-type assignments; -1 when the
+	// rules name none.
+	pieceElement int64
+	pieceItem    int64
+}
+
+// New builds a Validator from a config that passed config.Validate.
+func New(cfg config.GameplayConfig) (*Validator, error) {
+	rules := cfg.LoadoutRules
+	v := &Validator{
+		elements:       make(map[int64]struct{}, len(cfg.Elements)),
+		abilities:      make(map[int64]string, len(cfg.Abilities)),
+		items:          make(map[int64]itemRule, len(cfg.Items)),
+		itemSlotsTotal: rules.ItemSlotsTotal,
+		baseArmySlots:  rules.BaseArmyAbilitySlots,
+		maxArmySlots:   rules.MaxArmyAbilitySlotsTotal,
+		pieceElement:   -1,
+		pieceItem:      -1,
+	}
+	if v.maxArmySlots > ArmyAbilityColumns {
+		return nil, fmt.Errorf("%w: max army slots %d exceed storage", ErrInvalidRules, v.maxArmySlots)
+	}
+	if !slices.Equal(rules.PieceTypeSlots, PieceTypes[:]) {
+		return nil, fmt.Errorf("%w: piece_type_slots must be %v", ErrInvalidRules, PieceTypes)
+	}
+	cond := rules.ArmyAbilityPlacement.PieceTypeSlotsAllowArmyAbilitiesIf
+	if cond.ElementIDIs != nil {
+		v.pieceElement = int64(*cond.ElementIDIs)
+	}
+	if cond.OrItemIDIs != nil {
+		v.pieceItem = int64(*cond.OrItemIDIs)
+	}
+	for _, e := range cfg.Elements {
+		v.elements[int64(e.ID)] = struct{}{}
+	}
+	for _, a := range cfg.Abilities {
+		v.abilities[int64(a.ID)] = a.Scope
+	}
+	for _, it := range cfg.Items {
+		rule := itemRule{slotCost: it.SlotCost}
+		if effects, ok := it.Effects.(*config.ArmySlotsEffects); ok {
+			rule.armySlotsBonus = effects.ArmyAbilitySlotsBonus
+		}
+		for _, id := range it.IncompatibleItemIDs {
+			rule.incompatible = append(rule.incompatible, int64(id))
+		}
+		v.items[int64(it.ID)] = rule
+	}
+	return v, nil
+}
+
+// ArmySlots returns the army assignment slots granted by items: the base
+// count plus every item bonus, capped at the maximum. Unknown IDs and empty
+// slots add nothing; exclusivity is checked by Normalize.
+func (v *Validator) ArmySlots(items []int64) int {
+	slots := v.baseArmySlots
+	for _, id := range items {
+		slots += v.items[id].armySlotsBonus
+	}
+	return min(slots, v.maxArmySlots)
+}
+
+// PieceTypeSlotsAllowed reports whether army-wide abilities may be assigned to
+// piece-type slots under loadout_rules.army_ability_placement: the element
+// (Lightning) or an equipped item (Multitasker's Schedule) unlocks them.
+func (v *Validator) PieceTypeSlotsAllowed(elementID int64, items []int64) bool {
+	if v.pieceElement >= 0 && elementID == v.pieceElement {
+		return true
+	}
+	return v.pieceItem > 0 && slices.Contains(items, v.pieceItem)
+}
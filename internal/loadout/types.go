@@ -0,0 +1,29 @@
+// File: internal/loadout/types.go
+package loadout
+
+// Storage shape limits; these match the army_loadouts columns.
+const (
+	ArmyAbilityColumns = 4
+	ItemColumns        = 4
+)
+
+// Ability scopes from gameplay config. A piece_type ability belongs in a
+// piece-type slot; an army_wide ability belongs in an army slot and may also
+// take a piece-type slot when PieceTypeSlotsAllowed.
+const (
+	ScopePieceType = "piece_type"
+	ScopeArmyWide  = "army_wide"
+)
+
+// PieceTypes lists the piece-type assignment slots in column order. Gameplay
+// config must define each of these keys.
+var PieceTypes = [...]string{"pawn", "knight", "bishop", "rook", "queen", "king"}
+
+// Loadout is one army's element, ability assignments and items. Zero marks an
+// empty slot. PieceAbilities is indexed like PieceTypes.
+type Loadout struct {
+	ElementID      int64
+	ArmyAbilities  [ArmyAbilityColumns]int64
+	PieceAbilities [len(PieceTypes)]int64
+	Items          [ItemColumns]int64
+}
@@ -0,0 +1,116 @@
+// File: internal/loadout/validate.go
+package loadout
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// Normalize validates in and returns its canonical form. Every problem is
+// reported at once in a *ValidationError; field names refer to the slots of
+// in as submitted, before compaction.
+func (v *Validator) Normalize(in Loadout) (Loadout, error) {
+	var verr ValidationError
+	out := in
+
+	if _, ok := v.elements[in.ElementID]; !ok {
+		verr.add("element_id", CodeUnknownElement, fmt.Sprintf("unknown element %d", in.ElementID))
+	}
+
+	v.checkItems(&verr, in.Items[:])
+	items := filled(in.Items[:])
+
+	armySlots := v.ArmySlots(items)
+	seen := make(map[int64]struct{}, ArmyAbilityColumns)
+	used := 0
+	for i, id := range in.ArmyAbilities {
+		if id == 0 {
+			continue
+		}
+		field := "army_ability_" + strconv.Itoa(i+1)
+		scope, ok := v.abilities[id]
+		if !ok {
+			verr.add(field, CodeUnknownAbility, fmt.Sprintf("unknown ability %d", id))
+			continue
+		}
+		if scope != ScopeArmyWide {
+			verr.add(field, CodeWrongAbilityScope, fmt.Sprintf("ability %d is %s and cannot take an army slot", id, scope))
+			continue
+		}
+		if _, dup := seen[id]; dup {
+			verr.add(field, CodeDuplicateAbility, fmt.Sprintf("ability %d is already in an army slot", id))
+			continue
+		}
+		seen[id] = struct{}{}
+		used++
+		if used > armySlots {
+			verr.add(field, CodeArmySlotsExceeded, fmt.Sprintf("loadout has %d army slots", armySlots))
+		}
+	}
+
+	pieceAllowed := v.PieceTypeSlotsAllowed(in.ElementID, items)
+	for i, id := range in.PieceAbilities {
+		if id == 0 {
+			continue
+		}
+		field := "ability_" + PieceTypes[i]
+		scope, ok := v.abilities[id]
+		if !ok {
+			verr.add(field, CodeUnknownAbility, fmt.Sprintf("unknown ability %d", id))
+			continue
+		}
+		if scope == ScopeArmyWide && !pieceAllowed {
+			verr.add(field, CodePieceTypeNotAllowed, "army-wide abilities in piece-type slots require the Lightning element or Multitasker's Schedule")
+		}
+	}
+
+	if err := verr.err(); err != nil {
+		return Loadout{}, err
+	}
+	compact(out.ArmyAbilities[:])
+	compact(out.Items[:])
+	return out, nil
+}
+
+// checkItems reports unknown, duplicate and incompatible items, and the first
+// item that takes the total slot cost over the limit.
+func (v *Validator) checkItems(verr *ValidationError, items []int64) {
+	var equipped []int64
+	cost := 0
+	for i, id := range items {
+		if id == 0 {
+			continue
+		}
+		field := "item_" + strconv.Itoa(i+1)
+		rule, ok := v.items[id]
+		if !ok {
+			verr.add(field, CodeUnknownItem, fmt.Sprintf("unknown item %d", id))
+			continue
+		}
+		if slices.Contains(equipped, id) {
+			verr.add(field, CodeDuplicateItem, fmt.Sprintf("item %d is already equipped", id))
+			continue
+		}
+		if other, clash := v.incompatibleWith(id, rule, equipped); clash {
+			verr.add(field, CodeIncompatibleItem, fmt.Sprintf("item %d cannot be equipped with item %d", id, other))
+			continue
+		}
+		equipped = append(equipped, id)
+		cost += rule.slotCost
+		if cost > v.itemSlotsTotal {
+			verr.add(field, CodeItemSlotsExceeded, fmt.Sprintf("items cost %d of %d slots", cost, v.itemSlotsTotal))
+		}
+	}
+}
+
+// incompatibleWith checks both directions so a one-sided
+// incompatible_item_ids list is still enforced.
+func (v *Validator) incompatibleWith(id int64, rule itemRule, equipped []int64) (int64, bool) {
+	for _, other := range equipped {
+		if slices.Contains(rule.incompatible, other) || slices.Contains(v.items[other].incompatible, id) {
+			return other, true
+		}
+	}
+	return 0, false
+}
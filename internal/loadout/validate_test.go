@@ -0,0 +1,187 @@
+package loadout
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"example.com/mvp-repo/internal/config"
+)
+
+// Shipped gameplay.json IDs used below.
+const (
+	water     = 0
+	fire      = 1
+	lightning = 4
+
+	blockPath  = 1 // piece_type
+	stalwart   = 2 // piece_type
+	doubleKill = 5 // army_wide
+	chainKill  = 7 // army_wide
+
+	multitaskers = 1
+	dagger       = 2
+	dualGloves   = 3 // +1 army slot, cost 1
+	tripleGloves = 4 // +2 army slots, cost 2
+	headmaster   = 5 // +3 army slots, cost 3
+	potOfHunger  = 6
+)
+
+func shippedValidator(t *testing.T) *Validator {
+	t.Helper()
+	cfg, err := config.LoadGameplayConfig("../../config/gameplay.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// pieces fills piece-type slots from pawn onwards.
+func pieces(ids ...int64) (out [len(PieceTypes)]int64) {
+	copy(out[:], ids)
+	return out
+}
+
+func TestNormalize(t *testing.T) {
+	v := shippedValidator(t)
+	tests := []struct {
+		name string
+		in   Loadout
+		// want lists the expected field errors as "field code"; empty means valid.
+		want []string
+	}{
+		{
+			name: "piece_type ability in piece slot",
+			in:   Loadout{ElementID: fire, PieceAbilities: pieces(blockPath, stalwart)},
+		},
+		{
+			name: "army_wide ability in army slot",
+			in:   Loadout{ElementID: fire, ArmyAbilities: [4]int64{doubleKill}},
+		},
+		{
+			name: "piece_type ability in army slot",
+			in:   Loadout{ElementID: fire, ArmyAbilities: [4]int64{blockPath}},
+			want: []string{"army_ability_1 " + CodeWrongAbilityScope},
+		},
+		{
+			name: "army_wide ability in piece slot without unlock",
+			in:   Loadout{ElementID: fire, PieceAbilities: pieces(0, doubleKill)},
+			want: []string{"ability_knight " + CodePieceTypeNotAllowed},
+		},
+		{
+			name: "lightning unlocks army_wide in piece slot",
+			in:   Loadout{ElementID: lightning, PieceAbilities: pieces(0, doubleKill)},
+		},
+		{
+			name: "multitasker's schedule unlocks army_wide in piece slot",
+			in:   Loadout{ElementID: water, PieceAbilities: pieces(chainKill), Items: [4]int64{multitaskers}},
+		},
+		{
+			name: "dual and triple gloves",
+			in:   Loadout{ElementID: fire, Items: [4]int64{dualGloves, tripleGloves}},
+			want: []string{"item_2 " + CodeIncompatibleItem},
+		},
+		{
+			name: "triple gloves and headmaster",
+			in:   Loadout{ElementID: fire, Items: [4]int64{tripleGloves, headmaster}},
+			want: []string{"item_2 " + CodeIncompatibleItem},
+		},
+		{
+			name: "headmaster and dual gloves",
+			in:   Loadout{ElementID: fire, Items: [4]int64{headmaster, dualGloves}},
+			want: []string{"item_2 " + CodeIncompatibleItem},
+		},
+		{
+			name: "slot cost over item_slots_total",
+			in:   Loadout{ElementID: fire, Items: [4]int64{headmaster, dagger, potOfHunger}},
+			want: []string{"item_3 " + CodeItemSlotsExceeded},
+		},
+		{
+			name: "army abilities over base slots",
+			in:   Loadout{ElementID: fire, ArmyAbilities: [4]int64{doubleKill, chainKill}},
+			want: []string{"army_ability_2 " + CodeArmySlotsExceeded},
+		},
+		{
+			name: "dual gloves add an army slot",
+			in:   Loadout{ElementID: fire, ArmyAbilities: [4]int64{doubleKill, chainKill}, Items: [4]int64{dualGloves}},
+		},
+		{
+			name: "unknown ids and duplicates",
+			in: Loadout{
+				ElementID:      99,
+				ArmyAbilities:  [4]int64{doubleKill, doubleKill},
+				PieceAbilities: pieces(0, 0, 42),
+				Items:          [4]int64{dagger, dagger, 77},
+			},
+			want: []string{
+				"element_id " + CodeUnknownElement,
+				"item_2 " + CodeDuplicateItem,
+				"item_3 " + CodeUnknownItem,
+				"army_ability_2 " + CodeDuplicateAbility,
+				"ability_bishop " + CodeUnknownAbility,
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := v.Normalize(tc.in)
+			var got []string
+			if err != nil {
+				var verr *ValidationError
+				if !errors.As(err, &verr) || !errors.Is(err, ErrInvalidLoadout) {
+					t.Fatalf("Normalize = %v, want *ValidationError", err)
+				}
+				for _, f := range verr.Fields {
+					got = append(got, f.Field+" "+f.Code)
+				}
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Fatalf("field errors = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCompacts(t *testing.T) {
+	v := shippedValidator(t)
+	in := Loadout{
+		ElementID:      lightning,
+		ArmyAbilities:  [4]int64{0, chainKill, 0, doubleKill},
+		PieceAbilities: pieces(0, blockPath),
+		Items:          [4]int64{0, headmaster},
+	}
+	out, err := v.Normalize(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.ArmyAbilities != [4]int64{doubleKill, chainKill} || out.Items != [4]int64{headmaster} {
+		t.Fatalf("Normalize = %+v, want army and item slots sorted with empties last", out)
+	}
+	if out.PieceAbilities != in.PieceAbilities {
+		t.Fatalf("piece slots moved: %v", out.PieceAbilities)
+	}
+}
+
+func TestArmySlots(t *testing.T) {
+	v := shippedValidator(t)
+	for _, tc := range []struct {
+		items []int64
+		want  int
+	}{
+		{nil, 1},
+		{[]int64{dualGloves}, 2},
+		{[]int64{tripleGloves}, 3},
+		{[]int64{headmaster}, 4},
+		// ArmySlots leaves exclusivity to Normalize; the sum is still capped.
+		{[]int64{dualGloves, tripleGloves, headmaster}, 4},
+		{[]int64{99}, 1},
+	} {
+		if got := v.ArmySlots(tc.items); got != tc.want {
+			t.Errorf("ArmySlots(%v) = %d, want %d", tc.items, got, tc.want)
+		}
+	}
+}
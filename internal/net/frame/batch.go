@@ -0,0 +1,160 @@
+package frame
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// BatchType is the msg_type of a batch envelope. The envelope payload is a
+// sequence of complete frames (records), each with its own header. Records
+// may carry FlagCompressed but must not be batches themselves. Only sent to
+// connections that negotiated batching.
+const BatchType uint16 = 0xFFFF
+
+var (
+	ErrNestedBatch = errors.New("frame: nested batch")
+	ErrBatchFull   = errors.New("frame: batch full")
+)
+
+// Batch builds a batch envelope in place. The zero value is unusable; call
+// Reset first.
+type Batch struct {
+	buf     []byte
+	records int
+	max     uint32
+}
+
+// Reset starts a new envelope in dst (reusing its capacity) whose payload may
+// hold up to maxPayload bytes of records.
+func (b *Batch) Reset(dst []byte, maxPayload uint32) {
+	if maxPayload == 0 {
+		maxPayload = DefaultMaxPayloadBytes
+	}
+	b.buf = append(dst[:0], zeroHeader[:]...)
+	b.records = 0
+	b.max = maxPayload
+}
+
+// AppendFrame adds an already encoded frame as the next record. It returns
+// ErrBatchFull, leaving the batch unchanged, when the record does not fit.
+func (b *Batch) AppendFrame(f []byte) error {
+	if len(f) < HeaderLen {
+		return ErrShortFrame
+	}
+	if binary.LittleEndian.Uint16(f[0:2]) == BatchType {
+		return ErrNestedBatch
+	}
+	if !b.Fits(len(f)) {
+		return ErrBatchFull
+	}
+	b.buf = append(b.buf, f...)
+	b.records++
+	return nil
+}
+
+// Append encodes (msgType, payload) as the next record.
+func (b *Batch) Append(msgType uint16, payload []byte) error {
+	if msgType == BatchType {
+		return ErrNestedBatch
+	}
+	if !b.Fits(HeaderLen + len(payload)) {
+		return ErrBatchFull
+	}
+	var hdr [HeaderLen]byte
+	binary.LittleEndian.PutUint16(hdr[0:2], msgType)
+	binary.LittleEndian.PutUint32(hdr[2:6], uint32(len(payload)))
+	b.buf = append(b.buf, hdr[:]...)
+	b.buf = append(b.buf, payload...)
+	b.records++
+	return nil
+}
+
+// Fits reports whether a record of recordLen bytes (header included) fits.
+func (b *Batch) Fits(recordLen int) bool {
+	return recordLen <= b.Room()
+}
+
+// Room returns how many more record bytes (headers included) fit.
+func (b *Batch) Room() int {
+	return int(b.max) - (len(b.buf) - HeaderLen)
+}
+
+// Len returns the number of records.
+func (b *Batch) Len() int {
+	return b.records
+}
+
+// Bytes finalizes the envelope header and returns the frame. The batch may be
+// appended to again afterwards; call Bytes again to refresh the header.
+func (b *Batch) Bytes() []byte {
+	binary.LittleEndian.PutUint16(b.buf[0:2], BatchType)
+	binary.LittleEndian.PutUint32(b.buf[2:6], uint32(len(b.buf)-HeaderLen))
+	return b.buf
+}
+
+// BatchReader walks the records of a batch envelope payload without copying.
+//
+//	r := frame.NewBatchReader(payload, max)
+//	for r.Next() {
+//		msgType, record, compressed := r.Record()
+//		...
+//	}
+//	if err := r.Err(); err != nil { ... }
+type BatchReader struct {
+	rest       []byte
+	max        uint32
+	err        error
+	msgType    uint16
+	payload    []byte
+	compressed bool
+}
+
+// NewBatchReader reads records from payload; each record payload is limited
+// to maxPayload bytes.
+func NewBatchReader(payload []byte, maxPayload uint32) BatchReader {
+	if maxPayload == 0 {
+		maxPayload = DefaultMaxPayloadBytes
+	}
+	return BatchReader{rest: payload, max: maxPayload}
+}
+
+// Next advances to the next record. It returns false at the end of the
+// envelope or on the first malformed record (see Err).
+func (r *BatchReader) Next() bool {
+	if r.err != nil || len(r.rest) == 0 {
+		return false
+	}
+	if len(r.rest) < HeaderLen {
+		r.err = ErrShortFrame
+		return false
+	}
+	msgType := binary.LittleEndian.Uint16(r.rest[0:2])
+	rawLen := binary.LittleEndian.Uint32(r.rest[2:6])
+	payLen := rawLen &^ FlagCompressed
+	switch {
+	case msgType == BatchType:
+		r.err = ErrNestedBatch
+	case payLen > r.max:
+		r.err = ErrPayloadTooLarge
+	case len(r.rest)-HeaderLen < int(payLen):
+		r.err = ErrLengthMismatch
+	}
+	if r.err != nil {
+		return false
+	}
+	end := HeaderLen + int(payLen)
+	r.msgType = msgType
+	r.payload = r.rest[HeaderLen:end:end]
+	r.compressed = rawLen&FlagCompressed != 0
+	r.rest = r.rest[end:]
+	return true
+}
+
+// Record returns the current record. The payload aliases the envelope.
+func (r *BatchReader) Record() (uint16, []byte, bool) {
+	return r.msgType, r.payload, r.compressed
+}
+
+func (r *BatchReader) Err() error {
+	return r.err
+}
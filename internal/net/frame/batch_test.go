@@ -0,0 +1,123 @@
+package frame
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type record struct {
+	msgType    uint16
+	payload    []byte
+	compressed bool
+}
+
+func TestBatchRoundTrip(t *testing.T) {
+	big := bytes.Repeat([]byte("snapshot "), 64)
+	compressed, err := EncodeCompressed(nil, 11, big, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, deflated, _, err := DecodeAny(compressed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []record{
+		{msgType: 21, payload: []byte("chat event")},
+		{msgType: 12, payload: nil},
+		{msgType: 11, payload: deflated, compressed: true},
+		{msgType: 4, payload: []byte{0}},
+	}
+
+	var b Batch
+	b.Reset(nil, 1024)
+	for i, r := range want {
+		if r.compressed {
+			err = b.AppendFrame(compressed)
+		} else {
+			err = b.Append(r.msgType, r.payload)
+		}
+		if err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+	}
+	if b.Len() != len(want) {
+		t.Fatalf("Len = %d, want %d", b.Len(), len(want))
+	}
+
+	msgType, payload, err := Decode(b.Bytes(), 1024)
+	if err != nil || msgType != BatchType {
+		t.Fatalf("Decode envelope = %#x, %v", msgType, err)
+	}
+	r := NewBatchReader(payload, 1024)
+	var got []record
+	for r.Next() {
+		msgType, p, c := r.Record()
+		got = append(got, record{msgType: msgType, payload: p, compressed: c})
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("Err = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("read %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].msgType != want[i].msgType || got[i].compressed != want[i].compressed || !bytes.Equal(got[i].payload, want[i].payload) {
+			t.Errorf("record %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	inflated, err := Inflate(nil, got[2].payload, 0)
+	if err != nil || !bytes.Equal(inflated, big) {
+		t.Errorf("compressed record did not inflate back: %v", err)
+	}
+}
+
+func TestBatchFull(t *testing.T) {
+	var b Batch
+	b.Reset(nil, 2*HeaderLen+8)
+	if err := b.Append(1, make([]byte, 8)); err != nil {
+		t.Fatal(err)
+	}
+	before := len(b.Bytes())
+	if err := b.Append(1, make([]byte, 1)); !errors.Is(err, ErrBatchFull) {
+		t.Fatalf("Append past MaxBytes = %v, want ErrBatchFull", err)
+	}
+	if len(b.Bytes()) != before || b.Len() != 1 {
+		t.Fatal("a refused record changed the batch")
+	}
+	if err := b.Append(1, nil); err != nil {
+		t.Fatalf("record filling the batch exactly: %v", err)
+	}
+	if b.Room() != 0 {
+		t.Errorf("Room = %d, want 0", b.Room())
+	}
+	if err := b.Append(BatchType, nil); !errors.Is(err, ErrNestedBatch) {
+		t.Errorf("nested Append = %v, want ErrNestedBatch", err)
+	}
+}
+
+func TestBatchReaderRejectsMalformed(t *testing.T) {
+	nested, _ := Encode(nil, BatchType, nil, 0)
+	oversized, _ := Encode(nil, 1, make([]byte, 16), 0)
+	valid, _ := Encode(nil, 1, []byte("ok"), 0)
+	for _, tc := range []struct {
+		name    string
+		payload []byte
+		want    error
+	}{
+		{"short header", valid[:HeaderLen-1], ErrShortFrame},
+		{"truncated payload", valid[:len(valid)-1], ErrLengthMismatch},
+		{"nested batch", nested, ErrNestedBatch},
+		{"record over limit", oversized, ErrPayloadTooLarge},
+		{"trailing garbage", append(append([]byte{}, valid...), 1, 2), ErrShortFrame},
+	} {
+		r := NewBatchReader(tc.payload, 8)
+		n := 0
+		for r.Next() {
+			n++
+		}
+		if !errors.Is(r.Err(), tc.want) {
+			t.Errorf("%s: Err = %v after %d records, want %v", tc.name, r.Err(), n, tc.want)
+		}
+	}
+}
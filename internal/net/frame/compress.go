@@ -0,0 +1,106 @@
+package frame
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+)
+
+// FlagCompressed is set in the high bit of the u32 payload_len header field
+// when the payload is raw DEFLATE (RFC 1951). The remaining 31 bits carry the
+// compressed length. Only sent to connections that negotiated payload compression.
+const FlagCompressed uint32 = 1 << 31
+
+var (
+	ErrNotSmaller       = errors.New("frame: compressed payload not smaller")
+	ErrCorruptPayload   = errors.New("frame: corrupt compressed payload")
+	ErrInflatedTooLarge = errors.New("frame: inflated payload too large")
+)
+
+var zeroHeader [HeaderLen]byte
+
+var flateWriters = sync.Pool{
+	New: func() any {
+		w, _ := flate.NewWriter(io.Discard, flate.BestSpeed)
+		return w
+	},
+}
+
+// EncodeCompressed appends a frame whose payload is DEFLATE-compressed and
+// flagged with FlagCompressed. It returns ErrNotSmaller when compression does
+// not save bytes so callers can fall back to Encode.
+func EncodeCompressed(dst []byte, msgType uint16, payload []byte, maxPayload uint32) ([]byte, error) {
+	if maxPayload == 0 {
+		maxPayload = DefaultMaxPayloadBytes
+	}
+	if uint32(len(payload)) > maxPayload {
+		return nil, ErrPayloadTooLarge
+	}
+	buf := bytes.NewBuffer(dst[:0])
+	buf.Write(zeroHeader[:])
+	w := flateWriters.Get().(*flate.Writer)
+	w.Reset(buf)
+	_, err := w.Write(payload)
+	if err == nil {
+		err = w.Close()
+	}
+	flateWriters.Put(w)
+	if err != nil {
+		return nil, err
+	}
+	out := buf.Bytes()
+	compressedLen := len(out) - HeaderLen
+	if compressedLen >= len(payload) {
+		return out[:0], ErrNotSmaller
+	}
+	binary.LittleEndian.PutUint16(out[0:2], msgType)
+	binary.LittleEndian.PutUint32(out[2:6], uint32(compressedLen)|FlagCompressed)
+	return out, nil
+}
+
+// DecodeAny is Decode for receivers that negotiated payload compression. It
+// reports whether the payload is compressed; compressed payloads must be
+// passed through Inflate.
+func DecodeAny(b []byte, maxPayload uint32) (uint16, []byte, bool, error) {
+	if len(b) < HeaderLen {
+		return 0, nil, false, ErrShortFrame
+	}
+	rawLen := binary.LittleEndian.Uint32(b[2:6])
+	if rawLen&FlagCompressed == 0 {
+		msgType, payload, err := Decode(b, maxPayload)
+		return msgType, payload, false, err
+	}
+	if maxPayload == 0 {
+		maxPayload = DefaultMaxPayloadBytes
+	}
+	payLen := rawLen &^ FlagCompressed
+	if payLen > maxPayload {
+		return 0, nil, false, ErrPayloadTooLarge
+	}
+	if len(b) != HeaderLen+int(payLen) {
+		return 0, nil, false, ErrLengthMismatch
+	}
+	return binary.LittleEndian.Uint16(b[0:2]), b[HeaderLen:], true, nil
+}
+
+// Inflate appends the decompressed form of payload to dst, failing if the
+// result would exceed maxPayload.
+func Inflate(dst []byte, payload []byte, maxPayload uint32) ([]byte, error) {
+	if maxPayload == 0 {
+		maxPayload = DefaultMaxPayloadBytes
+	}
+	r := flate.NewReader(bytes.NewReader(payload))
+	defer r.Close()
+	buf := bytes.NewBuffer(dst)
+	n, err := io.Copy(buf, io.LimitReader(r, int64(maxPayload)+1))
+	if err != nil {
+		return nil, ErrCorruptPayload
+	}
+	if n > int64(maxPayload) {
+		return nil, ErrInflatedTooLarge
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,241 @@
+package frame
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// benchTicks is how many successive messages each payload stream holds.
+const benchTicks = 16
+
+// benchStream is a run of successive wire payloads of one kind, shaped like
+// production traffic: consecutive messages differ the way consecutive ticks
+// or turns do, so context takeover is not credited for exact repeats.
+type benchStream struct {
+	name     string
+	msgType  protocol.MsgType
+	payloads [][]byte
+}
+
+func (s benchStream) plainBytes() int {
+	n := 0
+	for _, p := range s.payloads {
+		n += HeaderLen + len(p)
+	}
+	return n
+}
+
+// benchStreams builds world snapshots for a busy AOI (150 entities clustered
+// around the player, a few moving each tick) and battle timelines (40 events
+// plus a board snapshot per turn). The generator is seeded so runs are
+// comparable.
+func benchStreams(tb testing.TB) []benchStream {
+	tb.Helper()
+	rng := rand.New(rand.NewSource(1))
+
+	entities := make([]*gen.WorldEntity, 150)
+	for i := range entities {
+		entities[i] = &gen.WorldEntity{
+			EntityId: 100000 + uint64(rng.Intn(50000)),
+			X:        int32(2048 + rng.Intn(96) - 48),
+			Y:        int32(1024 + rng.Intn(96) - 48),
+			Kind:     uint32(rng.Intn(4)),
+		}
+	}
+	board := make([]byte, 64)
+	for i := range board {
+		if i < 16 || i >= 48 {
+			board[i] = byte(1 + i%6)
+		}
+	}
+
+	snapshots := benchStream{name: "snapshot", msgType: protocol.MSG_WORLD_SNAPSHOT}
+	timelines := benchStream{name: "timeline", msgType: protocol.MSG_BATTLE_OUTCOME_TIMELINE}
+	for tick := 0; tick < benchTicks; tick++ {
+		for _, e := range entities[:20] {
+			e.X += int32(rng.Intn(3) - 1)
+			e.Y += int32(rng.Intn(3) - 1)
+		}
+		snapshots.payloads = append(snapshots.payloads, marshal(tb, &gen.WorldSnapshot{
+			TickSeq:  48213 + uint32(tick),
+			Entities: entities,
+		}))
+
+		timeline := &gen.BattleOutcomeTimeline{BattleId: 9001, TurnSeq: 37 + uint32(tick)}
+		for i := 0; i < 40; i++ {
+			timeline.Events = append(timeline.Events, &gen.TimelineEvent{
+				EventSeq: uint32(tick*40 + i + 1),
+				Type:     gen.TimelineEventType(rng.Intn(8)),
+				A:        uint64(rng.Intn(32)),
+				B:        uint64(rng.Intn(32)),
+				X:        int32(rng.Intn(8)),
+				Y:        int32(rng.Intn(8)),
+				U:        uint32(rng.Intn(6)),
+			})
+		}
+		from, to := rng.Intn(64), rng.Intn(64)
+		board[to], board[from] = board[from], 0
+		timeline.BoardSnapshot = board
+		timelines.payloads = append(timelines.payloads, marshal(tb, timeline))
+	}
+	return []benchStream{snapshots, timelines}
+}
+
+func marshal(tb testing.TB, msg proto.Message) []byte {
+	tb.Helper()
+	raw, err := proto.Marshal(msg)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return raw
+}
+
+// reportWire records the mean wire size of one pass over the stream and the
+// share saved against the uncompressed frames.
+func reportWire(b *testing.B, s benchStream, wire int) {
+	plain := s.plainBytes()
+	b.ReportMetric(float64(wire)/float64(len(s.payloads)), "wire-B/msg")
+	b.ReportMetric(100*float64(plain-wire)/float64(plain), "saved-%")
+}
+
+func streamBytes(s benchStream) int64 {
+	return int64(s.plainBytes() / len(s.payloads))
+}
+
+func BenchmarkEncode(b *testing.B) {
+	for _, s := range benchStreams(b) {
+		b.Run(s.name, func(b *testing.B) {
+			buf := make([]byte, 0, DefaultMaxPayloadBytes+HeaderLen)
+			wire := 0
+			b.SetBytes(streamBytes(s))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				out, err := Encode(buf, uint16(s.msgType), s.payloads[i%benchTicks], 0)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if i < benchTicks {
+					wire += len(out)
+				}
+			}
+			if b.N >= benchTicks {
+				reportWire(b, s, wire)
+			}
+		})
+	}
+}
+
+// BenchmarkEncodeCompressed is the app-level path: every frame is deflated
+// on its own and flagged with FlagCompressed, falling back to a plain frame
+// when deflate does not help.
+func BenchmarkEncodeCompressed(b *testing.B) {
+	for _, s := range benchStreams(b) {
+		b.Run(s.name, func(b *testing.B) {
+			buf := make([]byte, 0, DefaultMaxPayloadBytes+HeaderLen)
+			wire := 0
+			b.SetBytes(streamBytes(s))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				payload := s.payloads[i%benchTicks]
+				out, err := EncodeCompressed(buf, uint16(s.msgType), payload, 0)
+				if errors.Is(err, ErrNotSmaller) {
+					// The gateway sends such frames uncompressed.
+					out, err = Encode(out, uint16(s.msgType), payload, 0)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+				buf = out[:0]
+				if i < benchTicks {
+					wire += len(out)
+				}
+			}
+			if b.N >= benchTicks {
+				reportWire(b, s, wire)
+			}
+		})
+	}
+}
+
+// BenchmarkPermessageDeflate models what the transport does with
+// permessage-deflate (RFC 7692) at the same level: the whole frame is
+// deflated and the stream is sync-flushed per message. With context takeover
+// the window carries over between messages, so a snapshot compresses against
+// the previous tick's; without it each message starts cold.
+func BenchmarkPermessageDeflate(b *testing.B) {
+	for _, s := range benchStreams(b) {
+		frames := make([][]byte, len(s.payloads))
+		for i, p := range s.payloads {
+			f, err := Encode(nil, uint16(s.msgType), p, 0)
+			if err != nil {
+				b.Fatal(err)
+			}
+			frames[i] = f
+		}
+		for _, takeover := range []bool{true, false} {
+			name := s.name + "/no_context_takeover"
+			if takeover {
+				name = s.name + "/context_takeover"
+			}
+			b.Run(name, func(b *testing.B) {
+				var out bytes.Buffer
+				w, err := flate.NewWriter(&out, flate.BestSpeed)
+				if err != nil {
+					b.Fatal(err)
+				}
+				wire := 0
+				b.SetBytes(streamBytes(s))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					out.Reset()
+					if !takeover {
+						w.Reset(&out)
+					}
+					if _, err := w.Write(frames[i%benchTicks]); err != nil {
+						b.Fatal(err)
+					}
+					if err := w.Flush(); err != nil {
+						b.Fatal(err)
+					}
+					// RFC 7692 strips the trailing 0x00 0x00 0xff 0xff.
+					if i < benchTicks {
+						wire += out.Len() - 4
+					}
+				}
+				if b.N >= benchTicks {
+					reportWire(b, s, wire)
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeCompressedSavesBytes(t *testing.T) {
+	for _, s := range benchStreams(t) {
+		p := s.payloads[0]
+		out, err := EncodeCompressed(nil, uint16(s.msgType), p, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		msgType, body, compressed, err := DecodeAny(out, 0)
+		if err != nil || !compressed || msgType != uint16(s.msgType) {
+			t.Fatalf("%s: DecodeAny = %d, compressed=%v, %v", s.name, msgType, compressed, err)
+		}
+		inflated, err := Inflate(nil, body, 0)
+		if err != nil {
+			t.Fatalf("%s: inflate: %v", s.name, err)
+		}
+		if !bytes.Equal(inflated, p) {
+			t.Fatalf("%s: round trip changed the payload", s.name)
+		}
+		t.Logf("%s: %d -> %d bytes", s.name, len(p), len(body))
+	}
+}
@@ -0,0 +1,185 @@
+package frame
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"runtime"
+	"testing"
+
+	"example.com/mvp-repo/internal/protocol/protocoltest"
+)
+
+// fuzzMax is the payload limit under fuzzing; small so inputs reach it.
+const fuzzMax = 4096
+
+// inflateOverhead covers flate's decompressor state (window and tables),
+// which Inflate allocates regardless of input size.
+const inflateOverhead = 128 << 10
+
+// seedFrames returns one valid frame per MsgType, a compressed one, and a
+// batch envelope of all of them.
+func seedFrames(tb testing.TB) [][]byte {
+	tb.Helper()
+	var frames [][]byte
+	var batch Batch
+	batch.Reset(nil, 1<<20)
+	for _, s := range protocoltest.Samples() {
+		f, err := Encode(nil, uint16(s.MsgType), s.Payload, fuzzMax)
+		if err != nil {
+			tb.Fatalf("%s: %v", s.MsgType, err)
+		}
+		frames = append(frames, f)
+		if err := batch.AppendFrame(f); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	compressed, err := EncodeCompressed(nil, 11, bytes.Repeat([]byte("entity "), 200), fuzzMax)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	frames = append(frames, compressed)
+	if err := batch.AppendFrame(compressed); err != nil {
+		tb.Fatal(err)
+	}
+	return append(frames, append([]byte(nil), batch.Bytes()...))
+}
+
+// allocated reports the bytes fn allocates on the heap.
+func allocated(fn func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	fn()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, seed := range seedFrames(f) {
+		f.Add(seed)
+	}
+	f.Add([]byte{})
+	f.Add([]byte{1, 0, 0xff, 0xff, 0xff, 0x7f})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var (
+			msgType uint16
+			payload []byte
+			err     error
+		)
+		if n := allocated(func() { msgType, payload, err = Decode(data, fuzzMax) }); n != 0 {
+			t.Fatalf("Decode allocated %d bytes", n)
+		}
+		if err == nil {
+			if len(payload) > fuzzMax {
+				t.Fatalf("payload of %d bytes passed a %d limit", len(payload), fuzzMax)
+			}
+			out, err := Encode(nil, msgType, payload, fuzzMax)
+			if err != nil {
+				t.Fatalf("re-encode: %v", err)
+			}
+			if !bytes.Equal(out, data) {
+				t.Fatalf("Encode(Decode(x)) != x")
+			}
+		}
+
+		msgType, payload, compressed, err := DecodeAny(data, fuzzMax)
+		if err != nil || !compressed {
+			return
+		}
+		var inflated []byte
+		n := allocated(func() { inflated, err = Inflate(nil, payload, fuzzMax) })
+		if n > 2*fuzzMax+inflateOverhead {
+			t.Fatalf("Inflate allocated %d bytes for a %d limit", n, fuzzMax)
+		}
+		if err != nil {
+			return
+		}
+		if len(inflated) > fuzzMax {
+			t.Fatalf("inflated to %d bytes past a %d limit", len(inflated), fuzzMax)
+		}
+		roundTripCompressed(t, msgType, inflated)
+	})
+}
+
+func FuzzEncode(f *testing.F) {
+	for _, s := range protocoltest.Samples() {
+		f.Add(uint16(s.MsgType), s.Payload)
+	}
+	f.Add(uint16(11), bytes.Repeat([]byte{0}, fuzzMax+1))
+	f.Fuzz(func(t *testing.T, msgType uint16, payload []byte) {
+		out, err := Encode(nil, msgType, payload, fuzzMax)
+		if len(payload) > fuzzMax {
+			if !errors.Is(err, ErrPayloadTooLarge) {
+				t.Fatalf("Encode of %d bytes = %v, want ErrPayloadTooLarge", len(payload), err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("Encode: %v", err)
+		}
+		gotType, gotPayload, err := Decode(out, fuzzMax)
+		if err != nil || gotType != msgType || !bytes.Equal(gotPayload, payload) {
+			t.Fatalf("Decode(Encode(x)) = %d, %v; want %d", gotType, err, msgType)
+		}
+		roundTripCompressed(t, msgType, payload)
+	})
+}
+
+// roundTripCompressed checks EncodeCompressed -> DecodeAny -> Inflate.
+func roundTripCompressed(t *testing.T, msgType uint16, payload []byte) {
+	t.Helper()
+	out, err := EncodeCompressed(nil, msgType, payload, fuzzMax)
+	if errors.Is(err, ErrNotSmaller) {
+		return
+	}
+	if err != nil {
+		t.Fatalf("EncodeCompressed: %v", err)
+	}
+	gotType, body, compressed, err := DecodeAny(out, fuzzMax)
+	if err != nil || !compressed || gotType != msgType {
+		t.Fatalf("DecodeAny(EncodeCompressed(x)) = %d, %v, %v", gotType, compressed, err)
+	}
+	inflated, err := Inflate(nil, body, fuzzMax)
+	if err != nil || !bytes.Equal(inflated, payload) {
+		t.Fatalf("compressed round trip changed the payload: %v", err)
+	}
+}
+
+func FuzzBatchReader(f *testing.F) {
+	for _, seed := range seedFrames(f) {
+		f.Add(seed)
+		if msgType, payload, err := Decode(seed, 1<<20); err == nil && msgType == BatchType {
+			f.Add(payload)
+		}
+	}
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		var rebuilt Batch
+		rebuilt.Reset(nil, uint32(len(payload)+1))
+		r := NewBatchReader(payload, fuzzMax)
+		for r.Next() {
+			msgType, record, compressed := r.Record()
+			if msgType == BatchType {
+				t.Fatal("BatchReader yielded a nested batch")
+			}
+			if len(record) > fuzzMax {
+				t.Fatalf("record of %d bytes passed a %d limit", len(record), fuzzMax)
+			}
+			var hdr [HeaderLen]byte
+			binary.LittleEndian.PutUint16(hdr[0:2], msgType)
+			length := uint32(len(record))
+			if compressed {
+				length |= FlagCompressed
+			}
+			binary.LittleEndian.PutUint32(hdr[2:6], length)
+			if err := rebuilt.AppendFrame(append(hdr[:], record...)); err != nil {
+				t.Fatalf("rebuild: %v", err)
+			}
+		}
+		if r.Err() != nil {
+			return
+		}
+		if got := rebuilt.Bytes()[HeaderLen:]; !bytes.Equal(got, payload) {
+			t.Fatal("re-batching the records did not reproduce the envelope")
+		}
+	})
+}
@@ -79,6 +79,24 @@ func (r *AccountsRepo) UpdateLastLogin(ctx context.Context, userID int64, lastLo
 	return nil
 }
 
+func (r *AccountsRepo) UpdatePassHash(ctx context.Context, userID int64, passHash []byte) error {
+	if r.db == nil {
+		return ErrNilDB
+	}
+	res, err := r.db.ExecContext(ctx, updateAccountPassHash(r.dialect), passHash, userID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
 func (r *AccountsRepo) getSingle(ctx context.Context, query string, arg any) (Account, error) {
 	if r.db == nil {
 		return Account{}, ErrNilDB
@@ -122,5 +140,12 @@ func updateAccountLastLogin(dialect Dialect) string {
 	return `UPDATE accounts SET last_login_at = ? WHERE user_id = ?`
 }
 
+func updateAccountPassHash(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `UPDATE accounts SET pass_hash = $1 WHERE user_id = $2`
+	}
+	return `UPDATE accounts SET pass_hash = ? WHERE user_id = ?`
+}
+
 const createAccountSQLite = `INSERT INTO accounts (email, username, pass_hash, created_at, last_login_at) VALUES (?, ?, ?, ?, ?)`
 const createAccountPostgres = `INSERT INTO accounts (email, username, pass_hash, created_at, last_login_at) VALUES ($1, $2, $3, $4, $5) RETURNING user_id`
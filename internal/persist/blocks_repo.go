@@ -0,0 +1,95 @@
+// File: internal/persist/blocks_repo.go
+package persist
+
+import (
+	"context"
+	"database/sql"
+)
+
+type Block struct {
+	BlockerUserID int64
+	BlockedUserID int64
+	CreatedAt     int64
+}
+
+type BlocksRepo struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func NewBlocksRepo(db *sql.DB, dialect Dialect) *BlocksRepo {
+	return &BlocksRepo{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+func (r *BlocksRepo) Add(ctx context.Context, block Block) error {
+	if r.db == nil {
+		return ErrNilDB
+	}
+	_, err := r.db.ExecContext(ctx, insertBlock(r.dialect), block.BlockerUserID, block.BlockedUserID, block.CreatedAt)
+	return err
+}
+
+func (r *BlocksRepo) Remove(ctx context.Context, blockerUserID int64, blockedUserID int64) error {
+	if r.db == nil {
+		return ErrNilDB
+	}
+	res, err := r.db.ExecContext(ctx, deleteBlock(r.dialect), blockerUserID, blockedUserID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *BlocksRepo) ListByBlocker(ctx context.Context, blockerUserID int64) ([]Block, error) {
+	if r.db == nil {
+		return nil, ErrNilDB
+	}
+	rows, err := r.db.QueryContext(ctx, selectBlocksByBlocker(r.dialect), blockerUserID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	blocks := make([]Block, 0)
+	for rows.Next() {
+		var block Block
+		if err := rows.Scan(&block.BlockerUserID, &block.BlockedUserID, &block.CreatedAt); err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+func insertBlock(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `INSERT INTO chat_blocks (blocker_user_id, blocked_user_id, created_at) VALUES ($1, $2, $3) ON CONFLICT (blocker_user_id, blocked_user_id) DO NOTHING`
+	}
+	return `INSERT INTO chat_blocks (blocker_user_id, blocked_user_id, created_at) VALUES (?, ?, ?) ON CONFLICT(blocker_user_id, blocked_user_id) DO NOTHING`
+}
+
+func deleteBlock(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `DELETE FROM chat_blocks WHERE blocker_user_id = $1 AND blocked_user_id = $2`
+	}
+	return `DELETE FROM chat_blocks WHERE blocker_user_id = ? AND blocked_user_id = ?`
+}
+
+func selectBlocksByBlocker(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `SELECT blocker_user_id, blocked_user_id, created_at FROM chat_blocks WHERE blocker_user_id = $1 ORDER BY blocked_user_id`
+	}
+	return `SELECT blocker_user_id, blocked_user_id, created_at FROM chat_blocks WHERE blocker_user_id = ? ORDER BY blocked_user_id`
+}
@@ -0,0 +1,104 @@
+// File: internal/persist/chat_messages_repo.go
+package persist
+
+import (
+	"context"
+	"database/sql"
+)
+
+type ChatMessage struct {
+	MessageID  int64
+	Channel    string
+	FromUserID int64
+	Text       string
+	CreatedAt  int64
+}
+
+type ChatMessagesRepo struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func NewChatMessagesRepo(db *sql.DB, dialect Dialect) *ChatMessagesRepo {
+	return &ChatMessagesRepo{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+func (r *ChatMessagesRepo) Insert(ctx context.Context, msg ChatMessage) (int64, error) {
+	if r.db == nil {
+		return 0, ErrNilDB
+	}
+	switch r.dialect {
+	case DialectPostgres:
+		row := r.db.QueryRowContext(ctx, insertChatMessagePostgres, msg.Channel, msg.FromUserID, msg.Text, msg.CreatedAt)
+		var messageID int64
+		if err := row.Scan(&messageID); err != nil {
+			return 0, err
+		}
+		return messageID, nil
+	default:
+		res, err := r.db.ExecContext(ctx, insertChatMessageSQLite, msg.Channel, msg.FromUserID, msg.Text, msg.CreatedAt)
+		if err != nil {
+			return 0, err
+		}
+		return res.LastInsertId()
+	}
+}
+
+// ListRecent returns up to limit of the newest messages in channel, oldest first.
+func (r *ChatMessagesRepo) ListRecent(ctx context.Context, channel string, limit int) ([]ChatMessage, error) {
+	if r.db == nil {
+		return nil, ErrNilDB
+	}
+	rows, err := r.db.QueryContext(ctx, selectRecentChatMessages(r.dialect), channel, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	msgs := make([]ChatMessage, 0, limit)
+	for rows.Next() {
+		var msg ChatMessage
+		if err := rows.Scan(&msg.MessageID, &msg.Channel, &msg.FromUserID, &msg.Text, &msg.CreatedAt); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+	return msgs, nil
+}
+
+// DeleteBefore removes every message created before cutoff and returns the number removed.
+func (r *ChatMessagesRepo) DeleteBefore(ctx context.Context, cutoff int64) (int64, error) {
+	if r.db == nil {
+		return 0, ErrNilDB
+	}
+	res, err := r.db.ExecContext(ctx, deleteChatMessagesBefore(r.dialect), cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func selectRecentChatMessages(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `SELECT message_id, channel, from_user_id, text, created_at FROM chat_messages WHERE channel = $1 ORDER BY message_id DESC LIMIT $2`
+	}
+	return `SELECT message_id, channel, from_user_id, text, created_at FROM chat_messages WHERE channel = ? ORDER BY message_id DESC LIMIT ?`
+}
+
+func deleteChatMessagesBefore(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `DELETE FROM chat_messages WHERE created_at < $1`
+	}
+	return `DELETE FROM chat_messages WHERE created_at < ?`
+}
+
+const insertChatMessageSQLite = `INSERT INTO chat_messages (channel, from_user_id, text, created_at) VALUES (?, ?, ?, ?)`
+const insertChatMessagePostgres = `INSERT INTO chat_messages (channel, from_user_id, text, created_at) VALUES ($1, $2, $3, $4) RETURNING message_id`
@@ -0,0 +1,14 @@
+-- File: internal/persist/migrations/postgres/002_chat_moderation.sql
+CREATE TABLE chat_mutes (
+	user_id BIGINT PRIMARY KEY,
+	muted_until BIGINT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	created_at BIGINT NOT NULL
+);
+
+CREATE TABLE chat_blocks (
+	blocker_user_id BIGINT NOT NULL,
+	blocked_user_id BIGINT NOT NULL,
+	created_at BIGINT NOT NULL,
+	PRIMARY KEY (blocker_user_id, blocked_user_id)
+);
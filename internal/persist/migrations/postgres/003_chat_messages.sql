@@ -0,0 +1,10 @@
+-- File: internal/persist/migrations/postgres/003_chat_messages.sql
+CREATE TABLE chat_messages (
+	message_id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	channel TEXT NOT NULL,
+	from_user_id BIGINT NOT NULL,
+	text TEXT NOT NULL,
+	created_at BIGINT NOT NULL
+);
+
+CREATE INDEX chat_messages_channel_created_idx ON chat_messages (channel, created_at);
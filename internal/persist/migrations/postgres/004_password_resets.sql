@@ -0,0 +1,9 @@
+-- File: internal/persist/migrations/postgres/004_password_resets.sql
+CREATE TABLE password_resets (
+	token_hash BYTEA PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	created_at BIGINT NOT NULL,
+	expires_at BIGINT NOT NULL
+);
+
+CREATE INDEX password_resets_user_idx ON password_resets (user_id);
@@ -0,0 +1,8 @@
+-- File: internal/persist/migrations/postgres/005_session_metadata.sql
+ALTER TABLE sessions ADD COLUMN last_seen_at BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE sessions ADD COLUMN ip TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN user_agent TEXT NOT NULL DEFAULT '';
+
+UPDATE sessions SET last_seen_at = created_at;
+
+CREATE INDEX sessions_user_idx ON sessions (user_id);
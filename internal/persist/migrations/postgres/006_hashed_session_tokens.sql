@@ -0,0 +1,18 @@
+-- File: internal/persist/migrations/postgres/006_hashed_session_tokens.sql
+-- Sessions are keyed by the SHA-256 of the bearer token from here on. Raw
+-- tokens cannot be carried over portably, so every existing session is
+-- dropped and clients log in again.
+DROP TABLE sessions;
+
+CREATE TABLE sessions (
+	token_hash BYTEA PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	expires_at BIGINT NOT NULL,
+	created_at BIGINT NOT NULL,
+	last_seen_at BIGINT NOT NULL,
+	ip TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX sessions_user_idx ON sessions (user_id);
+CREATE INDEX sessions_expires_idx ON sessions (expires_at);
@@ -0,0 +1,14 @@
+-- File: internal/persist/migrations/sqlite/002_chat_moderation.sql
+CREATE TABLE chat_mutes (
+	user_id INTEGER PRIMARY KEY,
+	muted_until INTEGER NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL
+);
+
+CREATE TABLE chat_blocks (
+	blocker_user_id INTEGER NOT NULL,
+	blocked_user_id INTEGER NOT NULL,
+	created_at INTEGER NOT NULL,
+	PRIMARY KEY (blocker_user_id, blocked_user_id)
+);
@@ -0,0 +1,10 @@
+-- File: internal/persist/migrations/sqlite/003_chat_messages.sql
+CREATE TABLE chat_messages (
+	message_id INTEGER PRIMARY KEY,
+	channel TEXT NOT NULL,
+	from_user_id INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	created_at INTEGER NOT NULL
+);
+
+CREATE INDEX chat_messages_channel_created_idx ON chat_messages (channel, created_at);
@@ -0,0 +1,9 @@
+-- File: internal/persist/migrations/sqlite/004_password_resets.sql
+CREATE TABLE password_resets (
+	token_hash BLOB PRIMARY KEY,
+	user_id INTEGER NOT NULL,
+	created_at INTEGER NOT NULL,
+	expires_at INTEGER NOT NULL
+);
+
+CREATE INDEX password_resets_user_idx ON password_resets (user_id);
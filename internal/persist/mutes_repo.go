@@ -0,0 +1,88 @@
+// File: internal/persist/mutes_repo.go
+package persist
+
+import (
+	"context"
+	"database/sql"
+)
+
+type Mute struct {
+	UserID     int64
+	MutedUntil int64
+	Reason     string
+	CreatedAt  int64
+}
+
+type MutesRepo struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func NewMutesRepo(db *sql.DB, dialect Dialect) *MutesRepo {
+	return &MutesRepo{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+func (r *MutesRepo) Get(ctx context.Context, userID int64) (Mute, error) {
+	if r.db == nil {
+		return Mute{}, ErrNilDB
+	}
+	row := r.db.QueryRowContext(ctx, selectMuteByUser(r.dialect), userID)
+	var mute Mute
+	if err := row.Scan(&mute.UserID, &mute.MutedUntil, &mute.Reason, &mute.CreatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return Mute{}, ErrNotFound
+		}
+		return Mute{}, err
+	}
+	return mute, nil
+}
+
+func (r *MutesRepo) Upsert(ctx context.Context, mute Mute) error {
+	if r.db == nil {
+		return ErrNilDB
+	}
+	_, err := r.db.ExecContext(ctx, upsertMute(r.dialect), mute.UserID, mute.MutedUntil, mute.Reason, mute.CreatedAt)
+	return err
+}
+
+func (r *MutesRepo) Delete(ctx context.Context, userID int64) error {
+	if r.db == nil {
+		return ErrNilDB
+	}
+	res, err := r.db.ExecContext(ctx, deleteMuteByUser(r.dialect), userID)
+	if err != nil {
+		return err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func selectMuteByUser(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `SELECT user_id, muted_until, reason, created_at FROM chat_mutes WHERE user_id = $1`
+	}
+	return `SELECT user_id, muted_until, reason, created_at FROM chat_mutes WHERE user_id = ?`
+}
+
+func upsertMute(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `INSERT INTO chat_mutes (user_id, muted_until, reason, created_at) VALUES ($1, $2, $3, $4) ON CONFLICT (user_id) DO UPDATE SET muted_until = EXCLUDED.muted_until, reason = EXCLUDED.reason, created_at = EXCLUDED.created_at`
+	}
+	return `INSERT INTO chat_mutes (user_id, muted_until, reason, created_at) VALUES (?, ?, ?, ?) ON CONFLICT(user_id) DO UPDATE SET muted_until = excluded.muted_until, reason = excluded.reason, created_at = excluded.created_at`
+}
+
+func deleteMuteByUser(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `DELETE FROM chat_mutes WHERE user_id = $1`
+	}
+	return `DELETE FROM chat_mutes WHERE user_id = ?`
+}
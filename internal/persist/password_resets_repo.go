@@ -0,0 +1,87 @@
+// File: internal/persist/password_resets_repo.go
+package persist
+
+import (
+	"context"
+	"database/sql"
+)
+
+// PasswordReset is an outstanding reset token. Only the token's hash is
+// stored; the token itself exists in the mail sent to the user.
+type PasswordReset struct {
+	TokenHash []byte
+	UserID    int64
+	CreatedAt int64
+	ExpiresAt int64
+}
+
+type PasswordResetsRepo struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+func NewPasswordResetsRepo(db *sql.DB, dialect Dialect) *PasswordResetsRepo {
+	return &PasswordResetsRepo{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+func (r *PasswordResetsRepo) Create(ctx context.Context, reset PasswordReset) error {
+	if r.db == nil {
+		return ErrNilDB
+	}
+	_, err := r.db.ExecContext(ctx, insertPasswordReset(r.dialect), reset.TokenHash, reset.UserID, reset.CreatedAt, reset.ExpiresAt)
+	return err
+}
+
+// Consume deletes the reset with tokenHash if it expires after now and
+// returns its user. The delete makes the token single-use even under
+// concurrent attempts. ErrNotFound covers unknown, used and expired tokens.
+func (r *PasswordResetsRepo) Consume(ctx context.Context, tokenHash []byte, now int64) (int64, error) {
+	if r.db == nil {
+		return 0, ErrNilDB
+	}
+	var userID int64
+	row := r.db.QueryRowContext(ctx, consumePasswordReset(r.dialect), tokenHash, now)
+	if err := row.Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrNotFound
+		}
+		return 0, err
+	}
+	return userID, nil
+}
+
+// DeleteByUser drops every outstanding reset for userID.
+func (r *PasswordResetsRepo) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	if r.db == nil {
+		return 0, ErrNilDB
+	}
+	res, err := r.db.ExecContext(ctx, deletePasswordResetsByUser(r.dialect), userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func insertPasswordReset(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `INSERT INTO password_resets (token_hash, user_id, created_at, expires_at) VALUES ($1, $2, $3, $4)`
+	}
+	return `INSERT INTO password_resets (token_hash, user_id, created_at, expires_at) VALUES (?, ?, ?, ?)`
+}
+
+func consumePasswordReset(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `DELETE FROM password_resets WHERE token_hash = $1 AND expires_at > $2 RETURNING user_id`
+	}
+	return `DELETE FROM password_resets WHERE token_hash = ? AND expires_at > ? RETURNING user_id`
+}
+
+func deletePasswordResetsByUser(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `DELETE FROM password_resets WHERE user_id = $1`
+	}
+	return `DELETE FROM password_resets WHERE user_id = ?`
+}
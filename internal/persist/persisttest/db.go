@@ -0,0 +1,29 @@
+// Package persisttest opens migrated SQLite databases for tests.
+package persisttest
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"example.com/mvp-repo/internal/persist"
+)
+
+// Open returns a migrated SQLite database in a temporary directory, closed
+// when the test ends.
+func Open(tb testing.TB) *sql.DB {
+	tb.Helper()
+	dsn := "file:" + filepath.Join(tb.TempDir(), "test.db") + "?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)"
+	db, err := persist.Open(context.Background(), persist.Config{Driver: "sqlite", DSN: dsn, Dialect: persist.DialectSQLite})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { _ = db.Close() })
+	if err := persist.Migrate(context.Background(), db, persist.DialectSQLite); err != nil {
+		tb.Fatal(err)
+	}
+	return db
+}
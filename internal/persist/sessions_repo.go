@@ -6,11 +6,17 @@ import (
 	"database/sql"
 )
 
+// Session is a stored login. Only the SHA-256 of the bearer token is kept;
+// the token itself is handed to the client once and never stored.
 type Session struct {
-	Token     string
-	UserID    int64
-	ExpiresAt int64
-	CreatedAt int64
+	TokenHash  []byte
+	UserID     int64
+	ExpiresAt  int64
+	CreatedAt  int64
+	LastSeenAt int64
+	// IP and UserAgent describe the client that created the session.
+	IP        string
+	UserAgent string
 }
 
 type SessionsRepo struct {
@@ -29,17 +35,17 @@ func (r *SessionsRepo) Create(ctx context.Context, session Session) error {
 	if r.db == nil {
 		return ErrNilDB
 	}
-	_, err := r.db.ExecContext(ctx, insertSession(r.dialect), session.Token, session.UserID, session.ExpiresAt, session.CreatedAt)
+	_, err := r.db.ExecContext(ctx, insertSession(r.dialect), session.TokenHash, session.UserID, session.ExpiresAt, session.CreatedAt, session.LastSeenAt, session.IP, session.UserAgent)
 	return err
 }
 
-func (r *SessionsRepo) Get(ctx context.Context, token string) (Session, error) {
+func (r *SessionsRepo) Get(ctx context.Context, tokenHash []byte) (Session, error) {
 	if r.db == nil {
 		return Session{}, ErrNilDB
 	}
-	row := r.db.QueryRowContext(ctx, selectSessionByToken(r.dialect), token)
+	row := r.db.QueryRowContext(ctx, selectSessionByHash(r.dialect), tokenHash)
 	var session Session
-	if err := row.Scan(&session.Token, &session.UserID, &session.ExpiresAt, &session.CreatedAt); err != nil {
+	if err := row.Scan(&session.TokenHash, &session.UserID, &session.ExpiresAt, &session.CreatedAt, &session.LastSeenAt, &session.IP, &session.UserAgent); err != nil {
 		if err == sql.ErrNoRows {
 			return Session{}, ErrNotFound
 		}
@@ -48,11 +54,45 @@ func (r *SessionsRepo) Get(ctx context.Context, token string) (Session, error) {
 	return session, nil
 }
 
-func (r *SessionsRepo) Delete(ctx context.Context, token string) error {
+// ListByUser returns the sessions of userID that expire after now, most
+// recently seen first.
+func (r *SessionsRepo) ListByUser(ctx context.Context, userID int64, now int64) ([]Session, error) {
+	if r.db == nil {
+		return nil, ErrNilDB
+	}
+	rows, err := r.db.QueryContext(ctx, selectSessionsByUser(r.dialect), userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sessions := make([]Session, 0)
+	for rows.Next() {
+		var session Session
+		if err := rows.Scan(&session.TokenHash, &session.UserID, &session.ExpiresAt, &session.CreatedAt, &session.LastSeenAt, &session.IP, &session.UserAgent); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Touch records that the session was used at lastSeenAt.
+func (r *SessionsRepo) Touch(ctx context.Context, tokenHash []byte, lastSeenAt int64) error {
+	if r.db == nil {
+		return ErrNilDB
+	}
+	_, err := r.db.ExecContext(ctx, updateSessionLastSeen(r.dialect), lastSeenAt, tokenHash)
+	return err
+}
+
+func (r *SessionsRepo) Delete(ctx context.Context, tokenHash []byte) error {
 	if r.db == nil {
 		return ErrNilDB
 	}
-	res, err := r.db.ExecContext(ctx, deleteSessionByToken(r.dialect), token)
+	res, err := r.db.ExecContext(ctx, deleteSessionByHash(r.dialect), tokenHash)
 	if err != nil {
 		return err
 	}
@@ -66,23 +106,77 @@ func (r *SessionsRepo) Delete(ctx context.Context, token string) error {
 	return nil
 }
 
+// DeleteByUser drops every session of userID and reports how many there were.
+func (r *SessionsRepo) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	if r.db == nil {
+		return 0, ErrNilDB
+	}
+	res, err := r.db.ExecContext(ctx, deleteSessionsByUser(r.dialect), userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteExpired drops up to limit sessions that expired at or before now and
+// reports how many it removed; callers repeat until it returns less than
+// limit.
+func (r *SessionsRepo) DeleteExpired(ctx context.Context, now int64, limit int) (int64, error) {
+	if r.db == nil {
+		return 0, ErrNilDB
+	}
+	res, err := r.db.ExecContext(ctx, deleteExpiredSessions(r.dialect), now, limit)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 func insertSession(dialect Dialect) string {
 	if dialect == DialectPostgres {
-		return `INSERT INTO sessions (token, user_id, expires_at, created_at) VALUES ($1, $2, $3, $4)`
+		return `INSERT INTO sessions (token_hash, user_id, expires_at, created_at, last_seen_at, ip, user_agent) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	}
+	return `INSERT INTO sessions (token_hash, user_id, expires_at, created_at, last_seen_at, ip, user_agent) VALUES (?, ?, ?, ?, ?, ?, ?)`
+}
+
+func selectSessionByHash(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `SELECT token_hash, user_id, expires_at, created_at, last_seen_at, ip, user_agent FROM sessions WHERE token_hash = $1`
+	}
+	return `SELECT token_hash, user_id, expires_at, created_at, last_seen_at, ip, user_agent FROM sessions WHERE token_hash = ?`
+}
+
+func selectSessionsByUser(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `SELECT token_hash, user_id, expires_at, created_at, last_seen_at, ip, user_agent FROM sessions WHERE user_id = $1 AND expires_at > $2 ORDER BY last_seen_at DESC, created_at DESC`
+	}
+	return `SELECT token_hash, user_id, expires_at, created_at, last_seen_at, ip, user_agent FROM sessions WHERE user_id = ? AND expires_at > ? ORDER BY last_seen_at DESC, created_at DESC`
+}
+
+func updateSessionLastSeen(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `UPDATE sessions SET last_seen_at = $1 WHERE token_hash = $2`
+	}
+	return `UPDATE sessions SET last_seen_at = ? WHERE token_hash = ?`
+}
+
+func deleteSessionByHash(dialect Dialect) string {
+	if dialect == DialectPostgres {
+		return `DELETE FROM sessions WHERE token_hash = $1`
 	}
-	return `INSERT INTO sessions (token, user_id, expires_at, created_at) VALUES (?, ?, ?, ?)`
+	return `DELETE FROM sessions WHERE token_hash = ?`
 }
 
-func selectSessionByToken(dialect Dialect) string {
+func deleteSessionsByUser(dialect Dialect) string {
 	if dialect == DialectPostgres {
-		return `SELECT token, user_id, expires_at, created_at FROM sessions WHERE token = $1`
+		return `DELETE FROM sessions WHERE user_id = $1`
 	}
-	return `SELECT token, user_id, expires_at, created_at FROM sessions WHERE token = ?`
+	return `DELETE FROM sessions WHERE user_id = ?`
 }
 
-func deleteSessionByToken(dialect Dialect) string {
+func deleteExpiredSessions(dialect Dialect) string {
 	if dialect == DialectPostgres {
-		return `DELETE FROM sessions WHERE token = $1`
+		return `DELETE FROM sessions WHERE token_hash IN (SELECT token_hash FROM sessions WHERE expires_at <= $1 LIMIT $2)`
 	}
-	return `DELETE FROM sessions WHERE token = ?`
+	return `DELETE FROM sessions WHERE token_hash IN (SELECT token_hash FROM sessions WHERE expires_at <= ? LIMIT ?)`
 }
@@ -0,0 +1,98 @@
+package persist_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"example.com/mvp-repo/internal/persist"
+	"example.com/mvp-repo/internal/persist/persisttest"
+)
+
+func newSessionsRepo(t *testing.T) *persist.SessionsRepo {
+	t.Helper()
+	return persist.NewSessionsRepo(persisttest.Open(t), persist.DialectSQLite)
+}
+
+func createSession(t *testing.T, repo *persist.SessionsRepo, s persist.Session) {
+	t.Helper()
+	if err := repo.Create(context.Background(), s); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSessionsDeleteExpiredBatches(t *testing.T) {
+	repo := newSessionsRepo(t)
+	ctx := context.Background()
+	for i := range 7 {
+		createSession(t, repo, persist.Session{TokenHash: []byte(fmt.Sprintf("expired-%d", i)), UserID: 1, ExpiresAt: 100 + int64(i)})
+	}
+	createSession(t, repo, persist.Session{TokenHash: []byte("live"), UserID: 1, ExpiresAt: 1000})
+
+	for _, want := range []int64{3, 3, 1, 0} {
+		n, err := repo.DeleteExpired(ctx, 200, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != want {
+			t.Fatalf("DeleteExpired removed %d, want %d", n, want)
+		}
+	}
+	if _, err := repo.Get(ctx, []byte("live")); err != nil {
+		t.Fatalf("unexpired session deleted: %v", err)
+	}
+}
+
+func TestSessionsDeleteExpiredBoundary(t *testing.T) {
+	repo := newSessionsRepo(t)
+	ctx := context.Background()
+	createSession(t, repo, persist.Session{TokenHash: []byte("at-now"), UserID: 1, ExpiresAt: 200})
+	createSession(t, repo, persist.Session{TokenHash: []byte("after-now"), UserID: 1, ExpiresAt: 201})
+
+	if n, err := repo.DeleteExpired(ctx, 200, 10); err != nil || n != 1 {
+		t.Fatalf("DeleteExpired = %d, %v; want 1", n, err)
+	}
+	if _, err := repo.Get(ctx, []byte("at-now")); !errors.Is(err, persist.ErrNotFound) {
+		t.Fatalf("session expiring at now kept: %v", err)
+	}
+}
+
+func TestSessionsListByUser(t *testing.T) {
+	repo := newSessionsRepo(t)
+	ctx := context.Background()
+	createSession(t, repo, persist.Session{TokenHash: []byte("old"), UserID: 1, ExpiresAt: 1000, CreatedAt: 10, LastSeenAt: 10, IP: "192.0.2.1", UserAgent: "a"})
+	createSession(t, repo, persist.Session{TokenHash: []byte("recent"), UserID: 1, ExpiresAt: 1000, CreatedAt: 20, LastSeenAt: 50, IP: "192.0.2.2", UserAgent: "b"})
+	createSession(t, repo, persist.Session{TokenHash: []byte("expired"), UserID: 1, ExpiresAt: 100, CreatedAt: 30, LastSeenAt: 90})
+	createSession(t, repo, persist.Session{TokenHash: []byte("other-user"), UserID: 2, ExpiresAt: 1000})
+
+	got, err := repo.ListByUser(ctx, 1, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || !bytes.Equal(got[0].TokenHash, []byte("recent")) || !bytes.Equal(got[1].TokenHash, []byte("old")) {
+		t.Fatalf("ListByUser = %+v, want recent then old", got)
+	}
+	if got[0].IP != "192.0.2.2" || got[0].UserAgent != "b" || got[0].CreatedAt != 20 {
+		t.Fatalf("ListByUser lost metadata: %+v", got[0])
+	}
+}
+
+func TestSessionsDeleteByUser(t *testing.T) {
+	repo := newSessionsRepo(t)
+	ctx := context.Background()
+	createSession(t, repo, persist.Session{TokenHash: []byte("a"), UserID: 1, ExpiresAt: 1000})
+	createSession(t, repo, persist.Session{TokenHash: []byte("b"), UserID: 1, ExpiresAt: 1000})
+	createSession(t, repo, persist.Session{TokenHash: []byte("c"), UserID: 2, ExpiresAt: 1000})
+
+	if n, err := repo.DeleteByUser(ctx, 1); err != nil || n != 2 {
+		t.Fatalf("DeleteByUser = %d, %v; want 2", n, err)
+	}
+	if _, err := repo.Get(ctx, []byte("c")); err != nil {
+		t.Fatalf("other user's session deleted: %v", err)
+	}
+	if err := repo.Delete(ctx, []byte("a")); !errors.Is(err, persist.ErrNotFound) {
+		t.Fatalf("Delete of removed session = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,1795 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: game.proto
+
+package gen
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ElementId int32
+
+const (
+	ElementId_WATER     ElementId = 0
+	ElementId_FIRE      ElementId = 1
+	ElementId_EARTH     ElementId = 2
+	ElementId_AIR_WIND  ElementId = 3
+	ElementId_LIGHTNING ElementId = 4
+)
+
+// Enum value maps for ElementId.
+var (
+	ElementId_name = map[int32]string{
+		0: "WATER",
+		1: "FIRE",
+		2: "EARTH",
+		3: "AIR_WIND",
+		4: "LIGHTNING",
+	}
+	ElementId_value = map[string]int32{
+		"WATER":     0,
+		"FIRE":      1,
+		"EARTH":     2,
+		"AIR_WIND":  3,
+		"LIGHTNING": 4,
+	}
+)
+
+func (x ElementId) Enum() *ElementId {
+	p := new(ElementId)
+	*p = x
+	return p
+}
+
+func (x ElementId) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ElementId) Descriptor() protoreflect.EnumDescriptor {
+	return file_game_proto_enumTypes[0].Descriptor()
+}
+
+func (ElementId) Type() protoreflect.EnumType {
+	return &file_game_proto_enumTypes[0]
+}
+
+func (x ElementId) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ElementId.Descriptor instead.
+func (ElementId) EnumDescriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{0}
+}
+
+// Proto3 requires an explicit 0 value; contract IDs begin at 1.
+type ItemId int32
+
+const (
+	ItemId_ITEM_ID_UNSPECIFIED        ItemId = 0
+	ItemId_ITEM_MULTITASKERS_SCHEDULE ItemId = 1
+	ItemId_ITEM_POISONED_DAGGER       ItemId = 2
+	ItemId_ITEM_DUAL_ADEPTS_GLOVES    ItemId = 3
+	ItemId_ITEM_TRIPLE_ADEPTS_GLOVES  ItemId = 4
+	ItemId_ITEM_HEADMASTER_RING       ItemId = 5
+	ItemId_ITEM_POT_OF_HUNGER         ItemId = 6
+	ItemId_ITEM_SOLAR_NECKLACE        ItemId = 7
+)
+
+// Enum value maps for ItemId.
+var (
+	ItemId_name = map[int32]string{
+		0: "ITEM_ID_UNSPECIFIED",
+		1: "ITEM_MULTITASKERS_SCHEDULE",
+		2: "ITEM_POISONED_DAGGER",
+		3: "ITEM_DUAL_ADEPTS_GLOVES",
+		4: "ITEM_TRIPLE_ADEPTS_GLOVES",
+		5: "ITEM_HEADMASTER_RING",
+		6: "ITEM_POT_OF_HUNGER",
+		7: "ITEM_SOLAR_NECKLACE",
+	}
+	ItemId_value = map[string]int32{
+		"ITEM_ID_UNSPECIFIED":        0,
+		"ITEM_MULTITASKERS_SCHEDULE": 1,
+		"ITEM_POISONED_DAGGER":       2,
+		"ITEM_DUAL_ADEPTS_GLOVES":    3,
+		"ITEM_TRIPLE_ADEPTS_GLOVES":  4,
+		"ITEM_HEADMASTER_RING":       5,
+		"ITEM_POT_OF_HUNGER":         6,
+		"ITEM_SOLAR_NECKLACE":        7,
+	}
+)
+
+func (x ItemId) Enum() *ItemId {
+	p := new(ItemId)
+	*p = x
+	return p
+}
+
+func (x ItemId) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ItemId) Descriptor() protoreflect.EnumDescriptor {
+	return file_game_proto_enumTypes[1].Descriptor()
+}
+
+func (ItemId) Type() protoreflect.EnumType {
+	return &file_game_proto_enumTypes[1]
+}
+
+func (x ItemId) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ItemId.Descriptor instead.
+func (ItemId) EnumDescriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{1}
+}
+
+// Proto3 requires an explicit 0 value; contract IDs begin at 1.
+type AbilityId int32
+
+const (
+	AbilityId_ABILITY_ID_UNSPECIFIED AbilityId = 0
+	AbilityId_ABILITY_BLOCK_PATH     AbilityId = 1
+	AbilityId_ABILITY_STALWART       AbilityId = 2
+	AbilityId_ABILITY_BELLIGERENT    AbilityId = 3
+	AbilityId_ABILITY_REDO           AbilityId = 4
+	AbilityId_ABILITY_DOUBLE_KILL    AbilityId = 5
+	AbilityId_ABILITY_QUANTUM_KILL   AbilityId = 6
+	AbilityId_ABILITY_CHAIN_KILL     AbilityId = 7
+	AbilityId_ABILITY_NECROMANCER    AbilityId = 8
+)
+
+// Enum value maps for AbilityId.
+var (
+	AbilityId_name = map[int32]string{
+		0: "ABILITY_ID_UNSPECIFIED",
+		1: "ABILITY_BLOCK_PATH",
+		2: "ABILITY_STALWART",
+		3: "ABILITY_BELLIGERENT",
+		4: "ABILITY_REDO",
+		5: "ABILITY_DOUBLE_KILL",
+		6: "ABILITY_QUANTUM_KILL",
+		7: "ABILITY_CHAIN_KILL",
+		8: "ABILITY_NECROMANCER",
+	}
+	AbilityId_value = map[string]int32{
+		"ABILITY_ID_UNSPECIFIED": 0,
+		"ABILITY_BLOCK_PATH":     1,
+		"ABILITY_STALWART":       2,
+		"ABILITY_BELLIGERENT":    3,
+		"ABILITY_REDO":           4,
+		"ABILITY_DOUBLE_KILL":    5,
+		"ABILITY_QUANTUM_KILL":   6,
+		"ABILITY_CHAIN_KILL":     7,
+		"ABILITY_NECROMANCER":    8,
+	}
+)
+
+func (x AbilityId) Enum() *AbilityId {
+	p := new(AbilityId)
+	*p = x
+	return p
+}
+
+func (x AbilityId) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AbilityId) Descriptor() protoreflect.EnumDescriptor {
+	return file_game_proto_enumTypes[2].Descriptor()
+}
+
+func (AbilityId) Type() protoreflect.EnumType {
+	return &file_game_proto_enumTypes[2]
+}
+
+func (x AbilityId) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AbilityId.Descriptor instead.
+func (AbilityId) EnumDescriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{2}
+}
+
+type Dir4 int32
+
+const (
+	Dir4_N Dir4 = 0
+	Dir4_E Dir4 = 1
+	Dir4_S Dir4 = 2
+	Dir4_W Dir4 = 3
+)
+
+// Enum value maps for Dir4.
+var (
+	Dir4_name = map[int32]string{
+		0: "N",
+		1: "E",
+		2: "S",
+		3: "W",
+	}
+	Dir4_value = map[string]int32{
+		"N": 0,
+		"E": 1,
+		"S": 2,
+		"W": 3,
+	}
+)
+
+func (x Dir4) Enum() *Dir4 {
+	p := new(Dir4)
+	*p = x
+	return p
+}
+
+func (x Dir4) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Dir4) Descriptor() protoreflect.EnumDescriptor {
+	return file_game_proto_enumTypes[3].Descriptor()
+}
+
+func (Dir4) Type() protoreflect.EnumType {
+	return &file_game_proto_enumTypes[3]
+}
+
+func (x Dir4) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Dir4.Descriptor instead.
+func (Dir4) EnumDescriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{3}
+}
+
+type BattleActionType int32
+
+const (
+	BattleActionType_MOVE       BattleActionType = 0
+	BattleActionType_CHAIN_KILL BattleActionType = 1
+)
+
+// Enum value maps for BattleActionType.
+var (
+	BattleActionType_name = map[int32]string{
+		0: "MOVE",
+		1: "CHAIN_KILL",
+	}
+	BattleActionType_value = map[string]int32{
+		"MOVE":       0,
+		"CHAIN_KILL": 1,
+	}
+)
+
+func (x BattleActionType) Enum() *BattleActionType {
+	p := new(BattleActionType)
+	*p = x
+	return p
+}
+
+func (x BattleActionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (BattleActionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_game_proto_enumTypes[4].Descriptor()
+}
+
+func (BattleActionType) Type() protoreflect.EnumType {
+	return &file_game_proto_enumTypes[4]
+}
+
+func (x BattleActionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use BattleActionType.Descriptor instead.
+func (BattleActionType) EnumDescriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{4}
+}
+
+type TimelineEventType int32
+
+const (
+	TimelineEventType_EV_MOVE           TimelineEventType = 0
+	TimelineEventType_EV_CAPTURE        TimelineEventType = 1
+	TimelineEventType_EV_EXTRA_CAPTURE  TimelineEventType = 2
+	TimelineEventType_EV_BLOCK_PATH_SET TimelineEventType = 3
+	TimelineEventType_EV_ABILITY_FIZZLE TimelineEventType = 4
+	TimelineEventType_EV_REDO_REWIND    TimelineEventType = 5 // always rewinds 2 plies (defender replays)
+	TimelineEventType_EV_PIECE_RESTORED TimelineEventType = 6
+	TimelineEventType_EV_MATCH_STATE    TimelineEventType = 7
+)
+
+// Enum value maps for TimelineEventType.
+var (
+	TimelineEventType_name = map[int32]string{
+		0: "EV_MOVE",
+		1: "EV_CAPTURE",
+		2: "EV_EXTRA_CAPTURE",
+		3: "EV_BLOCK_PATH_SET",
+		4: "EV_ABILITY_FIZZLE",
+		5: "EV_REDO_REWIND",
+		6: "EV_PIECE_RESTORED",
+		7: "EV_MATCH_STATE",
+	}
+	TimelineEventType_value = map[string]int32{
+		"EV_MOVE":           0,
+		"EV_CAPTURE":        1,
+		"EV_EXTRA_CAPTURE":  2,
+		"EV_BLOCK_PATH_SET": 3,
+		"EV_ABILITY_FIZZLE": 4,
+		"EV_REDO_REWIND":    5,
+		"EV_PIECE_RESTORED": 6,
+		"EV_MATCH_STATE":    7,
+	}
+)
+
+func (x TimelineEventType) Enum() *TimelineEventType {
+	p := new(TimelineEventType)
+	*p = x
+	return p
+}
+
+func (x TimelineEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TimelineEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_game_proto_enumTypes[5].Descriptor()
+}
+
+func (TimelineEventType) Type() protoreflect.EnumType {
+	return &file_game_proto_enumTypes[5]
+}
+
+func (x TimelineEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TimelineEventType.Descriptor instead.
+func (TimelineEventType) EnumDescriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{5}
+}
+
+type Hello struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Token           []byte                 `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`                                             // session token from HTTPS login
+	ProtocolVersion uint32                 `protobuf:"varint,2,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // 0 from pre-negotiation clients, treated as 1
+	ClientBuild     string                 `protobuf:"bytes,3,opt,name=client_build,json=clientBuild,proto3" json:"client_build,omitempty"`              // free-form, for diagnostics
+	Features        []string               `protobuf:"bytes,4,rep,name=features,proto3" json:"features,omitempty"`                                       // optional features the client understands
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Hello) Reset() {
+	*x = Hello{}
+	mi := &file_game_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hello) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hello) ProtoMessage() {}
+
+func (x *Hello) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hello.ProtoReflect.Descriptor instead.
+func (*Hello) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Hello) GetToken() []byte {
+	if x != nil {
+		return x.Token
+	}
+	return nil
+}
+
+func (x *Hello) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *Hello) GetClientBuild() string {
+	if x != nil {
+		return x.ClientBuild
+	}
+	return ""
+}
+
+func (x *Hello) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+type Welcome struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PlayerId        uint64                 `protobuf:"varint,1,opt,name=player_id,json=playerId,proto3" json:"player_id,omitempty"`
+	ServerTimeS     uint32                 `protobuf:"varint,2,opt,name=server_time_s,json=serverTimeS,proto3" json:"server_time_s,omitempty"`           // coarse; optional
+	ProtocolVersion uint32                 `protobuf:"varint,3,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"` // server protocol version
+	ServerBuild     string                 `protobuf:"bytes,4,opt,name=server_build,json=serverBuild,proto3" json:"server_build,omitempty"`
+	Features        []string               `protobuf:"bytes,5,rep,name=features,proto3" json:"features,omitempty"` // negotiated: enabled on the server and offered in Hello
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Welcome) Reset() {
+	*x = Welcome{}
+	mi := &file_game_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Welcome) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Welcome) ProtoMessage() {}
+
+func (x *Welcome) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Welcome.ProtoReflect.Descriptor instead.
+func (*Welcome) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Welcome) GetPlayerId() uint64 {
+	if x != nil {
+		return x.PlayerId
+	}
+	return 0
+}
+
+func (x *Welcome) GetServerTimeS() uint32 {
+	if x != nil {
+		return x.ServerTimeS
+	}
+	return 0
+}
+
+func (x *Welcome) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *Welcome) GetServerBuild() string {
+	if x != nil {
+		return x.ServerBuild
+	}
+	return ""
+}
+
+func (x *Welcome) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+// Keepalive (contract lists msg types, but schema was not explicitly defined there)
+type Ping struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Ping) Reset() {
+	*x = Ping{}
+	mi := &file_game_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Ping) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Ping) ProtoMessage() {}
+
+func (x *Ping) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Ping.ProtoReflect.Descriptor instead.
+func (*Ping) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{2}
+}
+
+type Pong struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Pong) Reset() {
+	*x = Pong{}
+	mi := &file_game_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Pong) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Pong) ProtoMessage() {}
+
+func (x *Pong) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Pong.ProtoReflect.Descriptor instead.
+func (*Pong) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{3}
+}
+
+// Sent while the server drains; repeated as a countdown until the socket is
+// closed with status 1001 (going away).
+type ServerShutdown struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SecondsRemaining uint32                 `protobuf:"varint,1,opt,name=seconds_remaining,json=secondsRemaining,proto3" json:"seconds_remaining,omitempty"`
+	Reason           string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ServerShutdown) Reset() {
+	*x = ServerShutdown{}
+	mi := &file_game_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerShutdown) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerShutdown) ProtoMessage() {}
+
+func (x *ServerShutdown) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerShutdown.ProtoReflect.Descriptor instead.
+func (*ServerShutdown) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ServerShutdown) GetSecondsRemaining() uint32 {
+	if x != nil {
+		return x.SecondsRemaining
+	}
+	return 0
+}
+
+func (x *ServerShutdown) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Error / rejection (contract lists msg type, but schema was not explicitly defined there)
+type Error struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          uint32                 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"` // protocol.ErrorCode (DECISION 0015); < 100 closes the connection
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`  // human-readable, non-localized
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	mi := &file_game_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Error) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Error) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type WorldMoveIntent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// MVP: cardinal movement on tile grid
+	Dx            int32 `protobuf:"zigzag32,1,opt,name=dx,proto3" json:"dx,omitempty"` // -1,0,1
+	Dy            int32 `protobuf:"zigzag32,2,opt,name=dy,proto3" json:"dy,omitempty"` // -1,0,1
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorldMoveIntent) Reset() {
+	*x = WorldMoveIntent{}
+	mi := &file_game_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorldMoveIntent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorldMoveIntent) ProtoMessage() {}
+
+func (x *WorldMoveIntent) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorldMoveIntent.ProtoReflect.Descriptor instead.
+func (*WorldMoveIntent) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *WorldMoveIntent) GetDx() int32 {
+	if x != nil {
+		return x.Dx
+	}
+	return 0
+}
+
+func (x *WorldMoveIntent) GetDy() int32 {
+	if x != nil {
+		return x.Dy
+	}
+	return 0
+}
+
+type ChatSend struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatSend) Reset() {
+	*x = ChatSend{}
+	mi := &file_game_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatSend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatSend) ProtoMessage() {}
+
+func (x *ChatSend) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatSend.ProtoReflect.Descriptor instead.
+func (*ChatSend) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ChatSend) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type ChatEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FromPlayerId  uint64                 `protobuf:"varint,1,opt,name=from_player_id,json=fromPlayerId,proto3" json:"from_player_id,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatEvent) Reset() {
+	*x = ChatEvent{}
+	mi := &file_game_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatEvent) ProtoMessage() {}
+
+func (x *ChatEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatEvent.ProtoReflect.Descriptor instead.
+func (*ChatEvent) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ChatEvent) GetFromPlayerId() uint64 {
+	if x != nil {
+		return x.FromPlayerId
+	}
+	return 0
+}
+
+func (x *ChatEvent) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type WorldEntity struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EntityId      uint64                 `protobuf:"varint,1,opt,name=entity_id,json=entityId,proto3" json:"entity_id,omitempty"`
+	X             int32                  `protobuf:"zigzag32,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"zigzag32,3,opt,name=y,proto3" json:"y,omitempty"`
+	Kind          uint32                 `protobuf:"varint,4,opt,name=kind,proto3" json:"kind,omitempty"` // small enum in code (not canon-locked in proto yet)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorldEntity) Reset() {
+	*x = WorldEntity{}
+	mi := &file_game_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorldEntity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorldEntity) ProtoMessage() {}
+
+func (x *WorldEntity) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorldEntity.ProtoReflect.Descriptor instead.
+func (*WorldEntity) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WorldEntity) GetEntityId() uint64 {
+	if x != nil {
+		return x.EntityId
+	}
+	return 0
+}
+
+func (x *WorldEntity) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *WorldEntity) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *WorldEntity) GetKind() uint32 {
+	if x != nil {
+		return x.Kind
+	}
+	return 0
+}
+
+type WorldSnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TickSeq       uint32                 `protobuf:"varint,1,opt,name=tick_seq,json=tickSeq,proto3" json:"tick_seq,omitempty"`
+	Entities      []*WorldEntity         `protobuf:"bytes,2,rep,name=entities,proto3" json:"entities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorldSnapshot) Reset() {
+	*x = WorldSnapshot{}
+	mi := &file_game_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorldSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorldSnapshot) ProtoMessage() {}
+
+func (x *WorldSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorldSnapshot.ProtoReflect.Descriptor instead.
+func (*WorldSnapshot) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WorldSnapshot) GetTickSeq() uint32 {
+	if x != nil {
+		return x.TickSeq
+	}
+	return 0
+}
+
+func (x *WorldSnapshot) GetEntities() []*WorldEntity {
+	if x != nil {
+		return x.Entities
+	}
+	return nil
+}
+
+type WorldDelta struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TickSeq       uint32                 `protobuf:"varint,1,opt,name=tick_seq,json=tickSeq,proto3" json:"tick_seq,omitempty"`
+	Upserts       []*WorldEntity         `protobuf:"bytes,2,rep,name=upserts,proto3" json:"upserts,omitempty"`         // changed entities
+	Removes       []uint64               `protobuf:"varint,3,rep,packed,name=removes,proto3" json:"removes,omitempty"` // entity_ids leaving AOI or despawned
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorldDelta) Reset() {
+	*x = WorldDelta{}
+	mi := &file_game_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorldDelta) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorldDelta) ProtoMessage() {}
+
+func (x *WorldDelta) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorldDelta.ProtoReflect.Descriptor instead.
+func (*WorldDelta) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WorldDelta) GetTickSeq() uint32 {
+	if x != nil {
+		return x.TickSeq
+	}
+	return 0
+}
+
+func (x *WorldDelta) GetUpserts() []*WorldEntity {
+	if x != nil {
+		return x.Upserts
+	}
+	return nil
+}
+
+func (x *WorldDelta) GetRemoves() []uint64 {
+	if x != nil {
+		return x.Removes
+	}
+	return nil
+}
+
+type BattleStart struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	BattleId    uint64                 `protobuf:"varint,1,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`
+	Seed        uint64                 `protobuf:"fixed64,2,opt,name=seed,proto3" json:"seed,omitempty"`
+	ElementSelf ElementId              `protobuf:"varint,3,opt,name=element_self,json=elementSelf,proto3,enum=mvp.ElementId" json:"element_self,omitempty"`
+	ElementOpp  ElementId              `protobuf:"varint,4,opt,name=element_opp,json=elementOpp,proto3,enum=mvp.ElementId" json:"element_opp,omitempty"`
+	// loadouts may be sent as IDs only; client is renderer/UI
+	ArmyAbilitiesSelf []uint32 `protobuf:"varint,5,rep,packed,name=army_abilities_self,json=armyAbilitiesSelf,proto3" json:"army_abilities_self,omitempty"` // AbilityId
+	ArmyAbilitiesOpp  []uint32 `protobuf:"varint,6,rep,packed,name=army_abilities_opp,json=armyAbilitiesOpp,proto3" json:"army_abilities_opp,omitempty"`
+	ItemsSelf         []uint32 `protobuf:"varint,7,rep,packed,name=items_self,json=itemsSelf,proto3" json:"items_self,omitempty"` // ItemId (up to 4 entries)
+	ItemsOpp          []uint32 `protobuf:"varint,8,rep,packed,name=items_opp,json=itemsOpp,proto3" json:"items_opp,omitempty"`
+	InitialBoard      []byte   `protobuf:"bytes,9,opt,name=initial_board,json=initialBoard,proto3" json:"initial_board,omitempty"` // compact board encodings (implementation-defined)
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *BattleStart) Reset() {
+	*x = BattleStart{}
+	mi := &file_game_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleStart) ProtoMessage() {}
+
+func (x *BattleStart) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleStart.ProtoReflect.Descriptor instead.
+func (*BattleStart) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BattleStart) GetBattleId() uint64 {
+	if x != nil {
+		return x.BattleId
+	}
+	return 0
+}
+
+func (x *BattleStart) GetSeed() uint64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *BattleStart) GetElementSelf() ElementId {
+	if x != nil {
+		return x.ElementSelf
+	}
+	return ElementId_WATER
+}
+
+func (x *BattleStart) GetElementOpp() ElementId {
+	if x != nil {
+		return x.ElementOpp
+	}
+	return ElementId_WATER
+}
+
+func (x *BattleStart) GetArmyAbilitiesSelf() []uint32 {
+	if x != nil {
+		return x.ArmyAbilitiesSelf
+	}
+	return nil
+}
+
+func (x *BattleStart) GetArmyAbilitiesOpp() []uint32 {
+	if x != nil {
+		return x.ArmyAbilitiesOpp
+	}
+	return nil
+}
+
+func (x *BattleStart) GetItemsSelf() []uint32 {
+	if x != nil {
+		return x.ItemsSelf
+	}
+	return nil
+}
+
+func (x *BattleStart) GetItemsOpp() []uint32 {
+	if x != nil {
+		return x.ItemsOpp
+	}
+	return nil
+}
+
+func (x *BattleStart) GetInitialBoard() []byte {
+	if x != nil {
+		return x.InitialBoard
+	}
+	return nil
+}
+
+type SolarTopUp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AbilityId     uint32                 `protobuf:"varint,1,opt,name=ability_id,json=abilityId,proto3" json:"ability_id,omitempty"`               // AbilityId (must be consumable)
+	TargetPieceId uint64                 `protobuf:"varint,2,opt,name=target_piece_id,json=targetPieceId,proto3" json:"target_piece_id,omitempty"` // 0 for side-level consumables
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SolarTopUp) Reset() {
+	*x = SolarTopUp{}
+	mi := &file_game_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SolarTopUp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SolarTopUp) ProtoMessage() {}
+
+func (x *SolarTopUp) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SolarTopUp.ProtoReflect.Descriptor instead.
+func (*SolarTopUp) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SolarTopUp) GetAbilityId() uint32 {
+	if x != nil {
+		return x.AbilityId
+	}
+	return 0
+}
+
+func (x *SolarTopUp) GetTargetPieceId() uint64 {
+	if x != nil {
+		return x.TargetPieceId
+	}
+	return 0
+}
+
+type BattleTurnInput struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	BattleId   uint64                 `protobuf:"varint,1,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`
+	TurnSeq    uint32                 `protobuf:"varint,2,opt,name=turn_seq,json=turnSeq,proto3" json:"turn_seq,omitempty"` // ply index (normally increments; may decrement by 2 after EV_REDO_REWIND)
+	ActionType BattleActionType       `protobuf:"varint,3,opt,name=action_type,json=actionType,proto3,enum=mvp.BattleActionType" json:"action_type,omitempty"`
+	// MOVE
+	MovePieceId uint64 `protobuf:"varint,10,opt,name=move_piece_id,json=movePieceId,proto3" json:"move_piece_id,omitempty"`
+	MoveToX     int32  `protobuf:"zigzag32,11,opt,name=move_to_x,json=moveToX,proto3" json:"move_to_x,omitempty"`
+	MoveToY     int32  `protobuf:"zigzag32,12,opt,name=move_to_y,json=moveToY,proto3" json:"move_to_y,omitempty"`
+	PromoteTo   uint32 `protobuf:"varint,13,opt,name=promote_to,json=promoteTo,proto3" json:"promote_to,omitempty"` // PieceType or 0 (optional) — PieceType IDs are defined in internal/protocol (ledgered).
+	// CHAIN_KILL
+	ChainCapturerId      uint64 `protobuf:"varint,20,opt,name=chain_capturer_id,json=chainCapturerId,proto3" json:"chain_capturer_id,omitempty"`
+	ChainPiggybackAllyId uint64 `protobuf:"varint,21,opt,name=chain_piggyback_ally_id,json=chainPiggybackAllyId,proto3" json:"chain_piggyback_ally_id,omitempty"`
+	ChainTargetId        uint64 `protobuf:"varint,22,opt,name=chain_target_id,json=chainTargetId,proto3" json:"chain_target_id,omitempty"`
+	// post-move defense choice
+	BlockPathDir4 uint32 `protobuf:"varint,30,opt,name=block_path_dir4,json=blockPathDir4,proto3" json:"block_path_dir4,omitempty"` // Dir4, or 255 if none
+	// optional item use
+	SolarTopup    *SolarTopUp `protobuf:"bytes,40,opt,name=solar_topup,json=solarTopup,proto3" json:"solar_topup,omitempty"` // optional
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleTurnInput) Reset() {
+	*x = BattleTurnInput{}
+	mi := &file_game_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleTurnInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleTurnInput) ProtoMessage() {}
+
+func (x *BattleTurnInput) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleTurnInput.ProtoReflect.Descriptor instead.
+func (*BattleTurnInput) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *BattleTurnInput) GetBattleId() uint64 {
+	if x != nil {
+		return x.BattleId
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetTurnSeq() uint32 {
+	if x != nil {
+		return x.TurnSeq
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetActionType() BattleActionType {
+	if x != nil {
+		return x.ActionType
+	}
+	return BattleActionType_MOVE
+}
+
+func (x *BattleTurnInput) GetMovePieceId() uint64 {
+	if x != nil {
+		return x.MovePieceId
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetMoveToX() int32 {
+	if x != nil {
+		return x.MoveToX
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetMoveToY() int32 {
+	if x != nil {
+		return x.MoveToY
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetPromoteTo() uint32 {
+	if x != nil {
+		return x.PromoteTo
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetChainCapturerId() uint64 {
+	if x != nil {
+		return x.ChainCapturerId
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetChainPiggybackAllyId() uint64 {
+	if x != nil {
+		return x.ChainPiggybackAllyId
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetChainTargetId() uint64 {
+	if x != nil {
+		return x.ChainTargetId
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetBlockPathDir4() uint32 {
+	if x != nil {
+		return x.BlockPathDir4
+	}
+	return 0
+}
+
+func (x *BattleTurnInput) GetSolarTopup() *SolarTopUp {
+	if x != nil {
+		return x.SolarTopup
+	}
+	return nil
+}
+
+type TimelineEvent struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	EventSeq uint32                 `protobuf:"varint,1,opt,name=event_seq,json=eventSeq,proto3" json:"event_seq,omitempty"`
+	Type     TimelineEventType      `protobuf:"varint,2,opt,name=type,proto3,enum=mvp.TimelineEventType" json:"type,omitempty"`
+	// union payload (flattened for simplicity)
+	A             uint64 `protobuf:"varint,10,opt,name=a,proto3" json:"a,omitempty"`
+	B             uint64 `protobuf:"varint,11,opt,name=b,proto3" json:"b,omitempty"`
+	X             int32  `protobuf:"zigzag32,12,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32  `protobuf:"zigzag32,13,opt,name=y,proto3" json:"y,omitempty"`
+	U             uint32 `protobuf:"varint,14,opt,name=u,proto3" json:"u,omitempty"`
+	V             uint32 `protobuf:"varint,15,opt,name=v,proto3" json:"v,omitempty"`
+	S             string `protobuf:"bytes,16,opt,name=s,proto3" json:"s,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TimelineEvent) Reset() {
+	*x = TimelineEvent{}
+	mi := &file_game_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TimelineEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TimelineEvent) ProtoMessage() {}
+
+func (x *TimelineEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TimelineEvent.ProtoReflect.Descriptor instead.
+func (*TimelineEvent) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *TimelineEvent) GetEventSeq() uint32 {
+	if x != nil {
+		return x.EventSeq
+	}
+	return 0
+}
+
+func (x *TimelineEvent) GetType() TimelineEventType {
+	if x != nil {
+		return x.Type
+	}
+	return TimelineEventType_EV_MOVE
+}
+
+func (x *TimelineEvent) GetA() uint64 {
+	if x != nil {
+		return x.A
+	}
+	return 0
+}
+
+func (x *TimelineEvent) GetB() uint64 {
+	if x != nil {
+		return x.B
+	}
+	return 0
+}
+
+func (x *TimelineEvent) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *TimelineEvent) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *TimelineEvent) GetU() uint32 {
+	if x != nil {
+		return x.U
+	}
+	return 0
+}
+
+func (x *TimelineEvent) GetV() uint32 {
+	if x != nil {
+		return x.V
+	}
+	return 0
+}
+
+func (x *TimelineEvent) GetS() string {
+	if x != nil {
+		return x.S
+	}
+	return ""
+}
+
+type BattleOutcomeTimeline struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BattleId      uint64                 `protobuf:"varint,1,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`
+	TurnSeq       uint32                 `protobuf:"varint,2,opt,name=turn_seq,json=turnSeq,proto3" json:"turn_seq,omitempty"`
+	Events        []*TimelineEvent       `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	BoardSnapshot []byte                 `protobuf:"bytes,4,opt,name=board_snapshot,json=boardSnapshot,proto3" json:"board_snapshot,omitempty"` // optional: full board for resync; required after EV_REDO_REWIND
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BattleOutcomeTimeline) Reset() {
+	*x = BattleOutcomeTimeline{}
+	mi := &file_game_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleOutcomeTimeline) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleOutcomeTimeline) ProtoMessage() {}
+
+func (x *BattleOutcomeTimeline) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleOutcomeTimeline.ProtoReflect.Descriptor instead.
+func (*BattleOutcomeTimeline) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *BattleOutcomeTimeline) GetBattleId() uint64 {
+	if x != nil {
+		return x.BattleId
+	}
+	return 0
+}
+
+func (x *BattleOutcomeTimeline) GetTurnSeq() uint32 {
+	if x != nil {
+		return x.TurnSeq
+	}
+	return 0
+}
+
+func (x *BattleOutcomeTimeline) GetEvents() []*TimelineEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *BattleOutcomeTimeline) GetBoardSnapshot() []byte {
+	if x != nil {
+		return x.BoardSnapshot
+	}
+	return nil
+}
+
+type BattleEnd struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	BattleId       uint64                 `protobuf:"varint,1,opt,name=battle_id,json=battleId,proto3" json:"battle_id,omitempty"`
+	WinnerPlayerId uint64                 `protobuf:"varint,2,opt,name=winner_player_id,json=winnerPlayerId,proto3" json:"winner_player_id,omitempty"` // 0 if draw
+	Reason         uint32                 `protobuf:"varint,3,opt,name=reason,proto3" json:"reason,omitempty"`                                         // checkmate/stalemate/resign/timeout (codes not canon-locked in proto yet)
+	XpAwarded      uint32                 `protobuf:"varint,4,opt,name=xp_awarded,json=xpAwarded,proto3" json:"xp_awarded,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BattleEnd) Reset() {
+	*x = BattleEnd{}
+	mi := &file_game_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BattleEnd) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BattleEnd) ProtoMessage() {}
+
+func (x *BattleEnd) ProtoReflect() protoreflect.Message {
+	mi := &file_game_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BattleEnd.ProtoReflect.Descriptor instead.
+func (*BattleEnd) Descriptor() ([]byte, []int) {
+	return file_game_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BattleEnd) GetBattleId() uint64 {
+	if x != nil {
+		return x.BattleId
+	}
+	return 0
+}
+
+func (x *BattleEnd) GetWinnerPlayerId() uint64 {
+	if x != nil {
+		return x.WinnerPlayerId
+	}
+	return 0
+}
+
+func (x *BattleEnd) GetReason() uint32 {
+	if x != nil {
+		return x.Reason
+	}
+	return 0
+}
+
+func (x *BattleEnd) GetXpAwarded() uint32 {
+	if x != nil {
+		return x.XpAwarded
+	}
+	return 0
+}
+
+var File_game_proto protoreflect.FileDescriptor
+
+const file_game_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"game.proto\x12\x03mvp\"\x87\x01\n" +
+	"\x05Hello\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\fR\x05token\x12)\n" +
+	"\x10protocol_version\x18\x02 \x01(\rR\x0fprotocolVersion\x12!\n" +
+	"\fclient_build\x18\x03 \x01(\tR\vclientBuild\x12\x1a\n" +
+	"\bfeatures\x18\x04 \x03(\tR\bfeatures\"\xb4\x01\n" +
+	"\aWelcome\x12\x1b\n" +
+	"\tplayer_id\x18\x01 \x01(\x04R\bplayerId\x12\"\n" +
+	"\rserver_time_s\x18\x02 \x01(\rR\vserverTimeS\x12)\n" +
+	"\x10protocol_version\x18\x03 \x01(\rR\x0fprotocolVersion\x12!\n" +
+	"\fserver_build\x18\x04 \x01(\tR\vserverBuild\x12\x1a\n" +
+	"\bfeatures\x18\x05 \x03(\tR\bfeatures\"\x06\n" +
+	"\x04Ping\"\x06\n" +
+	"\x04Pong\"U\n" +
+	"\x0eServerShutdown\x12+\n" +
+	"\x11seconds_remaining\x18\x01 \x01(\rR\x10secondsRemaining\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"/\n" +
+	"\x05Error\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\rR\x04code\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"1\n" +
+	"\x0fWorldMoveIntent\x12\x0e\n" +
+	"\x02dx\x18\x01 \x01(\x11R\x02dx\x12\x0e\n" +
+	"\x02dy\x18\x02 \x01(\x11R\x02dy\"\x1e\n" +
+	"\bChatSend\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"E\n" +
+	"\tChatEvent\x12$\n" +
+	"\x0efrom_player_id\x18\x01 \x01(\x04R\ffromPlayerId\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"Z\n" +
+	"\vWorldEntity\x12\x1b\n" +
+	"\tentity_id\x18\x01 \x01(\x04R\bentityId\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x11R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x11R\x01y\x12\x12\n" +
+	"\x04kind\x18\x04 \x01(\rR\x04kind\"X\n" +
+	"\rWorldSnapshot\x12\x19\n" +
+	"\btick_seq\x18\x01 \x01(\rR\atickSeq\x12,\n" +
+	"\bentities\x18\x02 \x03(\v2\x10.mvp.WorldEntityR\bentities\"m\n" +
+	"\n" +
+	"WorldDelta\x12\x19\n" +
+	"\btick_seq\x18\x01 \x01(\rR\atickSeq\x12*\n" +
+	"\aupserts\x18\x02 \x03(\v2\x10.mvp.WorldEntityR\aupserts\x12\x18\n" +
+	"\aremoves\x18\x03 \x03(\x04R\aremoves\"\xe1\x02\n" +
+	"\vBattleStart\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\x04R\bbattleId\x12\x12\n" +
+	"\x04seed\x18\x02 \x01(\x06R\x04seed\x121\n" +
+	"\felement_self\x18\x03 \x01(\x0e2\x0e.mvp.ElementIdR\velementSelf\x12/\n" +
+	"\velement_opp\x18\x04 \x01(\x0e2\x0e.mvp.ElementIdR\n" +
+	"elementOpp\x12.\n" +
+	"\x13army_abilities_self\x18\x05 \x03(\rR\x11armyAbilitiesSelf\x12,\n" +
+	"\x12army_abilities_opp\x18\x06 \x03(\rR\x10armyAbilitiesOpp\x12\x1d\n" +
+	"\n" +
+	"items_self\x18\a \x03(\rR\titemsSelf\x12\x1b\n" +
+	"\titems_opp\x18\b \x03(\rR\bitemsOpp\x12#\n" +
+	"\rinitial_board\x18\t \x01(\fR\finitialBoard\"S\n" +
+	"\n" +
+	"SolarTopUp\x12\x1d\n" +
+	"\n" +
+	"ability_id\x18\x01 \x01(\rR\tabilityId\x12&\n" +
+	"\x0ftarget_piece_id\x18\x02 \x01(\x04R\rtargetPieceId\"\xe1\x03\n" +
+	"\x0fBattleTurnInput\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\x04R\bbattleId\x12\x19\n" +
+	"\bturn_seq\x18\x02 \x01(\rR\aturnSeq\x126\n" +
+	"\vaction_type\x18\x03 \x01(\x0e2\x15.mvp.BattleActionTypeR\n" +
+	"actionType\x12\"\n" +
+	"\rmove_piece_id\x18\n" +
+	" \x01(\x04R\vmovePieceId\x12\x1a\n" +
+	"\tmove_to_x\x18\v \x01(\x11R\amoveToX\x12\x1a\n" +
+	"\tmove_to_y\x18\f \x01(\x11R\amoveToY\x12\x1d\n" +
+	"\n" +
+	"promote_to\x18\r \x01(\rR\tpromoteTo\x12*\n" +
+	"\x11chain_capturer_id\x18\x14 \x01(\x04R\x0fchainCapturerId\x125\n" +
+	"\x17chain_piggyback_ally_id\x18\x15 \x01(\x04R\x14chainPiggybackAllyId\x12&\n" +
+	"\x0fchain_target_id\x18\x16 \x01(\x04R\rchainTargetId\x12&\n" +
+	"\x0fblock_path_dir4\x18\x1e \x01(\rR\rblockPathDir4\x120\n" +
+	"\vsolar_topup\x18( \x01(\v2\x0f.mvp.SolarTopUpR\n" +
+	"solarTopup\"\xba\x01\n" +
+	"\rTimelineEvent\x12\x1b\n" +
+	"\tevent_seq\x18\x01 \x01(\rR\beventSeq\x12*\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x16.mvp.TimelineEventTypeR\x04type\x12\f\n" +
+	"\x01a\x18\n" +
+	" \x01(\x04R\x01a\x12\f\n" +
+	"\x01b\x18\v \x01(\x04R\x01b\x12\f\n" +
+	"\x01x\x18\f \x01(\x11R\x01x\x12\f\n" +
+	"\x01y\x18\r \x01(\x11R\x01y\x12\f\n" +
+	"\x01u\x18\x0e \x01(\rR\x01u\x12\f\n" +
+	"\x01v\x18\x0f \x01(\rR\x01v\x12\f\n" +
+	"\x01s\x18\x10 \x01(\tR\x01s\"\xa2\x01\n" +
+	"\x15BattleOutcomeTimeline\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\x04R\bbattleId\x12\x19\n" +
+	"\bturn_seq\x18\x02 \x01(\rR\aturnSeq\x12*\n" +
+	"\x06events\x18\x03 \x03(\v2\x12.mvp.TimelineEventR\x06events\x12%\n" +
+	"\x0eboard_snapshot\x18\x04 \x01(\fR\rboardSnapshot\"\x89\x01\n" +
+	"\tBattleEnd\x12\x1b\n" +
+	"\tbattle_id\x18\x01 \x01(\x04R\bbattleId\x12(\n" +
+	"\x10winner_player_id\x18\x02 \x01(\x04R\x0ewinnerPlayerId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\rR\x06reason\x12\x1d\n" +
+	"\n" +
+	"xp_awarded\x18\x04 \x01(\rR\txpAwarded*H\n" +
+	"\tElementId\x12\t\n" +
+	"\x05WATER\x10\x00\x12\b\n" +
+	"\x04FIRE\x10\x01\x12\t\n" +
+	"\x05EARTH\x10\x02\x12\f\n" +
+	"\bAIR_WIND\x10\x03\x12\r\n" +
+	"\tLIGHTNING\x10\x04*\xe2\x01\n" +
+	"\x06ItemId\x12\x17\n" +
+	"\x13ITEM_ID_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aITEM_MULTITASKERS_SCHEDULE\x10\x01\x12\x18\n" +
+	"\x14ITEM_POISONED_DAGGER\x10\x02\x12\x1b\n" +
+	"\x17ITEM_DUAL_ADEPTS_GLOVES\x10\x03\x12\x1d\n" +
+	"\x19ITEM_TRIPLE_ADEPTS_GLOVES\x10\x04\x12\x18\n" +
+	"\x14ITEM_HEADMASTER_RING\x10\x05\x12\x16\n" +
+	"\x12ITEM_POT_OF_HUNGER\x10\x06\x12\x17\n" +
+	"\x13ITEM_SOLAR_NECKLACE\x10\a*\xe4\x01\n" +
+	"\tAbilityId\x12\x1a\n" +
+	"\x16ABILITY_ID_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12ABILITY_BLOCK_PATH\x10\x01\x12\x14\n" +
+	"\x10ABILITY_STALWART\x10\x02\x12\x17\n" +
+	"\x13ABILITY_BELLIGERENT\x10\x03\x12\x10\n" +
+	"\fABILITY_REDO\x10\x04\x12\x17\n" +
+	"\x13ABILITY_DOUBLE_KILL\x10\x05\x12\x18\n" +
+	"\x14ABILITY_QUANTUM_KILL\x10\x06\x12\x16\n" +
+	"\x12ABILITY_CHAIN_KILL\x10\a\x12\x17\n" +
+	"\x13ABILITY_NECROMANCER\x10\b*\"\n" +
+	"\x04Dir4\x12\x05\n" +
+	"\x01N\x10\x00\x12\x05\n" +
+	"\x01E\x10\x01\x12\x05\n" +
+	"\x01S\x10\x02\x12\x05\n" +
+	"\x01W\x10\x03*,\n" +
+	"\x10BattleActionType\x12\b\n" +
+	"\x04MOVE\x10\x00\x12\x0e\n" +
+	"\n" +
+	"CHAIN_KILL\x10\x01*\xb3\x01\n" +
+	"\x11TimelineEventType\x12\v\n" +
+	"\aEV_MOVE\x10\x00\x12\x0e\n" +
+	"\n" +
+	"EV_CAPTURE\x10\x01\x12\x14\n" +
+	"\x10EV_EXTRA_CAPTURE\x10\x02\x12\x15\n" +
+	"\x11EV_BLOCK_PATH_SET\x10\x03\x12\x15\n" +
+	"\x11EV_ABILITY_FIZZLE\x10\x04\x12\x12\n" +
+	"\x0eEV_REDO_REWIND\x10\x05\x12\x15\n" +
+	"\x11EV_PIECE_RESTORED\x10\x06\x12\x12\n" +
+	"\x0eEV_MATCH_STATE\x10\aB-Z+example.com/mvp-repo/internal/proto/gen;genb\x06proto3"
+
+var (
+	file_game_proto_rawDescOnce sync.Once
+	file_game_proto_rawDescData []byte
+)
+
+func file_game_proto_rawDescGZIP() []byte {
+	file_game_proto_rawDescOnce.Do(func() {
+		file_game_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_game_proto_rawDesc), len(file_game_proto_rawDesc)))
+	})
+	return file_game_proto_rawDescData
+}
+
+var file_game_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_game_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_game_proto_goTypes = []any{
+	(ElementId)(0),                // 0: mvp.ElementId
+	(ItemId)(0),                   // 1: mvp.ItemId
+	(AbilityId)(0),                // 2: mvp.AbilityId
+	(Dir4)(0),                     // 3: mvp.Dir4
+	(BattleActionType)(0),         // 4: mvp.BattleActionType
+	(TimelineEventType)(0),        // 5: mvp.TimelineEventType
+	(*Hello)(nil),                 // 6: mvp.Hello
+	(*Welcome)(nil),               // 7: mvp.Welcome
+	(*Ping)(nil),                  // 8: mvp.Ping
+	(*Pong)(nil),                  // 9: mvp.Pong
+	(*ServerShutdown)(nil),        // 10: mvp.ServerShutdown
+	(*Error)(nil),                 // 11: mvp.Error
+	(*WorldMoveIntent)(nil),       // 12: mvp.WorldMoveIntent
+	(*ChatSend)(nil),              // 13: mvp.ChatSend
+	(*ChatEvent)(nil),             // 14: mvp.ChatEvent
+	(*WorldEntity)(nil),           // 15: mvp.WorldEntity
+	(*WorldSnapshot)(nil),         // 16: mvp.WorldSnapshot
+	(*WorldDelta)(nil),            // 17: mvp.WorldDelta
+	(*BattleStart)(nil),           // 18: mvp.BattleStart
+	(*SolarTopUp)(nil),            // 19: mvp.SolarTopUp
+	(*BattleTurnInput)(nil),       // 20: mvp.BattleTurnInput
+	(*TimelineEvent)(nil),         // 21: mvp.TimelineEvent
+	(*BattleOutcomeTimeline)(nil), // 22: mvp.BattleOutcomeTimeline
+	(*BattleEnd)(nil),             // 23: mvp.BattleEnd
+}
+var file_game_proto_depIdxs = []int32{
+	15, // 0: mvp.WorldSnapshot.entities:type_name -> mvp.WorldEntity
+	15, // 1: mvp.WorldDelta.upserts:type_name -> mvp.WorldEntity
+	0,  // 2: mvp.BattleStart.element_self:type_name -> mvp.ElementId
+	0,  // 3: mvp.BattleStart.element_opp:type_name -> mvp.ElementId
+	4,  // 4: mvp.BattleTurnInput.action_type:type_name -> mvp.BattleActionType
+	19, // 5: mvp.BattleTurnInput.solar_topup:type_name -> mvp.SolarTopUp
+	5,  // 6: mvp.TimelineEvent.type:type_name -> mvp.TimelineEventType
+	21, // 7: mvp.BattleOutcomeTimeline.events:type_name -> mvp.TimelineEvent
+	8,  // [8:8] is the sub-list for method output_type
+	8,  // [8:8] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_game_proto_init() }
+func file_game_proto_init() {
+	if File_game_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_game_proto_rawDesc), len(file_game_proto_rawDesc)),
+			NumEnums:      6,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_game_proto_goTypes,
+		DependencyIndexes: file_game_proto_depIdxs,
+		EnumInfos:         file_game_proto_enumTypes,
+		MessageInfos:      file_game_proto_msgTypes,
+	}.Build()
+	File_game_proto = out.File
+	file_game_proto_goTypes = nil
+	file_game_proto_depIdxs = nil
+}
@@ -0,0 +1,184 @@
+package protocol
+
+import (
+	"strings"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"example.com/mvp-repo/internal/proto/gen"
+)
+
+// canonEnums lists the canon constant behind every proto enum value, keyed by
+// enum name and then proto value name.
+var canonEnums = map[protoreflect.Name]map[protoreflect.Name]int{
+	"ElementId": {
+		"WATER":     int(ELEMENT_WATER),
+		"FIRE":      int(ELEMENT_FIRE),
+		"EARTH":     int(ELEMENT_EARTH),
+		"AIR_WIND":  int(ELEMENT_AIR_WIND),
+		"LIGHTNING": int(ELEMENT_LIGHTNING),
+	},
+	"ItemId": {
+		"ITEM_MULTITASKERS_SCHEDULE": int(ITEM_MULTITASKERS_SCHEDULE),
+		"ITEM_POISONED_DAGGER":       int(ITEM_POISONED_DAGGER),
+		"ITEM_DUAL_ADEPTS_GLOVES":    int(ITEM_DUAL_ADEPTS_GLOVES),
+		"ITEM_TRIPLE_ADEPTS_GLOVES":  int(ITEM_TRIPLE_ADEPTS_GLOVES),
+		"ITEM_HEADMASTER_RING":       int(ITEM_HEADMASTER_RING),
+		"ITEM_POT_OF_HUNGER":         int(ITEM_POT_OF_HUNGER),
+		"ITEM_SOLAR_NECKLACE":        int(ITEM_SOLAR_NECKLACE),
+	},
+	"AbilityId": {
+		"ABILITY_BLOCK_PATH":   int(ABILITY_BLOCK_PATH),
+		"ABILITY_STALWART":     int(ABILITY_STALWART),
+		"ABILITY_BELLIGERENT":  int(ABILITY_BELLIGERENT),
+		"ABILITY_REDO":         int(ABILITY_REDO),
+		"ABILITY_DOUBLE_KILL":  int(ABILITY_DOUBLE_KILL),
+		"ABILITY_QUANTUM_KILL": int(ABILITY_QUANTUM_KILL),
+		"ABILITY_CHAIN_KILL":   int(ABILITY_CHAIN_KILL),
+		"ABILITY_NECROMANCER":  int(ABILITY_NECROMANCER),
+	},
+	"Dir4": {
+		"N": int(DIR_N),
+		"E": int(DIR_E),
+		"S": int(DIR_S),
+		"W": int(DIR_W),
+	},
+	"BattleActionType": {
+		"MOVE":       int(BAT_ACT_MOVE),
+		"CHAIN_KILL": int(BAT_ACT_CHAIN_KILL),
+	},
+	"TimelineEventType": {
+		"EV_MOVE":           int(EV_MOVE),
+		"EV_CAPTURE":        int(EV_CAPTURE),
+		"EV_EXTRA_CAPTURE":  int(EV_EXTRA_CAPTURE),
+		"EV_BLOCK_PATH_SET": int(EV_BLOCK_PATH_SET),
+		"EV_ABILITY_FIZZLE": int(EV_ABILITY_FIZZLE),
+		"EV_REDO_REWIND":    int(EV_REDO_REWIND),
+		"EV_PIECE_RESTORED": int(EV_PIECE_RESTORED),
+		"EV_MATCH_STATE":    int(EV_MATCH_STATE),
+	},
+}
+
+func TestProtoEnumsMatchCanon(t *testing.T) {
+	enums := gen.File_game_proto.Enums()
+	seen := make(map[protoreflect.Name]bool, enums.Len())
+	for i := 0; i < enums.Len(); i++ {
+		enum := enums.Get(i)
+		seen[enum.Name()] = true
+		canon, ok := canonEnums[enum.Name()]
+		if !ok {
+			t.Errorf("proto enum %s has no canon constants", enum.Name())
+			continue
+		}
+		values := enum.Values()
+		// Proto to canon: every value, bar the proto3 zero placeholder, is a
+		// canon constant with the same number.
+		for j := 0; j < values.Len(); j++ {
+			v := values.Get(j)
+			want, ok := canon[v.Name()]
+			if !ok {
+				if v.Number() == 0 && strings.HasSuffix(string(v.Name()), "_UNSPECIFIED") {
+					continue
+				}
+				t.Errorf("%s.%s = %d has no canon constant", enum.Name(), v.Name(), v.Number())
+				continue
+			}
+			if int(v.Number()) != want {
+				t.Errorf("%s.%s = %d, canon constant is %d", enum.Name(), v.Name(), v.Number(), want)
+			}
+		}
+		// Canon to proto: no canon constant is missing from the schema.
+		for name := range canon {
+			if values.ByName(name) == nil {
+				t.Errorf("canon constant for %s.%s is not in the proto enum", enum.Name(), name)
+			}
+		}
+	}
+	for name := range canonEnums {
+		if !seen[name] {
+			t.Errorf("canon enum %s is not in the proto file", name)
+		}
+	}
+}
+
+// TestMsgTypesMatchProtoMessages checks the registry against the schema:
+// every MsgType but the MSG_BATCH envelope has a payload message named after
+// it (MSG_WORLD_MOVE_INTENT <-> WorldMoveIntent), and every proto message
+// whose name maps to a MsgType is registered for that type.
+func TestMsgTypesMatchProtoMessages(t *testing.T) {
+	for msgType, name := range msgTypeNames {
+		if msgType == MSG_BATCH {
+			continue
+		}
+		msg, ok := NewMessage(msgType)
+		if !ok {
+			t.Errorf("%s has no payload message", name)
+			continue
+		}
+		desc := msg.ProtoReflect().Descriptor()
+		if got := "MSG_" + upperSnake(string(desc.Name())); got != name {
+			t.Errorf("%s is registered as %s", name, desc.Name())
+		}
+		if desc.ParentFile() != gen.File_game_proto {
+			t.Errorf("%s message %s is not from game.proto", name, desc.FullName())
+		}
+	}
+
+	messages := gen.File_game_proto.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		desc := messages.Get(i)
+		msgType, ok := ParseMsgType("MSG_" + upperSnake(string(desc.Name())))
+		if !ok {
+			// Embedded types such as WorldEntity are not payloads.
+			continue
+		}
+		if got, ok := msgTypeByName[desc.FullName()]; !ok || got != msgType {
+			t.Errorf("proto message %s is not registered as %s", desc.Name(), msgType)
+		}
+	}
+}
+
+// TestErrorCodesFitProtoError checks ErrorCode against Error.code, which the
+// schema carries as a plain uint32 (DECISION 0015) rather than an enum: every
+// code survives the wire unchanged and names map one to one onto codes.
+func TestErrorCodesFitProtoError(t *testing.T) {
+	field := (&gen.Error{}).ProtoReflect().Descriptor().Fields().ByName("code")
+	if field == nil || field.Kind() != protoreflect.Uint32Kind {
+		t.Fatalf("Error.code is %v, want uint32 to carry protocol.ErrorCode", field)
+	}
+	byName := make(map[string]ErrorCode, len(errorCodeNames))
+	for code, name := range errorCodeNames {
+		if other, dup := byName[name]; dup {
+			t.Errorf("%s names both %d and %d", name, other, code)
+		}
+		byName[name] = code
+		if code.String() != name {
+			t.Errorf("ErrorCode(%d).String() = %q, want %q", code, code.String(), name)
+		}
+		raw, err := proto.Marshal(&gen.Error{Code: uint32(code)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var decoded gen.Error
+		if err := proto.Unmarshal(raw, &decoded); err != nil {
+			t.Fatal(err)
+		}
+		if ErrorCode(decoded.GetCode()) != code {
+			t.Errorf("%s decoded as %d", name, decoded.GetCode())
+		}
+	}
+}
+
+// upperSnake turns a CamelCase message name into UPPER_SNAKE_CASE.
+func upperSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
@@ -0,0 +1,81 @@
+package protocol
+
+import "strconv"
+
+// ErrorCode values are carried in Error.code (MSG_ERROR). Codes below 100 are
+// protocol violations: the server closes the connection after them. Codes from
+// 100 up are rejections of a single request; the connection stays open.
+// Mapping is ledgered in DECISION 0015; values are append-only.
+type ErrorCode uint32
+
+const (
+	ERR_UNSPECIFIED ErrorCode = 0
+
+	// Protocol violations (connection is closed).
+	ERR_MALFORMED           ErrorCode = 1
+	ERR_UNKNOWN_MSG         ErrorCode = 2
+	ERR_UNAUTHENTICATED     ErrorCode = 3
+	ERR_RATE_LIMITED        ErrorCode = 4
+	ERR_INTERNAL            ErrorCode = 5
+	ERR_UNSUPPORTED_VERSION ErrorCode = 6
+
+	// Generic rejections.
+	ERR_UNIMPLEMENTED ErrorCode = 100
+	ERR_UNAVAILABLE   ErrorCode = 101
+	ERR_SERVER_ERROR  ErrorCode = 102
+	ERR_IN_BATTLE     ErrorCode = 103
+
+	// Chat rejections.
+	ERR_CHAT_EMPTY        ErrorCode = 200
+	ERR_CHAT_TOO_LONG     ErrorCode = 201
+	ERR_CHAT_INVALID_TEXT ErrorCode = 202
+	ERR_CHAT_REJECTED     ErrorCode = 203
+	ERR_CHAT_MUTED        ErrorCode = 204
+
+	// World rejections.
+	ERR_WORLD_INVALID_MOVE ErrorCode = 300
+	ERR_WORLD_BLOCKED      ErrorCode = 301
+
+	// Battle rejections.
+	ERR_BATTLE_NOT_FOUND      ErrorCode = 400
+	ERR_BATTLE_NOT_YOUR_TURN  ErrorCode = 401
+	ERR_BATTLE_STALE_TURN     ErrorCode = 402
+	ERR_BATTLE_ILLEGAL_ACTION ErrorCode = 403
+)
+
+var errorCodeNames = map[ErrorCode]string{
+	ERR_UNSPECIFIED:           "ERR_UNSPECIFIED",
+	ERR_MALFORMED:             "ERR_MALFORMED",
+	ERR_UNKNOWN_MSG:           "ERR_UNKNOWN_MSG",
+	ERR_UNAUTHENTICATED:       "ERR_UNAUTHENTICATED",
+	ERR_RATE_LIMITED:          "ERR_RATE_LIMITED",
+	ERR_INTERNAL:              "ERR_INTERNAL",
+	ERR_UNSUPPORTED_VERSION:   "ERR_UNSUPPORTED_VERSION",
+	ERR_UNIMPLEMENTED:         "ERR_UNIMPLEMENTED",
+	ERR_UNAVAILABLE:           "ERR_UNAVAILABLE",
+	ERR_SERVER_ERROR:          "ERR_SERVER_ERROR",
+	ERR_IN_BATTLE:             "ERR_IN_BATTLE",
+	ERR_CHAT_EMPTY:            "ERR_CHAT_EMPTY",
+	ERR_CHAT_TOO_LONG:         "ERR_CHAT_TOO_LONG",
+	ERR_CHAT_INVALID_TEXT:     "ERR_CHAT_INVALID_TEXT",
+	ERR_CHAT_REJECTED:         "ERR_CHAT_REJECTED",
+	ERR_CHAT_MUTED:            "ERR_CHAT_MUTED",
+	ERR_WORLD_INVALID_MOVE:    "ERR_WORLD_INVALID_MOVE",
+	ERR_WORLD_BLOCKED:         "ERR_WORLD_BLOCKED",
+	ERR_BATTLE_NOT_FOUND:      "ERR_BATTLE_NOT_FOUND",
+	ERR_BATTLE_NOT_YOUR_TURN:  "ERR_BATTLE_NOT_YOUR_TURN",
+	ERR_BATTLE_STALE_TURN:     "ERR_BATTLE_STALE_TURN",
+	ERR_BATTLE_ILLEGAL_ACTION: "ERR_BATTLE_ILLEGAL_ACTION",
+}
+
+func (c ErrorCode) String() string {
+	if name, ok := errorCodeNames[c]; ok {
+		return name
+	}
+	return "ERR_UNKNOWN(" + strconv.Itoa(int(c)) + ")"
+}
+
+// Fatal reports whether the code denotes a protocol violation.
+func (c ErrorCode) Fatal() bool {
+	return c != ERR_UNSPECIFIED && c < ERR_UNIMPLEMENTED
+}
@@ -1,5 +1,7 @@
 package protocol
 
+import "strconv"
+
 // MsgType values are the canonical u16 values used in the wire frame header.
 // These MUST match the Protocol Contract — CONSOLIDATED msg_type table.
 type MsgType uint16
@@ -11,6 +13,8 @@ const (
 	MSG_PING MsgType = 3
 	MSG_PONG MsgType = 4
 
+	MSG_SERVER_SHUTDOWN MsgType = 5
+
 	MSG_WORLD_MOVE_INTENT MsgType = 10
 	MSG_WORLD_SNAPSHOT    MsgType = 11
 	MSG_WORLD_DELTA       MsgType = 12
@@ -24,4 +28,44 @@ const (
 	MSG_BATTLE_END              MsgType = 33
 
 	MSG_ERROR MsgType = 250
+
+	// MSG_BATCH wraps several frames in one WebSocket message; equals
+	// frame.BatchType. Unwrapped by the gateway, never dispatched.
+	MSG_BATCH MsgType = 0xFFFF
 )
+
+var msgTypeNames = map[MsgType]string{
+	MSG_HELLO:                   "MSG_HELLO",
+	MSG_WELCOME:                 "MSG_WELCOME",
+	MSG_PING:                    "MSG_PING",
+	MSG_PONG:                    "MSG_PONG",
+	MSG_SERVER_SHUTDOWN:         "MSG_SERVER_SHUTDOWN",
+	MSG_WORLD_MOVE_INTENT:       "MSG_WORLD_MOVE_INTENT",
+	MSG_WORLD_SNAPSHOT:          "MSG_WORLD_SNAPSHOT",
+	MSG_WORLD_DELTA:             "MSG_WORLD_DELTA",
+	MSG_CHAT_SEND:               "MSG_CHAT_SEND",
+	MSG_CHAT_EVENT:              "MSG_CHAT_EVENT",
+	MSG_BATTLE_START:            "MSG_BATTLE_START",
+	MSG_BATTLE_TURN_INPUT:       "MSG_BATTLE_TURN_INPUT",
+	MSG_BATTLE_OUTCOME_TIMELINE: "MSG_BATTLE_OUTCOME_TIMELINE",
+	MSG_BATTLE_END:              "MSG_BATTLE_END",
+	MSG_ERROR:                   "MSG_ERROR",
+	MSG_BATCH:                   "MSG_BATCH",
+}
+
+func (t MsgType) String() string {
+	if name, ok := msgTypeNames[t]; ok {
+		return name
+	}
+	return "MSG_UNKNOWN(" + strconv.Itoa(int(t)) + ")"
+}
+
+// ParseMsgType resolves a canonical constant name (e.g. "MSG_CHAT_SEND").
+func ParseMsgType(name string) (MsgType, bool) {
+	for t, n := range msgTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,25 @@
+package protocoltest
+
+import (
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+func TestSamplesDecode(t *testing.T) {
+	samples := Samples()
+	if len(samples) == 0 {
+		t.Fatal("no samples")
+	}
+	for _, s := range samples {
+		msg, _ := protocol.NewMessage(s.MsgType)
+		if err := proto.Unmarshal(s.Payload, msg); err != nil {
+			t.Errorf("%s: %v", s.MsgType, err)
+		}
+		if len(s.Payload) == 0 && s.MsgType != protocol.MSG_PING && s.MsgType != protocol.MSG_PONG {
+			t.Errorf("%s: empty sample payload", s.MsgType)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package protocol
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"example.com/mvp-repo/internal/proto/gen"
+)
+
+// messageFactories maps each msg type to its payload message in proto/game.proto.
+var messageFactories = map[MsgType]func() proto.Message{
+	MSG_HELLO:                   func() proto.Message { return new(gen.Hello) },
+	MSG_WELCOME:                 func() proto.Message { return new(gen.Welcome) },
+	MSG_PING:                    func() proto.Message { return new(gen.Ping) },
+	MSG_PONG:                    func() proto.Message { return new(gen.Pong) },
+	MSG_SERVER_SHUTDOWN:         func() proto.Message { return new(gen.ServerShutdown) },
+	MSG_WORLD_MOVE_INTENT:       func() proto.Message { return new(gen.WorldMoveIntent) },
+	MSG_WORLD_SNAPSHOT:          func() proto.Message { return new(gen.WorldSnapshot) },
+	MSG_WORLD_DELTA:             func() proto.Message { return new(gen.WorldDelta) },
+	MSG_CHAT_SEND:               func() proto.Message { return new(gen.ChatSend) },
+	MSG_CHAT_EVENT:              func() proto.Message { return new(gen.ChatEvent) },
+	MSG_BATTLE_START:            func() proto.Message { return new(gen.BattleStart) },
+	MSG_BATTLE_TURN_INPUT:       func() proto.Message { return new(gen.BattleTurnInput) },
+	MSG_BATTLE_OUTCOME_TIMELINE: func() proto.Message { return new(gen.BattleOutcomeTimeline) },
+	MSG_BATTLE_END:              func() proto.Message { return new(gen.BattleEnd) },
+	MSG_ERROR:                   func() proto.Message { return new(gen.Error) },
+}
+
+var msgTypeByName = func() map[protoreflect.FullName]MsgType {
+	out := make(map[protoreflect.FullName]MsgType, len(messageFactories))
+	for t, newMsg := range messageFactories {
+		out[newMsg().ProtoReflect().Descriptor().FullName()] = t
+	}
+	return out
+}()
+
+// NewMessage returns an empty payload message for t.
+func NewMessage(t MsgType) (proto.Message, bool) {
+	newMsg, ok := messageFactories[t]
+	if !ok {
+		return nil, false
+	}
+	return newMsg(), true
+}
+
+// MsgTypeOf returns the msg type that carries msg on the wire.
+func MsgTypeOf(msg proto.Message) (MsgType, bool) {
+	t, ok := msgTypeByName[msg.ProtoReflect().Descriptor().FullName()]
+	return t, ok
+}
@@ -0,0 +1,76 @@
+package protocol
+
+// ProtocolVersion is the version this server speaks. Version 1 is the
+// original handshake (empty Hello); version 2 adds negotiation fields to
+// Hello/Welcome. Bump on any incompatible wire change (DECISION 0017).
+const (
+	ProtocolVersion    uint32 = 2
+	MinProtocolVersion uint32 = 1
+)
+
+// EffectiveVersion maps the zero value sent by pre-negotiation clients to 1.
+func EffectiveVersion(v uint32) uint32 {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
+
+// Features is a set of optional protocol features. Server-to-client optional
+// messages are only sent when the feature was negotiated in Hello/Welcome.
+type Features uint64
+
+const (
+	// FEATURE_SERVER_SHUTDOWN: client understands MSG_SERVER_SHUTDOWN.
+	FEATURE_SERVER_SHUTDOWN Features = 1 << iota
+	// FEATURE_ERROR_CODES: client understands the DECISION 0015 Error.code table.
+	FEATURE_ERROR_CODES
+	// FEATURE_BATCH: both directions may pack several frames into one
+	// MSG_BATCH envelope (DECISION 0018).
+	FEATURE_BATCH
+)
+
+var featureNames = map[Features]string{
+	FEATURE_SERVER_SHUTDOWN: "server_shutdown",
+	FEATURE_ERROR_CODES:     "error_codes",
+	FEATURE_BATCH:           "batch",
+}
+
+func (f Features) Has(feature Features) bool {
+	return f&feature == feature
+}
+
+// Names returns the wire names of the features in f.
+func (f Features) Names() []string {
+	var out []string
+	for bit := Features(1); bit != 0 && bit <= f; bit <<= 1 {
+		if f&bit == 0 {
+			continue
+		}
+		if name, ok := featureNames[bit]; ok {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// ParseFeatures resolves wire names. Unknown names are returned separately so
+// newer clients can offer features this server does not know.
+func ParseFeatures(names []string) (Features, []string) {
+	var f Features
+	var unknown []string
+	for _, name := range names {
+		found := false
+		for bit, n := range featureNames {
+			if n == name {
+				f |= bit
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, name)
+		}
+	}
+	return f, unknown
+}
@@ -1,6 +1,10 @@
 package router
 
-import "example.com/mvp-repo/internal/protocol"
+import (
+	"context"
+
+	"example.com/mvp-repo/internal/protocol"
+)
 
 type AuthHandler interface {
 	HandleHello(ctx Context, payload []byte) error
@@ -18,6 +22,20 @@ type BattleHandler interface {
 	HandleTurnInput(ctx Context, payload []byte) error
 }
 
+// SessionHandler observes a connection becoming bound (after a successful
+// HELLO) and unbound (on disconnect).
+type SessionHandler interface {
+	HandleBind(ctx Context) error
+	HandleUnbind(ctx Context)
+}
+
+// DrainHandler lets a module finish or checkpoint in-flight work (e.g.
+// battles) before the gateway closes connections. ctx carries the drain
+// deadline.
+type DrainHandler interface {
+	HandleDrain(ctx context.Context) error
+}
+
 func (r *Router) RegisterAuth(handler AuthHandler) {
 	if handler == nil {
 		return
@@ -45,3 +63,17 @@ func (r *Router) RegisterBattle(handler BattleHandler) {
 	}
 	r.Register(protocol.MSG_BATTLE_TURN_INPUT, handler.HandleTurnInput)
 }
+
+func (r *Router) RegisterSession(handler SessionHandler) {
+	if handler == nil {
+		return
+	}
+	r.sessions = append(r.sessions, handler)
+}
+
+func (r *Router) RegisterDrain(handler DrainHandler) {
+	if handler == nil {
+		return
+	}
+	r.drains = append(r.drains, handler)
+}
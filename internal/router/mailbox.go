@@ -0,0 +1,197 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+var (
+	ErrMailboxFull   = errors.New("router: mailbox full")
+	ErrMailboxClosed = errors.New("router: mailbox closed")
+)
+
+// KeyFunc picks the ordering key for a message. Messages with equal keys run
+// serially in arrival order.
+type KeyFunc func(ctx Context, payload []byte) uint64
+
+// PlayerKey orders messages per player.
+func PlayerKey(ctx Context, payload []byte) uint64 {
+	_ = payload
+	return ctx.PlayerID
+}
+
+type MailboxConfig struct {
+	// Shards is the number of worker goroutines; 1 gives the subsystem a
+	// single owner goroutine.
+	Shards int
+	// QueueSize bounds each shard's queue.
+	QueueSize int
+	// Key defaults to PlayerKey.
+	Key KeyFunc
+}
+
+type envelope struct {
+	ctx     Context
+	payload []byte
+	handler Handler
+}
+
+// Mailbox moves handler execution off the connection's read goroutine into
+// per-shard workers with bounded queues. A full shard rejects the message
+// with ERR_UNAVAILABLE instead of stalling the reader.
+type Mailbox struct {
+	name   string
+	key    KeyFunc
+	shards []chan envelope
+
+	mu     sync.RWMutex
+	closed bool
+
+	posted   atomic.Uint64
+	rejected atomic.Uint64
+}
+
+func NewMailbox(name string, cfg MailboxConfig) *Mailbox {
+	if cfg.Shards <= 0 {
+		cfg.Shards = 1
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1
+	}
+	if cfg.Key == nil {
+		cfg.Key = PlayerKey
+	}
+	m := &Mailbox{
+		name:   name,
+		key:    cfg.Key,
+		shards: make([]chan envelope, cfg.Shards),
+	}
+	for i := range m.shards {
+		m.shards[i] = make(chan envelope, cfg.QueueSize)
+	}
+	return m
+}
+
+// Post enqueues handler for asynchronous execution without blocking.
+func (m *Mailbox) Post(ctx Context, handler Handler, payload []byte) error {
+	env := envelope{ctx: ctx, payload: append([]byte(nil), payload...), handler: handler}
+	shard := m.shards[m.key(ctx, payload)%uint64(len(m.shards))]
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.closed {
+		m.rejected.Add(1)
+		return Reject(protocol.ERR_UNAVAILABLE, ErrMailboxClosed)
+	}
+	select {
+	case shard <- env:
+		m.posted.Add(1)
+		return nil
+	default:
+		m.rejected.Add(1)
+		return Reject(protocol.ERR_UNAVAILABLE, ErrMailboxFull)
+	}
+}
+
+// Run starts one worker per shard and blocks until ctx is done. Messages
+// already queued are still handled before Run returns.
+func (m *Mailbox) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	for _, shard := range m.shards {
+		wg.Add(1)
+		go func(shard chan envelope) {
+			defer wg.Done()
+			for env := range shard {
+				m.exec(env)
+			}
+		}(shard)
+	}
+	<-ctx.Done()
+	m.mu.Lock()
+	m.closed = true
+	for _, shard := range m.shards {
+		close(shard)
+	}
+	m.mu.Unlock()
+	wg.Wait()
+	return nil
+}
+
+// Drain handles up to max queued messages on the caller's goroutine and
+// returns how many ran. It lets a loop that owns the subsystem's state (for
+// example the world tick) consume its mailbox instead of calling Run.
+func (m *Mailbox) Drain(max int) int {
+	n := 0
+	for _, shard := range m.shards {
+		for n < max {
+			select {
+			case env, ok := <-shard:
+				if !ok {
+					return n
+				}
+				m.exec(env)
+				n++
+				continue
+			default:
+			}
+			break
+		}
+	}
+	return n
+}
+
+// Depth returns the number of queued messages across shards.
+func (m *Mailbox) Depth() int {
+	n := 0
+	for _, shard := range m.shards {
+		n += len(shard)
+	}
+	return n
+}
+
+func (m *Mailbox) Snapshot() map[string]any {
+	return map[string]any{
+		"depth":    m.Depth(),
+		"posted":   m.posted.Load(),
+		"rejected": m.rejected.Load(),
+	}
+}
+
+// exec runs one message and applies the same outcome rules as inline
+// dispatch: rejections on bound sessions are answered with MSG_ERROR, any
+// other error closes the connection.
+func (m *Mailbox) exec(env envelope) {
+	err := env.handler(env.ctx, env.payload)
+	if err == nil {
+		return
+	}
+	if rej, ok := asRejection(err); ok && env.ctx.Bound {
+		if err := rej.reply(env.ctx); err == nil {
+			return
+		}
+	}
+	slog.Info("router: mailbox handler closed connection", "mailbox", m.name, "player", env.ctx.PlayerID, "msg_type", env.ctx.MsgType.String(), "err", err)
+	if env.ctx.Sender != nil {
+		_ = env.ctx.Sender.Close(closeReason(err))
+	}
+}
+
+// closeReason names the ErrorCode behind err for the WebSocket close frame.
+func closeReason(err error) string {
+	var rej *Rejection
+	switch {
+	case errors.As(err, &rej):
+		return rej.Code.String()
+	case errors.Is(err, ErrUnauthenticated):
+		return protocol.ERR_UNAUTHENTICATED.String()
+	case errors.Is(err, ErrMalformedPayload):
+		return protocol.ERR_MALFORMED.String()
+	default:
+		return protocol.ERR_INTERNAL.String()
+	}
+}
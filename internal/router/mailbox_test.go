@@ -0,0 +1,175 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// runMailbox starts mb.Run and returns a func that stops it and waits.
+func runMailbox(mb *Mailbox) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = mb.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func TestMailboxOrdersPerKey(t *testing.T) {
+	mb := NewMailbox("test", MailboxConfig{Shards: 3, QueueSize: 1000})
+	var mu sync.Mutex
+	seen := make(map[uint64][]int)
+	handler := func(ctx Context, payload []byte) error {
+		mu.Lock()
+		seen[ctx.PlayerID] = append(seen[ctx.PlayerID], int(payload[0]))
+		mu.Unlock()
+		return nil
+	}
+	stop := runMailbox(mb)
+	const perKey = 200
+	for i := range perKey {
+		for player := uint64(1); player <= 7; player++ {
+			if err := mb.Post(Context{PlayerID: player}, handler, []byte{byte(i)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	stop()
+
+	for player := uint64(1); player <= 7; player++ {
+		got := seen[player]
+		if len(got) != perKey {
+			t.Fatalf("player %d: %d messages handled, want %d", player, len(got), perKey)
+		}
+		for i, v := range got {
+			if v != i%256 {
+				t.Fatalf("player %d: message %d handled at position %d", player, v, i)
+			}
+		}
+	}
+}
+
+func TestMailboxFullAndClosedAreUnavailable(t *testing.T) {
+	mb := NewMailbox("test", MailboxConfig{Shards: 1, QueueSize: 2})
+	noop := func(Context, []byte) error { return nil }
+	for range 2 {
+		if err := mb.Post(Context{}, noop, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := mb.Post(Context{}, noop, nil)
+	var rej *Rejection
+	if !errors.As(err, &rej) || rej.Code != protocol.ERR_UNAVAILABLE || !errors.Is(err, ErrMailboxFull) {
+		t.Fatalf("Post to full shard = %v, want ERR_UNAVAILABLE (ErrMailboxFull)", err)
+	}
+
+	runMailbox(mb)()
+	err = mb.Post(Context{}, noop, nil)
+	if !errors.As(err, &rej) || rej.Code != protocol.ERR_UNAVAILABLE || !errors.Is(err, ErrMailboxClosed) {
+		t.Fatalf("Post after Run = %v, want ERR_UNAVAILABLE (ErrMailboxClosed)", err)
+	}
+	if snap := mb.Snapshot(); snap["posted"] != uint64(2) || snap["rejected"] != uint64(2) {
+		t.Fatalf("snapshot = %v, want 2 posted, 2 rejected", snap)
+	}
+}
+
+func TestMailboxFullRepliesThroughDispatch(t *testing.T) {
+	r := New()
+	mb := NewMailbox("test", MailboxConfig{Shards: 1, QueueSize: 1})
+	r.Register(protocol.MSG_CHAT_SEND, func(Context, []byte) error { return nil })
+	r.Route(protocol.MSG_CHAT_SEND, mb)
+	sender := &testSender{}
+	ctx := Context{Sender: sender, Bound: true}
+
+	for i := range 2 {
+		if err := r.Dispatch(ctx, protocol.MSG_CHAT_SEND, nil); err != nil {
+			t.Fatalf("Dispatch %d = %v", i, err)
+		}
+	}
+	_, errs, closed := sender.snapshot()
+	if len(errs) != 1 || protocol.ErrorCode(errs[0].GetCode()) != protocol.ERR_UNAVAILABLE || len(closed) != 0 {
+		t.Fatalf("replies %v, closes %v; want one ERR_UNAVAILABLE and no close", errs, closed)
+	}
+}
+
+func TestMailboxRunDrainsQueueOnShutdown(t *testing.T) {
+	mb := NewMailbox("test", MailboxConfig{Shards: 2, QueueSize: 10})
+	var mu sync.Mutex
+	handled := 0
+	handler := func(Context, []byte) error {
+		mu.Lock()
+		handled++
+		mu.Unlock()
+		return nil
+	}
+	for player := range uint64(12) {
+		if err := mb.Post(Context{PlayerID: player}, handler, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := mb.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if handled != 12 || mb.Depth() != 0 {
+		t.Fatalf("handled %d, depth %d after Run; want 12 and 0", handled, mb.Depth())
+	}
+}
+
+func TestMailboxExecOutcomes(t *testing.T) {
+	cause := errors.New("test: refused")
+	tests := []struct {
+		name      string
+		bound     bool
+		err       error
+		wantReply protocol.ErrorCode
+		wantClose string
+	}{
+		{name: "rejection on bound session", bound: true, err: Reject(protocol.ERR_WORLD_BLOCKED, cause), wantReply: protocol.ERR_WORLD_BLOCKED},
+		{name: "rejection before hello", err: Reject(protocol.ERR_WORLD_BLOCKED, cause), wantClose: "ERR_WORLD_BLOCKED"},
+		{name: "fatal rejection", bound: true, err: Reject(protocol.ERR_MALFORMED, cause), wantClose: "ERR_MALFORMED"},
+		{name: "unauthenticated", bound: true, err: ErrUnauthenticated, wantClose: "ERR_UNAUTHENTICATED"},
+		{name: "other error", bound: true, err: cause, wantClose: "ERR_INTERNAL"},
+		{name: "success", bound: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mb := NewMailbox("test", MailboxConfig{})
+			sender := &testSender{}
+			if err := mb.Post(Context{Sender: sender, Bound: tc.bound}, func(Context, []byte) error { return tc.err }, nil); err != nil {
+				t.Fatal(err)
+			}
+			if n := mb.Drain(10); n != 1 {
+				t.Fatalf("Drain ran %d, want 1", n)
+			}
+			_, errs, closed := sender.snapshot()
+			if tc.wantReply != 0 {
+				if len(errs) != 1 || protocol.ErrorCode(errs[0].GetCode()) != tc.wantReply || len(closed) != 0 {
+					t.Fatalf("replies %v, closes %v; want one %s and no close", errs, closed, tc.wantReply)
+				}
+				return
+			}
+			if len(errs) != 0 {
+				t.Fatalf("replied %v, want none", errs)
+			}
+			if tc.wantClose == "" {
+				if len(closed) != 0 {
+					t.Fatalf("closed %v, want open", closed)
+				}
+				return
+			}
+			if len(closed) != 1 || closed[0] != tc.wantClose {
+				t.Fatalf("closes %v, want [%s]", closed, tc.wantClose)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package router
+
+import "example.com/mvp-repo/internal/protocol"
+
+// RateClass groups message types that share a rate budget and outbound
+// priority.
+type RateClass uint8
+
+const (
+	RateControl RateClass = iota
+	RateWorld
+	RateChat
+	RateBattle
+)
+
+func (c RateClass) String() string {
+	switch c {
+	case RateWorld:
+		return "world"
+	case RateChat:
+		return "chat"
+	case RateBattle:
+		return "battle"
+	default:
+		return "control"
+	}
+}
+
+// ParseRateClass resolves a class name as returned by String (e.g. "chat").
+func ParseRateClass(name string) (RateClass, bool) {
+	for c := RateControl; c <= RateBattle; c++ {
+		if c.String() == name {
+			return c, true
+		}
+	}
+	return 0, false
+}
+
+// MsgMeta describes how a client-to-server message type may be used.
+type MsgMeta struct {
+	// RequiresAuth rejects the message on a connection that has not completed HELLO.
+	RequiresAuth bool
+	// AllowedInBattle permits the message while the player is in a battle.
+	AllowedInBattle bool
+	RateClass       RateClass
+}
+
+// defaultMsgMeta applies to registered types without an explicit entry.
+var defaultMsgMeta = MsgMeta{RequiresAuth: true, AllowedInBattle: true, RateClass: RateControl}
+
+func defaultMeta() map[protocol.MsgType]MsgMeta {
+	return map[protocol.MsgType]MsgMeta{
+		protocol.MSG_HELLO:             {RequiresAuth: false, AllowedInBattle: true, RateClass: RateControl},
+		protocol.MSG_PING:              {RequiresAuth: false, AllowedInBattle: true, RateClass: RateControl},
+		protocol.MSG_PONG:              {RequiresAuth: false, AllowedInBattle: true, RateClass: RateControl},
+		protocol.MSG_WORLD_MOVE_INTENT: {RequiresAuth: true, AllowedInBattle: false, RateClass: RateWorld},
+		protocol.MSG_CHAT_SEND:         {RequiresAuth: true, AllowedInBattle: true, RateClass: RateChat},
+		protocol.MSG_BATTLE_TURN_INPUT: {RequiresAuth: true, AllowedInBattle: true, RateClass: RateBattle},
+	}
+}
+
+// Meta returns the metadata for msgType.
+func (r *Router) Meta(msgType protocol.MsgType) MsgMeta {
+	if m, ok := r.meta[msgType]; ok {
+		return m
+	}
+	return defaultMsgMeta
+}
+
+// SetMeta overrides the metadata for msgType. Call before dispatching starts.
+func (r *Router) SetMeta(msgType protocol.MsgType, meta MsgMeta) {
+	r.meta[msgType] = meta
+}
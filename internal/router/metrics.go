@@ -0,0 +1,95 @@
+package router
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// latencyBuckets are the upper bounds of the dispatch latency histogram; a
+// final implicit bucket catches everything slower.
+var latencyBuckets = [...]time.Duration{
+	100 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+}
+
+type typeStats struct {
+	handled  atomic.Uint64
+	rejected atomic.Uint64
+	failed   atomic.Uint64
+	buckets  [len(latencyBuckets) + 1]atomic.Uint64
+}
+
+// Metrics counts dispatches per message type and records their latency.
+type Metrics struct {
+	types sync.Map // protocol.MsgType -> *typeStats
+}
+
+func (m *Metrics) stats(msgType protocol.MsgType) *typeStats {
+	if v, ok := m.types.Load(msgType); ok {
+		return v.(*typeStats)
+	}
+	v, _ := m.types.LoadOrStore(msgType, &typeStats{})
+	return v.(*typeStats)
+}
+
+// Middleware records every dispatch that reaches it.
+func (m *Metrics) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context, payload []byte) error {
+			start := time.Now()
+			err := next(ctx, payload)
+			elapsed := time.Since(start)
+			st := m.stats(ctx.MsgType)
+			var rej *Rejection
+			switch {
+			case err == nil:
+				st.handled.Add(1)
+			case errors.As(err, &rej) && !rej.Code.Fatal():
+				st.rejected.Add(1)
+			default:
+				st.failed.Add(1)
+			}
+			i := 0
+			for i < len(latencyBuckets) && elapsed > latencyBuckets[i] {
+				i++
+			}
+			st.buckets[i].Add(1)
+			return err
+		}
+	}
+}
+
+// Snapshot returns counters and latency buckets keyed by message type name,
+// suitable for expvar.
+func (m *Metrics) Snapshot() map[string]any {
+	out := make(map[string]any)
+	m.types.Range(func(k, v any) bool {
+		st := v.(*typeStats)
+		hist := make(map[string]uint64, len(st.buckets))
+		for i := range st.buckets {
+			label := "+Inf"
+			if i < len(latencyBuckets) {
+				label = latencyBuckets[i].String()
+			}
+			hist[label] = st.buckets[i].Load()
+		}
+		out[k.(protocol.MsgType).String()] = map[string]any{
+			"handled":  st.handled.Load(),
+			"rejected": st.rejected.Load(),
+			"failed":   st.failed.Load(),
+			"latency":  hist,
+		}
+		return true
+	})
+	return out
+}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+var ErrHandlerPanic = errors.New("router: handler panic")
+
+// Middleware wraps a Handler. ctx.MsgType identifies the message being
+// handled; use Router.Meta for its metadata.
+type Middleware func(Handler) Handler
+
+// Use appends middlewares to the stack. The first middleware added is the
+// outermost. Call before dispatching starts.
+func (r *Router) Use(mws ...Middleware) {
+	r.middlewares = append(r.middlewares, mws...)
+	for i, h := range r.handlers {
+		if h != nil {
+			r.chain[i] = r.compile(protocol.MsgType(i))
+		}
+	}
+}
+
+func (r *Router) wrap(h Handler) Handler {
+	if h == nil {
+		return nil
+	}
+	for i := len(r.middlewares) - 1; i >= 0; i-- {
+		h = r.middlewares[i](h)
+	}
+	return h
+}
+
+// Recover turns a handler panic into an ERR_SERVER_ERROR reply; the panic
+// value and stack are logged with the trace ID, never sent to the client.
+func Recover() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context, payload []byte) (err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					slog.Error("router: handler panic", "trace", ctx.TraceID, "player", ctx.PlayerID, "msg_type", ctx.MsgType.String(), "panic", v, "stack", string(debug.Stack()))
+					err = &Rejection{
+						Code: protocol.ERR_SERVER_ERROR,
+						Text: "internal server error",
+						Err:  fmt.Errorf("%w: %v", ErrHandlerPanic, v),
+					}
+				}
+			}()
+			return next(ctx, payload)
+		}
+	}
+}
+
+// Trace logs dispatches slower than threshold along with their trace ID.
+func Trace(threshold time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context, payload []byte) error {
+			start := time.Now()
+			err := next(ctx, payload)
+			if elapsed := time.Since(start); elapsed >= threshold {
+				slog.Warn("router: slow dispatch", "trace", ctx.TraceID, "player", ctx.PlayerID, "msg_type", ctx.MsgType.String(), "took", elapsed, "err", err)
+			}
+			return err
+		}
+	}
+}
+
+// BattleGate rejects messages not allowed in battle while inBattle reports
+// the player is in one.
+func (r *Router) BattleGate(inBattle func(playerID uint64) bool) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx Context, payload []byte) error {
+			if !r.Meta(ctx.MsgType).AllowedInBattle && inBattle(ctx.PlayerID) {
+				return Reject(protocol.ERR_IN_BATTLE, fmt.Errorf("router: %s not allowed in battle", ctx.MsgType))
+			}
+			return next(ctx, payload)
+		}
+	}
+}
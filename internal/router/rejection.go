@@ -0,0 +1,53 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// Rejection is returned by a handler to refuse one request without dropping
+// the connection: Dispatch replies with MSG_ERROR and reports success. Any
+// other handler error is treated as a protocol violation and closes the
+// connection.
+type Rejection struct {
+	Code protocol.ErrorCode
+	Text string
+	// Err is the underlying cause, kept for errors.Is/As and logging.
+	Err error
+}
+
+// Reject builds a Rejection whose text is err's message.
+func Reject(code protocol.ErrorCode, err error) *Rejection {
+	text := ""
+	if err != nil {
+		text = err.Error()
+	}
+	return &Rejection{Code: code, Text: text, Err: err}
+}
+
+func (r *Rejection) Error() string {
+	return fmt.Sprintf("router: rejected %s: %s", r.Code, r.Text)
+}
+
+func (r *Rejection) Unwrap() error {
+	return r.Err
+}
+
+// reply sends the rejection to the client as MSG_ERROR.
+func (r *Rejection) reply(ctx Context) error {
+	if ctx.Sender == nil {
+		return r
+	}
+	return SendTyped(ctx.Sender, &gen.Error{Code: uint32(r.Code), Text: r.Text})
+}
+
+func asRejection(err error) (*Rejection, bool) {
+	var r *Rejection
+	if errors.As(err, &r) && !r.Code.Fatal() {
+		return r, true
+	}
+	return nil, false
+}
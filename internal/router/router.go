@@ -1,7 +1,10 @@
 package router
 
 import (
+	"context"
 	"errors"
+	"sync/atomic"
+	"time"
 
 	"example.com/mvp-repo/internal/protocol"
 )
@@ -23,29 +26,136 @@ type Context struct {
 	PlayerID   uint64
 	RemoteAddr string
 	Sender     Sender
+	// RTT is the connection's latest measured round trip (0 before the first sample).
+	RTT time.Duration
+	// Bound is set once the session completed HELLO.
+	Bound bool
+	// Features were negotiated by HELLO; zero before binding.
+	Features protocol.Features
+	// MsgType and TraceID are filled in by Dispatch.
+	MsgType protocol.MsgType
+	TraceID uint64
+}
+
+// Session is the per-connection state negotiated by HELLO.
+type Session struct {
+	PlayerID        uint64
+	ProtocolVersion uint32
+	Features        protocol.Features
+	// Token is the auth session HELLO presented; empty until HELLO binds
+	// the connection. The gateway uses it to disconnect revoked sessions.
+	Token string
+}
+
+// SessionBinder is implemented by senders that keep negotiated session state
+// (the gateway connection). The HELLO handler calls it before replying.
+type SessionBinder interface {
+	BindSession(Session)
 }
 
 type Handler func(ctx Context, payload []byte) error
 
 type Router struct {
 	handlers [maxMsgType]Handler
+	// chain holds handlers wrapped in the middleware stack; rebuilt by
+	// Register and Use so Dispatch stays a single table lookup.
+	chain       [maxMsgType]Handler
+	middlewares []Middleware
+	meta        map[protocol.MsgType]MsgMeta
+	routes      map[protocol.MsgType]*Mailbox
+	sessions    []SessionHandler
+	drains      []DrainHandler
+	traceSeq    atomic.Uint64
 }
 
 func New() *Router {
-	return &Router{}
+	return &Router{meta: defaultMeta(), routes: make(map[protocol.MsgType]*Mailbox)}
 }
 
+// Register installs handler for msgType. Like Use, it must be called before
+// the router starts dispatching.
 func (r *Router) Register(msgType protocol.MsgType, handler Handler) {
 	r.handlers[msgType] = handler
+	r.chain[msgType] = r.compile(msgType)
+}
+
+// Route makes msgType dispatch asynchronously through mb. The middleware
+// stack and handler run on the mailbox worker; Dispatch only enqueues. Call
+// before dispatching starts.
+func (r *Router) Route(msgType protocol.MsgType, mb *Mailbox) {
+	if mb == nil {
+		delete(r.routes, msgType)
+	} else {
+		r.routes[msgType] = mb
+	}
+	r.chain[msgType] = r.compile(msgType)
+}
+
+func (r *Router) compile(msgType protocol.MsgType) Handler {
+	h := r.wrap(r.handlers[msgType])
+	mb := r.routes[msgType]
+	if h == nil || mb == nil {
+		return h
+	}
+	return func(ctx Context, payload []byte) error {
+		return mb.Post(ctx, h, payload)
+	}
 }
 
+// Dispatch runs the middleware-wrapped handler for msgType. On a bound
+// session a *Rejection with a non-fatal code is answered with MSG_ERROR and
+// swallowed; any other error should close the connection.
 func (r *Router) Dispatch(ctx Context, msgType protocol.MsgType, payload []byte) error {
 	if int(msgType) >= len(r.handlers) {
 		return ErrUnhandled
 	}
-	h := r.handlers[msgType]
+	h := r.chain[msgType]
 	if h == nil {
 		return ErrUnhandled
 	}
-	return h(ctx, payload)
+	// Enforced here rather than as optional middleware so no handler can
+	// run for an unbound session, whatever stack is installed.
+	if !ctx.Bound && r.Meta(msgType).RequiresAuth {
+		return ErrUnauthenticated
+	}
+	ctx.MsgType = msgType
+	ctx.TraceID = r.traceSeq.Add(1)
+	err := h(ctx, payload)
+	// Before HELLO completes there is no session to keep, so rejections
+	// stay fatal.
+	if rej, ok := asRejection(err); ok && ctx.Bound {
+		return rej.reply(ctx)
+	}
+	return err
+}
+
+// Bind notifies session handlers in registration order. On failure, handlers
+// that were already bound are unbound again in reverse order.
+func (r *Router) Bind(ctx Context) error {
+	for i, h := range r.sessions {
+		if err := h.HandleBind(ctx); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				r.sessions[j].HandleUnbind(ctx)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Router) Unbind(ctx Context) {
+	for i := len(r.sessions) - 1; i >= 0; i-- {
+		r.sessions[i].HandleUnbind(ctx)
+	}
+}
+
+// Drain runs drain handlers in registration order and joins their errors.
+func (r *Router) Drain(ctx context.Context) error {
+	var errs []error
+	for _, h := range r.drains {
+		if err := h.HandleDrain(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
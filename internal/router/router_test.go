@@ -0,0 +1,150 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// testSender records replies and closes.
+type testSender struct {
+	mu     sync.Mutex
+	sent   []protocol.MsgType
+	errors []*gen.Error
+	closed []string
+}
+
+func (s *testSender) Send(msgType protocol.MsgType, payload []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, msgType)
+	if msgType == protocol.MSG_ERROR {
+		e := &gen.Error{}
+		if err := proto.Unmarshal(payload, e); err != nil {
+			return err
+		}
+		s.errors = append(s.errors, e)
+	}
+	return nil
+}
+
+func (s *testSender) Close(reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = append(s.closed, reason)
+	return nil
+}
+
+func (s *testSender) snapshot() (sent []protocol.MsgType, errs []*gen.Error, closed []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]protocol.MsgType(nil), s.sent...), append([]*gen.Error(nil), s.errors...), append([]string(nil), s.closed...)
+}
+
+func TestDispatchRejection(t *testing.T) {
+	tests := []struct {
+		name string
+		code protocol.ErrorCode
+		// bound is whether HELLO completed before the message.
+		bound bool
+		// msgType must not require auth when bound is false.
+		msgType   protocol.MsgType
+		wantReply bool
+	}{
+		{name: "non-fatal on bound session", code: protocol.ERR_CHAT_MUTED, bound: true, msgType: protocol.MSG_CHAT_SEND, wantReply: true},
+		{name: "non-fatal before hello", code: protocol.ERR_UNAVAILABLE, bound: false, msgType: protocol.MSG_HELLO},
+		{name: "fatal on bound session", code: protocol.ERR_MALFORMED, bound: true, msgType: protocol.MSG_CHAT_SEND},
+		{name: "fatal before hello", code: protocol.ERR_UNAUTHENTICATED, bound: false, msgType: protocol.MSG_HELLO},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := New()
+			cause := errors.New("test: refused")
+			r.Register(tc.msgType, func(Context, []byte) error { return Reject(tc.code, cause) })
+			sender := &testSender{}
+
+			err := r.Dispatch(Context{Sender: sender, Bound: tc.bound}, tc.msgType, nil)
+			sent, errs, _ := sender.snapshot()
+			if tc.wantReply {
+				if err != nil {
+					t.Fatalf("Dispatch = %v, want nil", err)
+				}
+				if len(errs) != 1 || protocol.ErrorCode(errs[0].GetCode()) != tc.code || errs[0].GetText() != cause.Error() {
+					t.Fatalf("replies = %v, want one MSG_ERROR %s", errs, tc.code)
+				}
+				return
+			}
+			var rej *Rejection
+			if !errors.As(err, &rej) || rej.Code != tc.code || !errors.Is(err, cause) {
+				t.Fatalf("Dispatch = %v, want the %s rejection returned", err, tc.code)
+			}
+			if len(sent) != 0 {
+				t.Fatalf("sent %v, want nothing: the caller closes", sent)
+			}
+		})
+	}
+}
+
+func TestDispatchRequiresAuthBeforeHandler(t *testing.T) {
+	r := New()
+	ran := false
+	r.Register(protocol.MSG_CHAT_SEND, func(Context, []byte) error { ran = true; return nil })
+
+	if err := r.Dispatch(Context{}, protocol.MSG_CHAT_SEND, nil); !errors.Is(err, ErrUnauthenticated) {
+		t.Fatalf("unbound Dispatch = %v, want ErrUnauthenticated", err)
+	}
+	if ran {
+		t.Fatal("handler ran for an unbound session")
+	}
+	if err := r.Dispatch(Context{Bound: true}, protocol.MSG_WORLD_MOVE_INTENT, nil); !errors.Is(err, ErrUnhandled) {
+		t.Fatalf("unregistered Dispatch = %v, want ErrUnhandled", err)
+	}
+}
+
+func TestDispatchPassesOtherErrors(t *testing.T) {
+	r := New()
+	cause := errors.New("test: broken")
+	r.Register(protocol.MSG_CHAT_SEND, func(Context, []byte) error { return cause })
+	sender := &testSender{}
+
+	if err := r.Dispatch(Context{Sender: sender, Bound: true}, protocol.MSG_CHAT_SEND, nil); !errors.Is(err, cause) {
+		t.Fatalf("Dispatch = %v, want the handler error", err)
+	}
+	if sent, _, _ := sender.snapshot(); len(sent) != 0 {
+		t.Fatalf("sent %v for a non-rejection error", sent)
+	}
+}
+
+func TestRecoverHidesPanicValue(t *testing.T) {
+	r := New()
+	r.Use(Recover())
+	const secret = "db password is hunter2"
+	r.Register(protocol.MSG_CHAT_SEND, func(Context, []byte) error { panic(secret) })
+	sender := &testSender{}
+
+	if err := r.Dispatch(Context{Sender: sender, Bound: true}, protocol.MSG_CHAT_SEND, nil); err != nil {
+		t.Fatalf("Dispatch = %v, want the panic answered and swallowed", err)
+	}
+	_, errs, closed := sender.snapshot()
+	if len(errs) != 1 || protocol.ErrorCode(errs[0].GetCode()) != protocol.ERR_SERVER_ERROR {
+		t.Fatalf("replies = %v, want one ERR_SERVER_ERROR", errs)
+	}
+	if strings.Contains(errs[0].GetText(), "hunter2") {
+		t.Fatalf("panic value sent to the client: %q", errs[0].GetText())
+	}
+	if len(closed) != 0 {
+		t.Fatalf("connection closed: %v", closed)
+	}
+
+	// The wrapped error still carries the panic for logs and errors.Is.
+	err := Recover()(func(Context, []byte) error { panic(secret) })(Context{}, nil)
+	if !errors.Is(err, ErrHandlerPanic) || !strings.Contains(err.Error(), "internal server error") {
+		t.Fatalf("Recover = %v, want ErrHandlerPanic", err)
+	}
+}
@@ -0,0 +1,80 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+var (
+	ErrUnregisteredMessage = errors.New("router: message has no msg type")
+	ErrMalformedPayload    = errors.New("router: malformed payload")
+)
+
+var marshalBuffers = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, 256)
+		return &buf
+	},
+}
+
+// SendTyped marshals msg into a pooled buffer and sends it under the msg
+// type registered for it in internal/protocol.
+func SendTyped(s Sender, msg proto.Message) error {
+	t, ok := protocol.MsgTypeOf(msg)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnregisteredMessage, msg.ProtoReflect().Descriptor().FullName())
+	}
+	bp := marshalBuffers.Get().(*[]byte)
+	payload, err := proto.MarshalOptions{}.MarshalAppend((*bp)[:0], msg)
+	if err == nil {
+		err = s.Send(t, payload)
+	}
+	*bp = payload[:0]
+	marshalBuffers.Put(bp)
+	return err
+}
+
+// messagePools holds one *sync.Pool per generated message type.
+var messagePools sync.Map // reflect.Type -> *sync.Pool
+
+func poolFor[T any]() *sync.Pool {
+	key := reflect.TypeFor[T]()
+	if p, ok := messagePools.Load(key); ok {
+		return p.(*sync.Pool)
+	}
+	p, _ := messagePools.LoadOrStore(key, &sync.Pool{New: func() any { return new(T) }})
+	return p.(*sync.Pool)
+}
+
+// Decode unmarshals payload into a pooled *T. Pass the message to Release
+// once the handler is done with it; it must not be retained afterwards.
+// Errors wrap ErrMalformedPayload.
+func Decode[T any, PT interface {
+	*T
+	proto.Message
+}](payload []byte) (PT, error) {
+	msg := PT(poolFor[T]().Get().(*T))
+	if err := proto.Unmarshal(payload, msg); err != nil {
+		Release(msg)
+		return nil, fmt.Errorf("%w: %v", ErrMalformedPayload, err)
+	}
+	return msg, nil
+}
+
+// Release resets msg and returns it to its pool.
+func Release[T any, PT interface {
+	*T
+	proto.Message
+}](msg PT) {
+	if msg == nil {
+		return
+	}
+	proto.Reset(msg)
+	poolFor[T]().Put((*T)(msg))
+}
@@ -0,0 +1,67 @@
+package ws_gateway
+
+import (
+	"example.com/mvp-repo/internal/net/frame"
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// MSG_BATCH and frame.BatchType must agree; this fails to compile otherwise.
+const _ = uint16(protocol.MSG_BATCH) - frame.BatchType + (frame.BatchType - uint16(protocol.MSG_BATCH))
+
+func (c *conn) batching() bool {
+	return c.cfg.Batching.MaxRecords > 1 && c.negotiated().Has(protocol.FEATURE_BATCH)
+}
+
+// packBatch packs first and as many following queued frames as fit into one
+// MSG_BATCH envelope. A lone frame is returned unchanged. Consumed frames are
+// returned to the pool.
+func (c *conn) packBatch(first []byte) []byte {
+	maxPayload := uint32(c.cfg.Batching.MaxBytes)
+	room := int(maxPayload) - len(first)
+	if room < frame.HeaderLen {
+		return first
+	}
+	next := c.queue.NextWithin(room)
+	if next == nil {
+		return first
+	}
+	var b frame.Batch
+	b.Reset(c.getBuffer(int(maxPayload)), maxPayload)
+	for _, f := range [][]byte{first, next} {
+		_ = b.AppendFrame(f)
+		c.putBuffer(f)
+	}
+	for b.Len() < c.cfg.Batching.MaxRecords {
+		f := c.queue.NextWithin(b.Room())
+		if f == nil {
+			break
+		}
+		_ = b.AppendFrame(f)
+		c.putBuffer(f)
+	}
+	c.metrics.BatchesSent.Add(1)
+	c.metrics.BatchedFrames.Add(uint64(b.Len()))
+	return b.Bytes()
+}
+
+// handleBatch unwraps an inbound envelope; each record goes through the same
+// rate limiting and dispatch as a standalone frame.
+func (c *conn) handleBatch(payload []byte) error {
+	if !c.negotiated().Has(protocol.FEATURE_BATCH) {
+		return violation(ErrUnsupportedFrame)
+	}
+	r := frame.NewBatchReader(payload, c.cfg.ReadLimitBytes)
+	for r.Next() {
+		msgType, record, compressed := r.Record()
+		if compressed {
+			return violation(ErrUnsupportedFrame)
+		}
+		if err := c.handle(protocol.MsgType(msgType), record); err != nil {
+			return err
+		}
+	}
+	if err := r.Err(); err != nil {
+		return violation(err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package ws_gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/coder/websocket"
+
+	"example.com/mvp-repo/internal/net/frame"
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/router"
+)
+
+// flushFrames is how many frames are queued per write-loop wakeup: a busy
+// tick's world deltas and chat events for one player.
+const flushFrames = 16
+
+func batchBenchConfig() Config {
+	cfg := testConfig()
+	cfg.WriteQueues = QueueLimits{Control: 64, Battle: 64, World: 64, Chat: 64}
+	cfg.Batching = Batching{MaxRecords: 32, MaxBytes: 16 << 10}
+	return cfg
+}
+
+// batchBenchPayloads are small frames of the kinds that pile up between
+// writes. World frames use MSG_WORLD_SNAPSHOT because deltas coalesce into a
+// single pending slot.
+var batchBenchPayloads = []struct {
+	name    string
+	msgType protocol.MsgType
+	size    int
+}{
+	{"world_48B", protocol.MSG_WORLD_SNAPSHOT, 48},
+	{"chat_event_160B", protocol.MSG_CHAT_EVENT, 160},
+}
+
+// BenchmarkWritePerMessage writes each queued frame as its own WebSocket
+// message, as writeLoop does for sessions without FEATURE_BATCH.
+func BenchmarkWritePerMessage(b *testing.B) {
+	for _, p := range batchBenchPayloads {
+		b.Run(p.name, func(b *testing.B) {
+			c := socketConn(b, batchBenchConfig(), router.New())
+			payload := make([]byte, p.size)
+			ctx := context.Background()
+			b.SetBytes(int64(flushFrames * (frame.HeaderLen + p.size)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < flushFrames; j++ {
+					if err := c.Send(p.msgType, payload); err != nil {
+						b.Fatal(err)
+					}
+				}
+				writes := 0
+				for f := c.queue.Next(); f != nil; f = c.queue.Next() {
+					if err := c.ws.Write(ctx, websocket.MessageBinary, f); err != nil {
+						b.Fatal(err)
+					}
+					c.putBuffer(f)
+					writes++
+				}
+				if writes != flushFrames {
+					b.Fatalf("wrote %d messages, want %d", writes, flushFrames)
+				}
+			}
+			b.ReportMetric(flushFrames, "ws-msgs/flush")
+		})
+	}
+}
+
+// BenchmarkWriteBatched packs the same queued frames with packBatch and
+// writes one MSG_BATCH envelope per flush.
+func BenchmarkWriteBatched(b *testing.B) {
+	for _, p := range batchBenchPayloads {
+		b.Run(p.name, func(b *testing.B) {
+			c := socketConn(b, batchBenchConfig(), router.New())
+			payload := make([]byte, p.size)
+			ctx := context.Background()
+			b.SetBytes(int64(flushFrames * (frame.HeaderLen + p.size)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			writes := 0
+			for i := 0; i < b.N; i++ {
+				for j := 0; j < flushFrames; j++ {
+					if err := c.Send(p.msgType, payload); err != nil {
+						b.Fatal(err)
+					}
+				}
+				for f := c.queue.Next(); f != nil; f = c.queue.Next() {
+					out := c.packBatch(f)
+					if err := c.ws.Write(ctx, websocket.MessageBinary, out); err != nil {
+						b.Fatal(err)
+					}
+					c.putBuffer(out)
+					writes++
+				}
+			}
+			b.ReportMetric(float64(writes)/float64(b.N), "ws-msgs/flush")
+			if got := c.metrics.BatchedFrames.Load(); got != uint64(b.N*flushFrames) {
+				b.Fatalf("batched %d frames, want %d", got, b.N*flushFrames)
+			}
+		})
+	}
+}
@@ -3,27 +3,134 @@ package ws_gateway
 import (
 	"fmt"
 	"time"
+
+	"github.com/coder/websocket"
+
+	"example.com/mvp-repo/internal/net/frame"
 )
 
 const (
-	DefaultReadTimeout  = 30 * time.Second
-	DefaultWriteTimeout = 10 * time.Second
+	DefaultReadTimeout       = 30 * time.Second
+	DefaultWriteTimeout      = 10 * time.Second
+	DefaultHeartbeatInterval = 10 * time.Second
+	DefaultPongTimeout       = 5 * time.Second
+	DefaultIdleTimeout       = 30 * time.Second
+	DefaultDrainTimeout      = 30 * time.Second
+	DefaultDrainNotice       = 5 * time.Second
+	DefaultDrainFlushTimeout = 5 * time.Second
 )
 
+// QueueLimits bounds each outbound priority lane, in frames.
+type QueueLimits struct {
+	Control int
+	Battle  int
+	World   int
+	Chat    int
+}
+
+// CompressionMode selects permessage-deflate (RFC 7692) negotiation.
+type CompressionMode string
+
+const (
+	CompressionDisabled          CompressionMode = "disabled"
+	CompressionContextTakeover   CompressionMode = "context_takeover"
+	CompressionNoContextTakeover CompressionMode = "no_context_takeover"
+)
+
+// PayloadDeflateSubprotocol is offered by clients that can decode frames
+// carrying frame.FlagCompressed.
+const PayloadDeflateSubprotocol = "mvp.payload-deflate"
+
+// Compression configures transport-level permessage-deflate and the optional
+// application-level compressed-frame flag for large snapshot/timeline payloads.
+type Compression struct {
+	Mode            CompressionMode
+	ThresholdBytes  int
+	PayloadDeflate  bool
+	PayloadMinBytes int
+}
+
+func (m CompressionMode) websocketMode() websocket.CompressionMode {
+	switch m {
+	case CompressionContextTakeover:
+		return websocket.CompressionContextTakeover
+	case CompressionNoContextTakeover:
+		return websocket.CompressionNoContextTakeover
+	default:
+		return websocket.CompressionDisabled
+	}
+}
+
 type Config struct {
 	ReadLimitBytes         uint32
-	WriteQueueMaxFrames    int
+	WriteQueues            QueueLimits
 	OverworldDeltaCoalesce bool
-	ReadTimeout            time.Duration
-	WriteTimeout           time.Duration
+	Compression            Compression
+	Limits                 Limits
+	// ReadTimeout bounds receiving one frame once its header has arrived.
+	ReadTimeout time.Duration
+	// WriteTimeout bounds writing one frame; exceeding it marks a slow consumer.
+	WriteTimeout time.Duration
+	// HeartbeatInterval paces server MSG_PING and WebSocket pings.
+	HeartbeatInterval time.Duration
+	// PongTimeout bounds waiting for a WebSocket pong.
+	PongTimeout time.Duration
+	// IdleTimeout closes peers that send no application frames for this long.
+	IdleTimeout time.Duration
+	Drain       DrainConfig
+	// Batching applies to sessions that negotiated protocol.FEATURE_BATCH.
+	Batching Batching
+}
+
+// Batching bounds outbound MSG_BATCH envelopes. MaxRecords <= 1 disables
+// outbound batching; MaxBytes caps the envelope payload and must not exceed
+// ReadLimitBytes (clients mirror the server's frame limit).
+type Batching struct {
+	MaxRecords int
+	MaxBytes   int
+}
+
+// DrainConfig bounds Server.Drain. Drain hooks get Timeout; connections then
+// get FlushTimeout to write their queued frames before being cut.
+type DrainConfig struct {
+	Timeout        time.Duration
+	NoticeInterval time.Duration
+	FlushTimeout   time.Duration
 }
 
 func (cfg Config) Validate() error {
 	if cfg.ReadLimitBytes == 0 {
 		return fmt.Errorf("ws_gateway: ReadLimitBytes must be > 0")
 	}
-	if cfg.WriteQueueMaxFrames <= 0 {
-		return fmt.Errorf("ws_gateway: WriteQueueMaxFrames must be > 0")
+	if cfg.WriteQueues.Control <= 0 || cfg.WriteQueues.Battle <= 0 || cfg.WriteQueues.World <= 0 || cfg.WriteQueues.Chat <= 0 {
+		return fmt.Errorf("ws_gateway: WriteQueues limits must be > 0")
+	}
+	switch cfg.Compression.Mode {
+	case "", CompressionDisabled, CompressionContextTakeover, CompressionNoContextTakeover:
+	default:
+		return fmt.Errorf("ws_gateway: unknown Compression.Mode %q", cfg.Compression.Mode)
+	}
+	if cfg.Compression.ThresholdBytes < 0 || cfg.Compression.PayloadMinBytes < 0 {
+		return fmt.Errorf("ws_gateway: Compression thresholds must be >= 0")
+	}
+	if cfg.Limits.MaxConnsPerIP < 0 {
+		return fmt.Errorf("ws_gateway: Limits.MaxConnsPerIP must be >= 0")
+	}
+	if err := cfg.Limits.Handshake.validate("Handshake"); err != nil {
+		return err
+	}
+	if err := cfg.Limits.Messages.validate("Messages"); err != nil {
+		return err
+	}
+	for class, limit := range cfg.Limits.ClassLimits {
+		if err := limit.validate("ClassLimits[" + class.String() + "]"); err != nil {
+			return err
+		}
+	}
+	for msgType, limit := range cfg.Limits.MessageOverrides {
+		if err := limit.validate("MessageOverrides[" + msgType.String() + "]"); err != nil {
+			return err
+		}
 	}
 	if cfg.ReadTimeout <= 0 {
 		return fmt.Errorf("ws_gateway: ReadTimeout must be > 0")
@@ -31,5 +138,30 @@ func (cfg Config) Validate() error {
 	if cfg.WriteTimeout <= 0 {
 		return fmt.Errorf("ws_gateway: WriteTimeout must be > 0")
 	}
+	if cfg.HeartbeatInterval <= 0 {
+		return fmt.Errorf("ws_gateway: HeartbeatInterval must be > 0")
+	}
+	if cfg.PongTimeout <= 0 || cfg.PongTimeout >= cfg.HeartbeatInterval {
+		return fmt.Errorf("ws_gateway: PongTimeout must be > 0 and < HeartbeatInterval")
+	}
+	if cfg.IdleTimeout <= cfg.HeartbeatInterval {
+		return fmt.Errorf("ws_gateway: IdleTimeout must be > HeartbeatInterval")
+	}
+	if cfg.Batching.MaxRecords > 1 && (cfg.Batching.MaxBytes <= frame.HeaderLen || cfg.Batching.MaxBytes > int(cfg.ReadLimitBytes)) {
+		return fmt.Errorf("ws_gateway: Batching.MaxBytes must be in (%d, ReadLimitBytes]", frame.HeaderLen)
+	}
+	if cfg.Drain.Timeout <= 0 || cfg.Drain.NoticeInterval <= 0 || cfg.Drain.FlushTimeout <= 0 {
+		return fmt.Errorf("ws_gateway: Drain timeouts must be > 0")
+	}
+	return nil
+}
+
+func (l RateLimit) validate(name string) error {
+	if l.PerSecond < 0 {
+		return fmt.Errorf("ws_gateway: Limits.%s.PerSecond must be >= 0", name)
+	}
+	if l.PerSecond > 0 && l.Burst < 1 {
+		return fmt.Errorf("ws_gateway: Limits.%s.Burst must be >= 1", name)
+	}
 	return nil
 }
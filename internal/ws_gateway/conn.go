@@ -2,7 +2,9 @@ package ws_gateway
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/coder/websocket"
@@ -19,63 +21,45 @@ type conn struct {
 	queue      *outboundQueue
 	notifyCh   chan struct{}
 	pool       *sync.Pool
+	metrics    *Metrics
+	limiter    *messageLimiter
 	remoteAddr string
 	bound      bool
 	playerID   uint64
+	// payloadDeflate is set when the client negotiated PayloadDeflateSubprotocol.
+	payloadDeflate bool
+	features       atomic.Uint64
+	// session is what HELLO bound, for lookups from other goroutines.
+	session   atomic.Pointer[router.Session]
+	kickCh    chan error
+	inboundAt atomic.Int64
+	rtt       atomic.Int64
+	drainCh   chan struct{}
+	drainOnce sync.Once
+	cancel    context.CancelFunc
 }
 
-type deadlineConn struct {
-	conn          *websocket.Conn
-	readDeadline  time.Time
-	writeDeadline time.Time
-}
-
-func (c *deadlineConn) SetReadDeadline(deadline time.Time) error {
-	c.readDeadline = deadline
-	return nil
-}
-
-func (c *deadlineConn) SetWriteDeadline(deadline time.Time) error {
-	c.writeDeadline = deadline
-	return nil
-}
-
-func (c *deadlineConn) Read(ctx context.Context) (websocket.MessageType, []byte, error) {
-	if c.readDeadline.IsZero() {
-		return c.conn.Read(ctx)
-	}
-	readCtx, cancel := context.WithDeadline(ctx, c.readDeadline)
-	defer cancel()
-	return c.conn.Read(readCtx)
-}
-
-func (c *deadlineConn) Write(ctx context.Context, typ websocket.MessageType, data []byte) error {
-	if c.writeDeadline.IsZero() {
-		return c.conn.Write(ctx, typ, data)
-	}
-	writeCtx, cancel := context.WithDeadline(ctx, c.writeDeadline)
-	defer cancel()
-	return c.conn.Write(writeCtx, typ, data)
-}
-
-func (c *deadlineConn) Close(code websocket.StatusCode, reason string) error {
-	return c.conn.Close(code, reason)
-}
-
-func newConn(ws *websocket.Conn, router *router.Router, cfg Config, pool *sync.Pool, remoteAddr string) *conn {
-	return &conn{
-		ws:         &deadlineConn{conn: ws},
+func newConn(ws *websocket.Conn, router *router.Router, cfg Config, limits *atomic.Pointer[Limits], pool *sync.Pool, metrics *Metrics, remoteAddr string) *conn {
+	c := &conn{
+		ws:         &deadlineConn{conn: ws, frameTimeout: cfg.ReadTimeout},
 		router:     router,
 		cfg:        cfg,
-		queue:      newOutboundQueue(cfg.WriteQueueMaxFrames),
+		queue:      newOutboundQueue(cfg.WriteQueues),
 		notifyCh:   make(chan struct{}, 1),
+		drainCh:    make(chan struct{}),
+		kickCh:     make(chan error, 1),
 		pool:       pool,
+		metrics:    metrics,
+		limiter:    newMessageLimiter(limits, router),
 		remoteAddr: remoteAddr,
 	}
+	c.touch(time.Now())
+	return c
 }
 
 func (c *conn) run(ctx context.Context) error {
-	errCh := make(chan error, 2)
+	const loops = 3
+	errCh := make(chan error, loops)
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -85,67 +69,132 @@ func (c *conn) run(ctx context.Context) error {
 	go func() {
 		errCh <- c.writeLoop(ctx)
 	}()
+	go func() {
+		errCh <- c.heartbeatLoop(ctx)
+	}()
 
-	err := <-errCh
-	cancel()
+	var err error
+	pending := loops
+	select {
+	case err = <-errCh:
+		pending--
+	case err = <-c.kickCh:
+	}
 	c.queue.Close()
-	_ = c.ws.Close(websocket.StatusNormalClosure, "closing")
-	<-errCh
+	// Close before cancelling: a cancelled read tears the socket down
+	// without sending the close frame carrying our status code.
+	code, reason := closeStatus(err)
+	_ = c.ws.Close(code, reason)
+	cancel()
+	for ; pending > 0; pending-- {
+		<-errCh
+	}
+	if c.bound {
+		c.router.Unbind(c.routerContext())
+	}
 	return err
 }
 
 func (c *conn) readLoop(ctx context.Context) error {
 	for {
-		if err := c.ws.SetReadDeadline(time.Now().Add(c.cfg.ReadTimeout)); err != nil {
-			return err
-		}
 		msgType, data, err := c.ws.Read(ctx)
 		if err != nil {
+			if errors.Is(err, ErrReadTimeout) {
+				c.metrics.ReadTimeouts.Add(1)
+			}
 			return err
 		}
+		c.touch(time.Now())
 		if msgType != websocket.MessageBinary {
-			return ErrUnsupportedFrame
+			return violation(ErrUnsupportedFrame)
 		}
-		wireType, payload, err := frame.Decode(data, c.cfg.ReadLimitBytes)
-		if err != nil {
+		if err := c.handleFrame(data); err != nil {
 			return err
 		}
-		msg := protocol.MsgType(wireType)
+	}
+}
 
-		if msg == protocol.MSG_PING {
-			if err := c.Send(protocol.MSG_PONG, nil); err != nil {
-				return err
-			}
-			continue
-		}
+// handleFrame processes one inbound WebSocket message: a single frame or a
+// batch envelope.
+func (c *conn) handleFrame(data []byte) error {
+	wireType, payload, err := frame.Decode(data, c.cfg.ReadLimitBytes)
+	if err != nil {
+		return violation(err)
+	}
+	if wireType == frame.BatchType {
+		return c.handleBatch(payload)
+	}
+	return c.handle(protocol.MsgType(wireType), payload)
+}
 
-		if !c.bound {
-			if msg != protocol.MSG_HELLO {
-				return ErrUnauthenticated
-			}
-			ctx := router.Context{
-				PlayerID:   c.playerID,
-				RemoteAddr: c.remoteAddr,
-				Sender:     c,
-			}
-			if err := c.router.Dispatch(ctx, msg, payload); err != nil {
-				return err
-			}
-			c.bound = true
-			continue
-		}
+// handle processes one logical message, whether it arrived alone or inside a
+// batch envelope.
+func (c *conn) handle(msg protocol.MsgType, payload []byte) error {
+	if !c.limiter.allow(msg, time.Now()) {
+		c.metrics.MessageRateLimited.Add(1)
+		return &closeError{code: StatusRateLimited, reason: "rate limited", err: ErrRateLimited}
+	}
 
-		rctx := router.Context{
-			PlayerID:   c.playerID,
-			RemoteAddr: c.remoteAddr,
-			Sender:     c,
-		}
-		if err := c.router.Dispatch(rctx, msg, payload); err != nil {
-			return err
+	switch msg {
+	case protocol.MSG_PING:
+		return c.Send(protocol.MSG_PONG, nil)
+	case protocol.MSG_PONG:
+		// Reply to a server heartbeat; liveness was recorded on read.
+		return nil
+	}
+
+	// HELLO binds once; a second one would rebind the session under the
+	// handlers that already joined it.
+	if c.bound && msg == protocol.MSG_HELLO {
+		return violation(ErrAlreadyBound)
+	}
+	// Dispatch refuses types that require auth until the session is bound;
+	// the gateway only tracks when HELLO binds it.
+	rctx := c.routerContext()
+	if err := c.router.Dispatch(rctx, msg, payload); err != nil {
+		return violation(err)
+	}
+	if !c.bound && msg == protocol.MSG_HELLO {
+		if err := c.router.Bind(rctx); err != nil {
+			return violation(err)
 		}
+		c.bound = true
+	}
+	return nil
+}
+
+func (c *conn) routerContext() router.Context {
+	return router.Context{
+		PlayerID:   c.playerID,
+		RemoteAddr: c.remoteAddr,
+		Sender:     c,
+		RTT:        c.RTT(),
+		Bound:      c.bound,
+		Features:   c.negotiated(),
+	}
+}
+
+// BindSession records what HELLO negotiated. It runs on the read goroutine
+// during HELLO dispatch.
+func (c *conn) BindSession(s router.Session) {
+	c.playerID = s.PlayerID
+	c.features.Store(uint64(s.Features))
+	c.session.Store(&s)
+}
+
+// kick closes the connection with err's status without waiting for the
+// outbound queue. Only the first kick counts.
+func (c *conn) kick(err error) {
+	select {
+	case c.kickCh <- err:
+	default:
 	}
 }
 
+func (c *conn) negotiated() protocol.Features {
+	return protocol.Features(c.features.Load())
+}
+
 func (c *conn) writeLoop(ctx context.Context) error {
 	for {
 		frameBytes := c.queue.Next()
@@ -155,13 +204,22 @@ func (c *conn) writeLoop(ctx context.Context) error {
 				return ctx.Err()
 			case <-c.notifyCh:
 				continue
+			case <-c.drainCh:
+				return errGoingAway
 			}
 		}
+		if c.batching() {
+			frameBytes = c.packBatch(frameBytes)
+		}
 		if err := c.ws.SetWriteDeadline(time.Now().Add(c.cfg.WriteTimeout)); err != nil {
 			return err
 		}
 		err := c.ws.Write(ctx, websocket.MessageBinary, frameBytes)
 		c.putBuffer(frameBytes)
+		if errors.Is(err, ErrWriteTimeout) {
+			c.metrics.SlowConsumers.Add(1)
+			return &closeError{code: StatusSlowConsumer, reason: "slow consumer", err: err}
+		}
 		if err != nil {
 			return err
 		}
@@ -170,13 +228,13 @@ func (c *conn) writeLoop(ctx context.Context) error {
 
 func (c *conn) Send(msgType protocol.MsgType, payload []byte) error {
 	buffer := c.getBuffer(len(payload))
-	frameBytes, err := frame.Encode(buffer[:0], uint16(msgType), payload, c.cfg.ReadLimitBytes)
+	frameBytes, err := c.encode(buffer, msgType, payload)
 	if err != nil {
 		c.putBuffer(buffer)
 		return err
 	}
 
-	if isDroppable(msgType) && c.cfg.OverworldDeltaCoalesce {
+	if msgType == protocol.MSG_WORLD_DELTA && c.cfg.OverworldDeltaCoalesce {
 		replaced, ok := c.queue.SetDroppable(frameBytes)
 		if !ok {
 			c.putBuffer(frameBytes)
@@ -188,19 +246,49 @@ func (c *conn) Send(msgType protocol.MsgType, payload []byte) error {
 		c.notify()
 		return nil
 	}
+	if msgType == protocol.MSG_WORLD_SNAPSHOT {
+		c.putBuffer(c.queue.ClearDroppable())
+	}
 
-	if !c.queue.Enqueue(frameBytes) {
+	l := laneOf(msgType)
+	dropped, ok := c.queue.Enqueue(l, frameBytes)
+	if !ok {
 		c.putBuffer(frameBytes)
-		if isDroppable(msgType) {
-			return nil
-		}
 		_ = c.Close("backpressure")
 		return ErrBackpressure
 	}
+	if dropped != nil {
+		c.putBuffer(dropped)
+	}
 	c.notify()
 	return nil
 }
 
+func (c *conn) encode(buffer []byte, msgType protocol.MsgType, payload []byte) ([]byte, error) {
+	if c.payloadDeflate && compressible(msgType) && len(payload) >= c.cfg.Compression.PayloadMinBytes {
+		frameBytes, err := frame.EncodeCompressed(buffer[:0], uint16(msgType), payload, c.cfg.ReadLimitBytes)
+		if err == nil {
+			return frameBytes, nil
+		}
+		if !errors.Is(err, frame.ErrNotSmaller) {
+			return nil, err
+		}
+		buffer = frameBytes
+	}
+	return frame.Encode(buffer[:0], uint16(msgType), payload, c.cfg.ReadLimitBytes)
+}
+
+// compressible lists the large, infrequent payloads worth compressing at the
+// application level.
+func compressible(msgType protocol.MsgType) bool {
+	switch msgType {
+	case protocol.MSG_WORLD_SNAPSHOT, protocol.MSG_BATTLE_OUTCOME_TIMELINE:
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *conn) Close(reason string) error {
 	return c.ws.Close(websocket.StatusPolicyViolation, reason)
 }
@@ -229,12 +317,3 @@ func (c *conn) putBuffer(buf []byte) {
 	buf = buf[:0]
 	c.pool.Put(&buf)
 }
-
-func isDroppable(msgType protocol.MsgType) bool {
-	switch msgType {
-	case protocol.MSG_WORLD_DELTA:
-		return true
-	default:
-		return false
-	}
-}
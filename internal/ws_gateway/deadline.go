@@ -0,0 +1,149 @@
+package ws_gateway
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// opDeadline gives one direction of a websocket.Conn net.Conn-style deadline
+// semantics: the deadline applies to the in-flight call and moving it while
+// the call is blocked re-arms (or disarms) the cancellation.
+type opDeadline struct {
+	mu       sync.Mutex
+	deadline time.Time
+	gen      uint64
+	cancel   context.CancelFunc
+	timer    *time.Timer
+	timedOut bool
+}
+
+func (d *opDeadline) set(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deadline = t
+	if d.cancel != nil {
+		d.arm()
+	}
+}
+
+// begin starts an operation whose context is cancelled when the deadline passes.
+func (d *opDeadline) begin(ctx context.Context) context.Context {
+	opCtx, cancel := context.WithCancel(ctx)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.gen++
+	d.cancel = cancel
+	d.timedOut = false
+	d.arm()
+	return opCtx
+}
+
+// end finishes the current operation and reports whether it hit the deadline.
+func (d *opDeadline) end() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.cancel != nil {
+		d.cancel()
+		d.cancel = nil
+	}
+	return d.timedOut
+}
+
+// arm must be called with d.mu held and an operation in flight.
+func (d *opDeadline) arm() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.deadline.IsZero() {
+		return
+	}
+	gen := d.gen
+	cancel := d.cancel
+	d.timer = time.AfterFunc(time.Until(d.deadline), func() {
+		d.mu.Lock()
+		if d.gen == gen {
+			d.timedOut = true
+		}
+		d.mu.Unlock()
+		cancel()
+	})
+}
+
+type deadlineConn struct {
+	conn *websocket.Conn
+	// frameTimeout bounds reading a message body once its header has arrived,
+	// unless an earlier read deadline is already set.
+	frameTimeout time.Duration
+	read         opDeadline
+	write        opDeadline
+}
+
+func (c *deadlineConn) SetReadDeadline(deadline time.Time) error {
+	c.read.set(deadline)
+	return nil
+}
+
+func (c *deadlineConn) SetWriteDeadline(deadline time.Time) error {
+	c.write.set(deadline)
+	return nil
+}
+
+// Read waits for the next message. Waiting for a header is bounded only by the
+// read deadline (idle peers are reaped by the heartbeat); the body must then
+// arrive within frameTimeout.
+func (c *deadlineConn) Read(ctx context.Context) (websocket.MessageType, []byte, error) {
+	opCtx := c.read.begin(ctx)
+	typ, r, err := c.conn.Reader(opCtx)
+	if err == nil {
+		prev := c.tightenReadDeadline(time.Now().Add(c.frameTimeout))
+		var data []byte
+		data, err = io.ReadAll(r)
+		c.read.set(prev)
+		if err == nil {
+			c.read.end()
+			return typ, data, nil
+		}
+	}
+	if c.read.end() {
+		return 0, nil, ErrReadTimeout
+	}
+	return 0, nil, err
+}
+
+// tightenReadDeadline moves the read deadline to t if that is earlier and
+// returns the previous deadline so it can be restored.
+func (c *deadlineConn) tightenReadDeadline(t time.Time) time.Time {
+	c.read.mu.Lock()
+	prev := c.read.deadline
+	c.read.mu.Unlock()
+	if c.frameTimeout > 0 && (prev.IsZero() || t.Before(prev)) {
+		c.read.set(t)
+	}
+	return prev
+}
+
+func (c *deadlineConn) Write(ctx context.Context, typ websocket.MessageType, data []byte) error {
+	opCtx := c.write.begin(ctx)
+	err := c.conn.Write(opCtx, typ, data)
+	if c.write.end() {
+		return ErrWriteTimeout
+	}
+	return err
+}
+
+func (c *deadlineConn) Ping(ctx context.Context) error {
+	return c.conn.Ping(ctx)
+}
+
+func (c *deadlineConn) Close(code websocket.StatusCode, reason string) error {
+	return c.conn.Close(code, reason)
+}
@@ -0,0 +1,146 @@
+package ws_gateway
+
+import (
+	"context"
+	"time"
+
+	"github.com/coder/websocket"
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/protocol"
+)
+
+const drainReason = "server shutting down"
+
+// Drain takes the gateway out of service:
+//  1. new upgrades are refused with 503;
+//  2. connected clients that negotiated FEATURE_SERVER_SHUTDOWN receive
+//     MSG_SERVER_SHUTDOWN every NoticeInterval with the seconds left until
+//     Drain.Timeout;
+//  3. router drain hooks run with that deadline so battles can finish or
+//     checkpoint;
+//  4. every connection flushes its outbound queue and closes with 1001
+//     (going away); connections still open after FlushTimeout are cut.
+//
+// Cancelling ctx skips straight to cutting the remaining connections.
+func (s *Server) Drain(ctx context.Context) error {
+	s.mu.Lock()
+	if s.draining.Load() {
+		s.mu.Unlock()
+		return ErrDraining
+	}
+	s.draining.Store(true)
+	s.closeDrainedLocked()
+	s.mu.Unlock()
+
+	deadline := time.Now().Add(s.cfg.Drain.Timeout)
+	hookCtx, cancelHooks := context.WithDeadline(ctx, deadline)
+	defer cancelHooks()
+
+	hooksDone := make(chan error, 1)
+	go func() {
+		hooksDone <- s.router.Drain(hookCtx)
+	}()
+
+	ticker := time.NewTicker(s.cfg.Drain.NoticeInterval)
+	defer ticker.Stop()
+	s.broadcastShutdown(deadline)
+	var err error
+wait:
+	for {
+		select {
+		case err = <-hooksDone:
+			break wait
+		case <-ticker.C:
+			s.broadcastShutdown(deadline)
+		}
+	}
+
+	for _, c := range s.liveConns() {
+		c.beginDrain()
+	}
+	flushCtx, cancelFlush := context.WithTimeout(ctx, s.cfg.Drain.FlushTimeout)
+	defer cancelFlush()
+	select {
+	case <-s.drained:
+	case <-flushCtx.Done():
+		for _, c := range s.liveConns() {
+			c.cancel()
+		}
+		<-s.drained
+	}
+	return err
+}
+
+// Draining reports whether Drain has started.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+func (s *Server) broadcastShutdown(deadline time.Time) {
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+	payload, err := proto.Marshal(&gen.ServerShutdown{
+		SecondsRemaining: uint32((remaining + time.Second - 1) / time.Second),
+		Reason:           drainReason,
+	})
+	if err != nil {
+		return
+	}
+	for _, c := range s.liveConns() {
+		if c.negotiated().Has(protocol.FEATURE_SERVER_SHUTDOWN) {
+			_ = c.Send(protocol.MSG_SERVER_SHUTDOWN, payload)
+		}
+	}
+}
+
+// track registers c; a connection that raced past the draining check is told
+// to drain straight away.
+func (s *Server) track(c *conn) {
+	s.mu.Lock()
+	s.live[c] = struct{}{}
+	draining := s.draining.Load()
+	s.mu.Unlock()
+	if draining {
+		c.beginDrain()
+	}
+}
+
+// untrack closes drained once the last connection leaves during a drain.
+func (s *Server) untrack(c *conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.live, c)
+	if s.draining.Load() {
+		s.closeDrainedLocked()
+	}
+}
+
+// closeDrainedLocked closes drained once no connection is live. s.mu must be
+// held.
+func (s *Server) closeDrainedLocked() {
+	if len(s.live) == 0 && !s.drainedClosed {
+		s.drainedClosed = true
+		close(s.drained)
+	}
+}
+
+func (s *Server) liveConns() []*conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]*conn, 0, len(s.live))
+	for c := range s.live {
+		out = append(out, c)
+	}
+	return out
+}
+
+// beginDrain makes the write loop close with 1001 once the queue is empty.
+func (c *conn) beginDrain() {
+	c.drainOnce.Do(func() { close(c.drainCh) })
+}
+
+var errGoingAway = &closeError{code: websocket.StatusGoingAway, reason: drainReason, err: ErrDraining}
@@ -0,0 +1,62 @@
+package ws_gateway
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func TestDrainClosesConnectionsGoingAway(t *testing.T) {
+	s, url := startServer(t, testConfig(), nil)
+	ws := dial(t, url)
+	liveConn(t, s)
+
+	done := make(chan error, 1)
+	go func() { done <- s.Drain(context.Background()) }()
+	code, reason := readUntilClose(t, ws)
+	if code != websocket.StatusGoingAway || reason != drainReason {
+		t.Fatalf("close = %d %q, want %d %q", code, reason, websocket.StatusGoingAway, drainReason)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Drain: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Drain did not return after the last connection left")
+	}
+	if !s.Draining() {
+		t.Error("Draining() = false after Drain")
+	}
+}
+
+func TestDrainToleratesConnectionTrackedAfterStart(t *testing.T) {
+	s, url := startServer(t, testConfig(), nil)
+	// No connection is live, so Drain closes drained straight away.
+	if err := s.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+
+	// A handshake that passed the draining check before Drain started is
+	// tracked late; ServeHTTP refuses new ones, so track it by hand.
+	c := newConn(nil, s.router, s.cfg, &s.limits, &s.pool, &s.metrics, "late")
+	s.track(c)
+	select {
+	case <-c.drainCh:
+	default:
+		t.Error("connection tracked during a drain was not told to drain")
+	}
+	s.untrack(c) // closed drained a second time before the fix
+
+	ws, resp, err := websocket.Dial(context.Background(), url, nil)
+	if err == nil {
+		ws.CloseNow()
+		t.Fatal("upgrade accepted while draining")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("upgrade while draining: %v, want 503", err)
+	}
+}
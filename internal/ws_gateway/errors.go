@@ -1,10 +1,90 @@
 package ws_gateway
 
-import "errors"
+import (
+	"errors"
+
+	"github.com/coder/websocket"
+
+	"example.com/mvp-repo/internal/net/frame"
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/router"
+)
+
+// Application close codes (RFC 6455 reserves 4000-4999 for private use).
+const (
+	StatusIdleTimeout    websocket.StatusCode = 4001
+	StatusSlowConsumer   websocket.StatusCode = 4002
+	StatusSessionRevoked websocket.StatusCode = 4003
+	StatusRateLimited    websocket.StatusCode = 4008
+)
 
 var (
 	ErrRouterRequired   = errors.New("ws_gateway: router is required")
 	ErrBackpressure     = errors.New("ws_gateway: backpressure")
 	ErrUnsupportedFrame = errors.New("ws_gateway: unsupported frame")
-	ErrUnauthenticated  = errors.New("ws_gateway: unauthenticated")
+	ErrRateLimited      = errors.New("ws_gateway: message rate limited")
+	ErrReadTimeout      = errors.New("ws_gateway: read timeout")
+	ErrWriteTimeout     = errors.New("ws_gateway: write timeout")
+	ErrIdleTimeout      = errors.New("ws_gateway: idle timeout")
+	ErrPingTimeout      = errors.New("ws_gateway: ping timeout")
+	ErrDraining         = errors.New("ws_gateway: server draining")
+	ErrSessionRevoked   = errors.New("ws_gateway: session revoked")
+	ErrAlreadyBound     = errors.New("ws_gateway: session already bound")
 )
+
+// closeError carries the WebSocket status code a loop error should close with.
+type closeError struct {
+	code   websocket.StatusCode
+	reason string
+	err    error
+}
+
+func (e *closeError) Error() string {
+	return e.err.Error()
+}
+
+func (e *closeError) Unwrap() error {
+	return e.err
+}
+
+func closeStatus(err error) (websocket.StatusCode, string) {
+	var ce *closeError
+	if errors.As(err, &ce) {
+		return ce.code, ce.reason
+	}
+	return websocket.StatusNormalClosure, "closing"
+}
+
+// violation turns a read-side failure into a close carrying the protocol
+// ErrorCode name as the reason: policy violation for bad client input,
+// internal error otherwise.
+func violation(err error) error {
+	var ce *closeError
+	if errors.As(err, &ce) {
+		return err
+	}
+	code := protocol.ERR_INTERNAL
+	var rej *router.Rejection
+	switch {
+	case errors.As(err, &rej):
+		code = rej.Code
+	case errors.Is(err, router.ErrUnhandled):
+		code = protocol.ERR_UNKNOWN_MSG
+	case errors.Is(err, router.ErrMalformedPayload):
+		code = protocol.ERR_MALFORMED
+	case errors.Is(err, router.ErrUnauthenticated):
+		code = protocol.ERR_UNAUTHENTICATED
+	case errors.Is(err, ErrUnsupportedFrame),
+		errors.Is(err, ErrAlreadyBound),
+		errors.Is(err, frame.ErrShortFrame),
+		errors.Is(err, frame.ErrPayloadTooLarge),
+		errors.Is(err, frame.ErrLengthMismatch),
+		errors.Is(err, frame.ErrNestedBatch):
+		code = protocol.ERR_MALFORMED
+	}
+	status := websocket.StatusPolicyViolation
+	if code == protocol.ERR_INTERNAL {
+		status = websocket.StatusInternalError
+	}
+	return &closeError{code: status, reason: code.String(), err: err}
+}
@@ -0,0 +1,198 @@
+package ws_gateway
+
+import (
+	"bytes"
+	"errors"
+	"runtime"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"example.com/mvp-repo/internal/net/frame"
+	"example.com/mvp-repo/internal/proto/gen"
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/protocol/protocoltest"
+	"example.com/mvp-repo/internal/router"
+)
+
+const fuzzReadLimit = 4096
+
+// fuzzAllocSlack covers fixed per-message costs (router context, protobuf
+// decode state, the pooled outbound buffer) on top of the frame itself.
+const fuzzAllocSlack = 64 << 10
+
+var errFuzzUnauthenticated = errors.New("fuzz: bad token")
+
+// fuzzRouter registers a handler for every msg type. HELLO binds the session
+// with every offered feature when its token is "valid" and is refused
+// otherwise; every other handler fails the test if it runs unbound.
+func fuzzRouter(current **testing.T) *router.Router {
+	r := router.New()
+	for _, s := range protocoltest.Samples() {
+		msgType := s.MsgType
+		r.Register(msgType, func(ctx router.Context, payload []byte) error {
+			if !ctx.Bound {
+				(*current).Fatalf("%s handler ran on an unbound session", msgType)
+			}
+			return nil
+		})
+	}
+	r.Register(protocol.MSG_HELLO, func(ctx router.Context, payload []byte) error {
+		if ctx.Bound {
+			(*current).Fatal("HELLO dispatched on a bound session")
+		}
+		hello, err := router.Decode[gen.Hello](payload)
+		if err != nil {
+			return router.Reject(protocol.ERR_MALFORMED, err)
+		}
+		valid := string(hello.GetToken()) == "valid"
+		offered, _ := protocol.ParseFeatures(hello.GetFeatures())
+		router.Release(hello)
+		if !valid {
+			return router.Reject(protocol.ERR_UNAUTHENTICATED, errFuzzUnauthenticated)
+		}
+		ctx.Sender.(router.SessionBinder).BindSession(router.Session{PlayerID: 1, Features: offered, Token: "valid"})
+		return ctx.Sender.Send(protocol.MSG_WELCOME, nil)
+	})
+	return r
+}
+
+// reset returns c to a fresh, unbound connection and discards what it
+// queued, so one socket serves every fuzz input.
+func (c *conn) reset() {
+	c.bound = false
+	c.playerID = 0
+	c.features.Store(0)
+	c.session.Store(nil)
+	c.limiter = newMessageLimiter(c.limiter.source, c.router)
+	for f := c.queue.Next(); f != nil; f = c.queue.Next() {
+		c.putBuffer(f)
+	}
+}
+
+// fuzzSeeds returns a valid HELLO, one valid frame per MsgType, and a batch
+// envelope carrying every MsgType.
+func fuzzSeeds(tb testing.TB) (hello []byte, frames [][]byte, batch []byte) {
+	tb.Helper()
+	payload, err := proto.Marshal(&gen.Hello{
+		Token:           []byte("valid"),
+		ProtocolVersion: protocol.ProtocolVersion,
+		Features:        protocol.FEATURE_BATCH.Names(),
+	})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	hello, err = frame.Encode(nil, uint16(protocol.MSG_HELLO), payload, fuzzReadLimit)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	var b frame.Batch
+	b.Reset(nil, fuzzReadLimit)
+	for _, s := range protocoltest.Samples() {
+		f, err := frame.Encode(nil, uint16(s.MsgType), s.Payload, fuzzReadLimit)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		frames = append(frames, f)
+		if err := b.AppendFrame(f); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return hello, frames, bytes.Clone(b.Bytes())
+}
+
+func TestReadPathSeeds(t *testing.T) {
+	current := t
+	cfg := testConfig()
+	cfg.ReadLimitBytes = fuzzReadLimit
+	c := socketConn(t, cfg, fuzzRouter(&current))
+	hello, frames, batch := fuzzSeeds(t)
+
+	// Before HELLO, auth-only types and batches are refused.
+	for _, f := range frames {
+		msgType, _, _ := frame.Decode(f, fuzzReadLimit)
+		switch protocol.MsgType(msgType) {
+		case protocol.MSG_HELLO, protocol.MSG_PING, protocol.MSG_PONG:
+			continue
+		}
+		c.reset()
+		if err := c.handleFrame(f); !errors.Is(err, router.ErrUnauthenticated) {
+			t.Errorf("unbound %s = %v, want ErrUnauthenticated", protocol.MsgType(msgType), err)
+		}
+	}
+	c.reset()
+	if err := c.handleFrame(batch); !errors.Is(err, ErrUnsupportedFrame) {
+		t.Errorf("batch before HELLO = %v, want ErrUnsupportedFrame", err)
+	}
+
+	// After a valid HELLO every record of the batch dispatches bound.
+	c.reset()
+	if err := c.handleFrame(hello); err != nil || !c.bound {
+		t.Fatalf("HELLO: bound=%v, %v", c.bound, err)
+	}
+	// The batch starts with its own HELLO record, which a bound session
+	// refuses; the rest go through without it.
+	if err := c.handleFrame(batch); !errors.Is(err, ErrAlreadyBound) {
+		t.Fatalf("batch with a second HELLO = %v, want ErrAlreadyBound", err)
+	}
+	r := frame.NewBatchReader(batch[frame.HeaderLen:], fuzzReadLimit)
+	var rest frame.Batch
+	rest.Reset(nil, fuzzReadLimit)
+	for r.Next() {
+		msgType, payload, _ := r.Record()
+		if protocol.MsgType(msgType) != protocol.MSG_HELLO {
+			_ = rest.Append(msgType, payload)
+		}
+	}
+	if err := c.handleFrame(rest.Bytes()); err != nil {
+		t.Fatalf("batch after HELLO: %v", err)
+	}
+}
+
+// FuzzReadPath feeds two arbitrary WebSocket messages through the gateway's
+// read path (frame decode, batch unwrapping, rate limiting, dispatch,
+// binding) on a connection backed by a real socket pair; the second is only
+// read if the first did not close the connection. Properties: no panic, heap
+// allocation bounded by the read limit, and no handler other than HELLO runs
+// while the session is unbound.
+func FuzzReadPath(f *testing.F) {
+	hello, frames, batch := fuzzSeeds(f)
+	for _, seed := range frames {
+		f.Add(seed, []byte(nil))
+		f.Add(hello, seed)
+	}
+	f.Add(batch, []byte(nil))
+	f.Add(hello, batch)
+
+	var current *testing.T
+	cfg := testConfig()
+	cfg.ReadLimitBytes = fuzzReadLimit
+	// A batch of PINGs queues one PONG per record; nothing drains the queue
+	// within an input.
+	cfg.WriteQueues.Control = 2 * fuzzReadLimit / frame.HeaderLen
+	c := socketConn(f, cfg, fuzzRouter(&current))
+
+	f.Fuzz(func(t *testing.T, first, second []byte) {
+		current = t
+		c.reset()
+		for _, data := range [][]byte{first, second} {
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			err := c.handleFrame(data)
+			runtime.ReadMemStats(&after)
+			if n := after.TotalAlloc - before.TotalAlloc; n > 4*fuzzReadLimit+fuzzAllocSlack {
+				t.Fatalf("handling %d bytes allocated %d", len(data), n)
+			}
+			if c.bound != (c.playerID == 1) {
+				t.Fatalf("bound=%v with player %d", c.bound, c.playerID)
+			}
+			if err != nil {
+				var ce *closeError
+				if !errors.As(err, &ce) {
+					t.Fatalf("read path returned %v without a close status", err)
+				}
+				return
+			}
+		}
+	})
+}
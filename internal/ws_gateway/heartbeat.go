@@ -0,0 +1,63 @@
+package ws_gateway
+
+import (
+	"context"
+	"time"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// heartbeatLoop sends a protocol MSG_PING and a WebSocket-level ping every
+// HeartbeatInterval. A missing WebSocket pong means the transport is stalled;
+// the pong also yields the RTT sample. Idle is judged on application frames
+// only (clients answer MSG_PING with MSG_PONG), so a peer whose socket still
+// answers pings but sends nothing for IdleTimeout is reaped as well.
+func (c *conn) heartbeatLoop(ctx context.Context) error {
+	ticker := time.NewTicker(c.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case now := <-ticker.C:
+			if now.Sub(c.lastInbound()) > c.cfg.IdleTimeout {
+				c.metrics.IdleTimeouts.Add(1)
+				return &closeError{code: StatusIdleTimeout, reason: "idle timeout", err: ErrIdleTimeout}
+			}
+			if err := c.Send(protocol.MSG_PING, nil); err != nil {
+				return err
+			}
+			if err := c.ping(ctx); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				c.metrics.IdleTimeouts.Add(1)
+				return &closeError{code: StatusIdleTimeout, reason: "ping timeout", err: ErrPingTimeout}
+			}
+		}
+	}
+}
+
+func (c *conn) ping(ctx context.Context) error {
+	pingCtx, cancel := context.WithTimeout(ctx, c.cfg.PongTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := c.ws.Ping(pingCtx); err != nil {
+		return err
+	}
+	c.rtt.Store(int64(time.Since(start)))
+	return nil
+}
+
+func (c *conn) touch(now time.Time) {
+	c.inboundAt.Store(now.UnixNano())
+}
+
+func (c *conn) lastInbound() time.Time {
+	return time.Unix(0, c.inboundAt.Load())
+}
+
+// RTT returns the most recent WebSocket ping round trip, or 0 before the first sample.
+func (c *conn) RTT() time.Duration {
+	return time.Duration(c.rtt.Load())
+}
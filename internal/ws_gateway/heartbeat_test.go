@@ -0,0 +1,98 @@
+package ws_gateway
+
+import (
+	"testing"
+	"time"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+func TestHeartbeatRecordsRTT(t *testing.T) {
+	s, url := startServer(t, testConfig(), nil)
+	ws := dial(t, url)
+	// Pongs are only sent while a read is in progress.
+	closed := readInBackground(ws)
+	c := liveConn(t, s)
+
+	waitFor(t, "an RTT sample", func() bool { return c.RTT() > 0 })
+	if rtt := c.RTT(); rtt >= testConfig().PongTimeout {
+		t.Errorf("RTT = %v, want below PongTimeout", rtt)
+	}
+	if n := s.Metrics().IdleTimeouts.Load(); n != 0 {
+		t.Errorf("idle_timeouts = %d for a responsive peer", n)
+	}
+	select {
+	case <-closed:
+		t.Fatal("responsive peer was disconnected")
+	default:
+	}
+}
+
+func TestHeartbeatReapsIdlePeer(t *testing.T) {
+	cfg := testConfig()
+	cfg.IdleTimeout = 150 * time.Millisecond
+	s, url := startServer(t, cfg, nil)
+	ws := dial(t, url)
+
+	// The socket answers every WebSocket ping but never sends MSG_PONG or
+	// anything else at the application level.
+	start := time.Now()
+	code, reason := readUntilClose(t, ws)
+	if code != StatusIdleTimeout || reason != "idle timeout" {
+		t.Fatalf("close = %d %q, want %d %q", code, reason, StatusIdleTimeout, "idle timeout")
+	}
+	if elapsed := time.Since(start); elapsed < cfg.IdleTimeout {
+		t.Errorf("reaped after %v, before IdleTimeout", elapsed)
+	}
+	waitFor(t, "the connection to be untracked", func() bool { return len(s.liveConns()) == 0 })
+	if n := s.Metrics().IdleTimeouts.Load(); n != 1 {
+		t.Errorf("idle_timeouts = %d, want 1", n)
+	}
+}
+
+func TestHeartbeatReapsStalledPeer(t *testing.T) {
+	s, url := startServer(t, testConfig(), nil)
+	ws := dial(t, url)
+	c := liveConn(t, s)
+
+	// Nothing reads on the client, so the WebSocket pong is never sent: the
+	// stalled peer must be reaped well before IdleTimeout.
+	waitFor(t, "the ping timeout", func() bool { return s.Metrics().IdleTimeouts.Load() == 1 })
+	if c.RTT() != 0 {
+		t.Errorf("RTT = %v recorded without a pong", c.RTT())
+	}
+	code, reason := readUntilClose(t, ws)
+	if code != StatusIdleTimeout || reason != "ping timeout" {
+		t.Fatalf("close = %d %q, want %d %q", code, reason, StatusIdleTimeout, "ping timeout")
+	}
+	waitFor(t, "the connection to be untracked", func() bool { return len(s.liveConns()) == 0 })
+}
+
+func TestWriteDeadlineReapsSlowConsumer(t *testing.T) {
+	cfg := testConfig()
+	cfg.WriteQueues.Battle = 4096
+	cfg.WriteTimeout = 100 * time.Millisecond
+	// Keep the heartbeat out of the way: the client answers no pings either.
+	cfg.HeartbeatInterval = 2 * time.Second
+	cfg.PongTimeout = time.Second
+	s, url := startServer(t, cfg, nil)
+	dial(t, url)
+	c := liveConn(t, s)
+
+	// The client never reads, so once the socket buffers fill a write blocks
+	// past WriteTimeout. That is a slow consumer, not an idle peer.
+	payload := make([]byte, 32<<10)
+	deadline := time.Now().Add(5 * time.Second)
+	for s.Metrics().SlowConsumers.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("slow consumer was not detected")
+		}
+		if err := c.Send(protocol.MSG_BATTLE_OUTCOME_TIMELINE, payload); err != nil {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitFor(t, "the connection to be untracked", func() bool { return len(s.liveConns()) == 0 })
+	if n := s.Metrics().IdleTimeouts.Load(); n != 0 {
+		t.Errorf("idle_timeouts = %d, want 0 for a slow consumer", n)
+	}
+}
@@ -0,0 +1,268 @@
+package ws_gateway
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/router"
+)
+
+const limiterSweepInterval = time.Minute
+
+// RateLimit is a token bucket: PerSecond refill with Burst capacity.
+// A zero PerSecond disables the limit.
+type RateLimit struct {
+	PerSecond float64
+	Burst     int
+}
+
+func (l RateLimit) enabled() bool {
+	return l.PerSecond > 0
+}
+
+// Limits guards the upgrade endpoint and inbound message flow.
+type Limits struct {
+	// AllowedOrigins lists host patterns (filepath.Match, case-insensitive)
+	// allowed to open cross-origin sockets. The request host is always allowed.
+	AllowedOrigins []string
+	// MaxConnsPerIP caps concurrent sockets per remote IP; 0 disables the cap.
+	MaxConnsPerIP int
+	// Handshake limits upgrade attempts per remote IP.
+	Handshake RateLimit
+	// Messages is the default per-connection inbound limit for each MsgType.
+	Messages RateLimit
+	// ClassLimits replaces Messages with one budget shared by every MsgType
+	// of a router.RateClass (router.MsgMeta.RateClass).
+	ClassLimits map[router.RateClass]RateLimit
+	// MessageOverrides replaces Messages and ClassLimits for specific
+	// MsgTypes; an overridden type gets its own bucket outside its class.
+	MessageOverrides map[protocol.MsgType]RateLimit
+}
+
+type tokenBucket struct {
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(limit RateLimit, now time.Time) tokenBucket {
+	return tokenBucket{limit: limit, tokens: float64(limit.Burst), last: now}
+}
+
+func (b *tokenBucket) allow(now time.Time) bool {
+	if !b.limit.enabled() {
+		return true
+	}
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens += elapsed * b.limit.PerSecond
+		if capacity := float64(b.limit.Burst); b.tokens > capacity {
+			b.tokens = capacity
+		}
+	}
+	b.last = now
+}
+
+// setLimit switches the bucket to limit, keeping the tokens earned so far up
+// to the new capacity. A bucket that was disabled starts full.
+func (b *tokenBucket) setLimit(limit RateLimit, now time.Time) {
+	if b.limit == limit {
+		return
+	}
+	if !b.limit.enabled() {
+		*b = newTokenBucket(limit, now)
+		return
+	}
+	b.refill(now)
+	b.limit = limit
+	if capacity := float64(limit.Burst); b.tokens > capacity {
+		b.tokens = capacity
+	}
+}
+
+func (b *tokenBucket) full(now time.Time) bool {
+	b.refill(now)
+	return b.tokens >= float64(b.limit.Burst)
+}
+
+type ipState struct {
+	conns     int
+	handshake tokenBucket
+}
+
+// ipLimiter tracks handshake buckets and live socket counts per remote IP.
+// Limits are read on every admit so SetLimits applies to the next attempt.
+type ipLimiter struct {
+	limits    *atomic.Pointer[Limits]
+	mu        sync.Mutex
+	byIP      map[string]*ipState
+	lastSweep time.Time
+}
+
+func newIPLimiter(limits *atomic.Pointer[Limits]) *ipLimiter {
+	return &ipLimiter{
+		limits: limits,
+		byIP:   make(map[string]*ipState),
+	}
+}
+
+type admitResult uint8
+
+const (
+	admitOK admitResult = iota
+	admitRateLimited
+	admitTooManyConns
+)
+
+// admit charges one handshake attempt and reserves a connection slot for ip.
+// Callers must release the slot when the socket closes.
+func (l *ipLimiter) admit(ip string, now time.Time) admitResult {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+	limits := l.limits.Load()
+	st, ok := l.byIP[ip]
+	if !ok {
+		st = &ipState{handshake: newTokenBucket(limits.Handshake, now)}
+		l.byIP[ip] = st
+	} else {
+		st.handshake.setLimit(limits.Handshake, now)
+	}
+	if !st.handshake.allow(now) {
+		return admitRateLimited
+	}
+	if limits.MaxConnsPerIP > 0 && st.conns >= limits.MaxConnsPerIP {
+		return admitTooManyConns
+	}
+	st.conns++
+	return admitOK
+}
+
+func (l *ipLimiter) release(ip string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if st, ok := l.byIP[ip]; ok && st.conns > 0 {
+		st.conns--
+	}
+}
+
+// sweep drops idle entries so the map stays bounded by active IPs.
+func (l *ipLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < limiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for ip, st := range l.byIP {
+		if st.conns == 0 && st.handshake.full(now) {
+			delete(l.byIP, ip)
+		}
+	}
+}
+
+// messageLimiter applies per-MsgType and per-RateClass buckets to one
+// connection's inbound frames. When the server's limits are replaced the
+// buckets are rebuilt on the next message, each starting full.
+type messageLimiter struct {
+	source  *atomic.Pointer[Limits]
+	router  *router.Router
+	limits  *Limits
+	types   map[protocol.MsgType]*tokenBucket
+	classes map[router.RateClass]*tokenBucket
+}
+
+func newMessageLimiter(source *atomic.Pointer[Limits], r *router.Router) *messageLimiter {
+	return &messageLimiter{
+		source:  source,
+		router:  r,
+		limits:  source.Load(),
+		types:   make(map[protocol.MsgType]*tokenBucket),
+		classes: make(map[router.RateClass]*tokenBucket),
+	}
+}
+
+func (l *messageLimiter) allow(msgType protocol.MsgType, now time.Time) bool {
+	if current := l.source.Load(); current != l.limits {
+		l.limits = current
+		clear(l.types)
+		clear(l.classes)
+	}
+	b := l.bucket(msgType, now)
+	if b == nil {
+		return true
+	}
+	return b.allow(now)
+}
+
+// bucket picks the budget msgType is charged to: its override, else its rate
+// class's shared limit, else the default per-type limit. It returns nil when
+// that limit is disabled.
+func (l *messageLimiter) bucket(msgType protocol.MsgType, now time.Time) *tokenBucket {
+	if limit, ok := l.limits.MessageOverrides[msgType]; ok {
+		return bucketFor(l.types, msgType, limit, now)
+	}
+	class := l.router.Meta(msgType).RateClass
+	if limit, ok := l.limits.ClassLimits[class]; ok {
+		return bucketFor(l.classes, class, limit, now)
+	}
+	return bucketFor(l.types, msgType, l.limits.Messages, now)
+}
+
+func bucketFor[K comparable](buckets map[K]*tokenBucket, key K, limit RateLimit, now time.Time) *tokenBucket {
+	if !limit.enabled() {
+		return nil
+	}
+	b, ok := buckets[key]
+	if !ok {
+		bucket := newTokenBucket(limit, now)
+		b = &bucket
+		buckets[key] = b
+	}
+	return b
+}
+
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// originAllowed mirrors websocket.Accept's origin check so rejections can be
+// counted before the upgrade is attempted.
+func originAllowed(r *http.Request, patterns []string) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(r.Host, u.Host) {
+		return true
+	}
+	for _, pattern := range patterns {
+		matched, err := filepath.Match(strings.ToLower(pattern), strings.ToLower(u.Host))
+		if err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
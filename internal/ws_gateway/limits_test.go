@@ -0,0 +1,48 @@
+package ws_gateway
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"example.com/mvp-repo/internal/protocol"
+	"example.com/mvp-repo/internal/router"
+)
+
+func TestMessageLimiterChargesRateClass(t *testing.T) {
+	var source atomic.Pointer[Limits]
+	source.Store(&Limits{
+		Messages:    RateLimit{PerSecond: 1, Burst: 1},
+		ClassLimits: map[router.RateClass]RateLimit{router.RateControl: {PerSecond: 1, Burst: 2}},
+		MessageOverrides: map[protocol.MsgType]RateLimit{
+			protocol.MSG_HELLO: {PerSecond: 1, Burst: 1},
+		},
+	})
+	l := newMessageLimiter(&source, router.New())
+	now := time.Now()
+
+	// MSG_PING and MSG_PONG are both control: they share one burst of 2.
+	if !l.allow(protocol.MSG_PING, now) || !l.allow(protocol.MSG_PONG, now) {
+		t.Fatal("control class refused within its burst")
+	}
+	if l.allow(protocol.MSG_PING, now) {
+		t.Error("control class allowed past its shared burst")
+	}
+	// MSG_HELLO is control too, but its override gives it its own bucket.
+	if !l.allow(protocol.MSG_HELLO, now) {
+		t.Error("overridden type was charged to its class")
+	}
+	// Chat has no class limit and falls back to the per-type default.
+	if !l.allow(protocol.MSG_CHAT_SEND, now) {
+		t.Error("chat refused its first message")
+	}
+	if l.allow(protocol.MSG_CHAT_SEND, now) {
+		t.Error("chat allowed past the default per-type burst")
+	}
+
+	// Replacing the limits starts every bucket full.
+	source.Store(&Limits{ClassLimits: map[router.RateClass]RateLimit{router.RateControl: {PerSecond: 1, Burst: 1}}})
+	if !l.allow(protocol.MSG_PONG, now) || l.allow(protocol.MSG_PING, now) {
+		t.Error("replaced class limit not applied with a fresh bucket")
+	}
+}
@@ -0,0 +1,35 @@
+package ws_gateway
+
+import "sync/atomic"
+
+// Metrics counts gateway admission and policy events. All fields are safe
+// for concurrent use.
+type Metrics struct {
+	Accepted             atomic.Uint64
+	OriginRejected       atomic.Uint64
+	HandshakeRateLimited atomic.Uint64
+	IPLimitRejected      atomic.Uint64
+	MessageRateLimited   atomic.Uint64
+	IdleTimeouts         atomic.Uint64
+	SlowConsumers        atomic.Uint64
+	ReadTimeouts         atomic.Uint64
+	BatchesSent          atomic.Uint64
+	BatchedFrames        atomic.Uint64
+	SessionsRevoked      atomic.Uint64
+}
+
+func (m *Metrics) Snapshot() map[string]uint64 {
+	return map[string]uint64{
+		"accepted":               m.Accepted.Load(),
+		"origin_rejected":        m.OriginRejected.Load(),
+		"handshake_rate_limited": m.HandshakeRateLimited.Load(),
+		"ip_limit_rejected":      m.IPLimitRejected.Load(),
+		"message_rate_limited":   m.MessageRateLimited.Load(),
+		"idle_timeouts":          m.IdleTimeouts.Load(),
+		"slow_consumers":         m.SlowConsumers.Load(),
+		"read_timeouts":          m.ReadTimeouts.Load(),
+		"batches_sent":           m.BatchesSent.Load(),
+		"batched_frames":         m.BatchedFrames.Load(),
+		"sessions_revoked":       m.SessionsRevoked.Load(),
+	}
+}
@@ -1,34 +1,119 @@
 package ws_gateway
 
-import "sync"
+import (
+	"sync"
+
+	"example.com/mvp-repo/internal/protocol"
+)
+
+// lane is an outbound priority class. Lower values are drained first.
+type lane uint8
+
+const (
+	// laneControl carries handshake, keepalive and error frames. Never dropped.
+	laneControl lane = iota
+	// laneBattle carries battle lifecycle and outcome timelines. Never dropped.
+	laneBattle
+	// laneWorld carries overworld snapshots (FIFO, oldest shed) and deltas
+	// (coalesced into a single pending slot when enabled).
+	laneWorld
+	// laneChat carries chat events; the oldest are shed under load.
+	laneChat
+
+	laneCount
+)
+
+func laneOf(msgType protocol.MsgType) lane {
+	switch msgType {
+	case protocol.MSG_BATTLE_START, protocol.MSG_BATTLE_OUTCOME_TIMELINE, protocol.MSG_BATTLE_END:
+		return laneBattle
+	case protocol.MSG_WORLD_SNAPSHOT, protocol.MSG_WORLD_DELTA:
+		return laneWorld
+	case protocol.MSG_CHAT_EVENT:
+		return laneChat
+	default:
+		return laneControl
+	}
+}
+
+// sheddable reports whether a full lane drops its oldest frame instead of
+// failing the enqueue.
+func (l lane) sheddable() bool {
+	return l == laneWorld || l == laneChat
+}
+
+type frameRing struct {
+	buf  [][]byte
+	head int
+	tail int
+	size int
+}
+
+func newFrameRing(capacity int) frameRing {
+	return frameRing{buf: make([][]byte, capacity)}
+}
+
+func (r *frameRing) full() bool {
+	return r.size == len(r.buf)
+}
+
+func (r *frameRing) push(frame []byte) {
+	r.buf[r.tail] = frame
+	r.tail = (r.tail + 1) % len(r.buf)
+	r.size++
+}
+
+func (r *frameRing) peek() []byte {
+	if r.size == 0 {
+		return nil
+	}
+	return r.buf[r.head]
+}
+
+func (r *frameRing) pop() []byte {
+	if r.size == 0 {
+		return nil
+	}
+	frame := r.buf[r.head]
+	r.buf[r.head] = nil
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+	return frame
+}
 
 type outboundQueue struct {
 	mu               sync.Mutex
-	buf              [][]byte
-	head             int
-	tail             int
-	size             int
+	lanes            [laneCount]frameRing
 	pendingDroppable []byte
 	closed           bool
 }
 
-func newOutboundQueue(capacity int) *outboundQueue {
-	return &outboundQueue{buf: make([][]byte, capacity)}
+func newOutboundQueue(limits QueueLimits) *outboundQueue {
+	q := &outboundQueue{}
+	q.lanes[laneControl] = newFrameRing(limits.Control)
+	q.lanes[laneBattle] = newFrameRing(limits.Battle)
+	q.lanes[laneWorld] = newFrameRing(limits.World)
+	q.lanes[laneChat] = newFrameRing(limits.Chat)
+	return q
 }
 
-func (q *outboundQueue) Enqueue(frame []byte) bool {
+// Enqueue appends frame to lane l. On a full sheddable lane the oldest frame
+// is evicted and returned as dropped; on a full non-sheddable lane ok is false.
+func (q *outboundQueue) Enqueue(l lane, frame []byte) (dropped []byte, ok bool) {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 	if q.closed {
-		return false
+		return nil, false
 	}
-	if q.size == len(q.buf) {
-		return false
+	r := &q.lanes[l]
+	if r.full() {
+		if !l.sheddable() {
+			return nil, false
+		}
+		dropped = r.pop()
 	}
-	q.buf[q.tail] = frame
-	q.tail = (q.tail + 1) % len(q.buf)
-	q.size++
-	return true
+	r.push(frame)
+	return dropped, true
 }
 
 func (q *outboundQueue) SetDroppable(frame []byte) (replaced []byte, ok bool) {
@@ -42,20 +127,43 @@ func (q *outboundQueue) SetDroppable(frame []byte) (replaced []byte, ok bool) {
 	return replaced, true
 }
 
+// ClearDroppable discards the pending coalesced frame, e.g. when a snapshot
+// supersedes it.
+func (q *outboundQueue) ClearDroppable() []byte {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	replaced := q.pendingDroppable
+	q.pendingDroppable = nil
+	return replaced
+}
+
+// Next returns the next frame in priority order. The coalesced world delta
+// follows any queued world snapshots and precedes chat.
 func (q *outboundQueue) Next() []byte {
+	return q.NextWithin(-1)
+}
+
+// NextWithin is Next restricted to frames of at most maxLen bytes (no limit
+// when maxLen < 0). A larger frame stays queued and nil is returned, so
+// priority order is never skipped.
+func (q *outboundQueue) NextWithin(maxLen int) []byte {
 	q.mu.Lock()
 	defer q.mu.Unlock()
-	if q.size > 0 {
-		frame := q.buf[q.head]
-		q.buf[q.head] = nil
-		q.head = (q.head + 1) % len(q.buf)
-		q.size--
-		return frame
-	}
-	if q.pendingDroppable != nil {
-		frame := q.pendingDroppable
-		q.pendingDroppable = nil
-		return frame
+	for l := laneControl; l < laneCount; l++ {
+		if frame := q.lanes[l].peek(); frame != nil {
+			if maxLen >= 0 && len(frame) > maxLen {
+				return nil
+			}
+			return q.lanes[l].pop()
+		}
+		if l == laneWorld && q.pendingDroppable != nil {
+			frame := q.pendingDroppable
+			if maxLen >= 0 && len(frame) > maxLen {
+				return nil
+			}
+			q.pendingDroppable = nil
+			return frame
+		}
 	}
 	return nil
 }
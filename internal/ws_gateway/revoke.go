@@ -0,0 +1,39 @@
+package ws_gateway
+
+import "example.com/mvp-repo/internal/router"
+
+var errSessionRevoked = &closeError{code: StatusSessionRevoked, reason: "session revoked", err: ErrSessionRevoked}
+
+// DisconnectSession closes every connection whose HELLO presented token and
+// reports how many there were. Connections not yet bound by HELLO are never
+// matched.
+func (s *Server) DisconnectSession(token string) int {
+	if token == "" {
+		return 0
+	}
+	return s.disconnect(func(sess *router.Session) bool {
+		return sess.Token == token
+	})
+}
+
+// DisconnectPlayer closes every token-bound connection of playerID and
+// reports how many there were.
+func (s *Server) DisconnectPlayer(playerID uint64) int {
+	return s.disconnect(func(sess *router.Session) bool {
+		return sess.Token != "" && sess.PlayerID == playerID
+	})
+}
+
+func (s *Server) disconnect(match func(*router.Session) bool) int {
+	n := 0
+	for _, c := range s.liveConns() {
+		if sess := c.session.Load(); sess != nil && match(sess) {
+			c.kick(errSessionRevoked)
+			n++
+		}
+	}
+	if n > 0 {
+		s.metrics.SessionsRevoked.Add(uint64(n))
+	}
+	return n
+}
@@ -2,9 +2,11 @@ package ws_gateway
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/coder/websocket"
 
@@ -12,9 +14,23 @@ import (
 )
 
 type Server struct {
-	cfg    Config
-	router *router.Router
-	pool   sync.Pool
+	cfg     Config
+	router  *router.Router
+	pool    sync.Pool
+	ips     *ipLimiter
+	metrics Metrics
+	// limits starts as cfg.Limits and is replaced by SetLimits; cfg.Limits
+	// is not read after New.
+	limits atomic.Pointer[Limits]
+
+	draining atomic.Bool
+	mu       sync.Mutex
+	live     map[*conn]struct{}
+	// drained is closed when the last connection leaves during a drain.
+	// drainedClosed guards it: a connection that raced past the draining
+	// check can be tracked and untracked after drained was closed.
+	drained       chan struct{}
+	drainedClosed bool
 }
 
 func New(cfg Config, router *router.Router) (*Server, error) {
@@ -27,33 +43,122 @@ func New(cfg Config, router *router.Router) (*Server, error) {
 	if cfg.WriteTimeout == 0 {
 		cfg.WriteTimeout = DefaultWriteTimeout
 	}
+	if cfg.HeartbeatInterval == 0 {
+		cfg.HeartbeatInterval = DefaultHeartbeatInterval
+	}
+	if cfg.PongTimeout == 0 {
+		cfg.PongTimeout = DefaultPongTimeout
+	}
+	if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+	if cfg.Drain.Timeout == 0 {
+		cfg.Drain.Timeout = DefaultDrainTimeout
+	}
+	if cfg.Drain.NoticeInterval == 0 {
+		cfg.Drain.NoticeInterval = DefaultDrainNotice
+	}
+	if cfg.Drain.FlushTimeout == 0 {
+		cfg.Drain.FlushTimeout = DefaultDrainFlushTimeout
+	}
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
-	return &Server{
-		cfg:    cfg,
-		router: router,
+	s := &Server{
+		cfg:     cfg,
+		router:  router,
+		live:    make(map[*conn]struct{}),
+		drained: make(chan struct{}),
 		pool: sync.Pool{
 			New: func() any {
 				buf := make([]byte, 0, int(cfg.ReadLimitBytes)+8)
 				return &buf
 			},
 		},
-	}, nil
+	}
+	limits := cfg.Limits
+	s.limits.Store(&limits)
+	s.ips = newIPLimiter(&s.limits)
+	return s, nil
+}
+
+func (s *Server) Metrics() *Metrics {
+	return &s.metrics
+}
+
+// Limits returns the limits currently in force.
+func (s *Server) Limits() Limits {
+	return *s.limits.Load()
+}
+
+// SetLimits replaces the origin, handshake, per-IP and message limits.
+// New handshakes see them immediately; open connections switch on their next
+// inbound message with fresh buckets. Sockets already over a lowered
+// MaxConnsPerIP are kept.
+func (s *Server) SetLimits(limits Limits) error {
+	cfg := s.cfg
+	cfg.Limits = limits
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	s.limits.Store(&limits)
+	return nil
 }
 
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	conn, err := websocket.Accept(w, r, nil)
+	if s.draining.Load() {
+		w.Header().Set("Retry-After", "30")
+		http.Error(w, "server draining", http.StatusServiceUnavailable)
+		return
+	}
+	limits := s.limits.Load()
+	if !originAllowed(r, limits.AllowedOrigins) {
+		s.metrics.OriginRejected.Add(1)
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	ip := remoteIP(r)
+	switch s.ips.admit(ip, time.Now()) {
+	case admitRateLimited:
+		s.metrics.HandshakeRateLimited.Add(1)
+		http.Error(w, "too many connection attempts", http.StatusTooManyRequests)
+		return
+	case admitTooManyConns:
+		s.metrics.IPLimitRejected.Add(1)
+		http.Error(w, "too many connections", http.StatusTooManyRequests)
+		return
+	}
+	defer s.ips.release(ip)
+
+	conn, err := websocket.Accept(w, r, s.acceptOptions(limits.AllowedOrigins))
 	if err != nil {
 		return
 	}
+	s.metrics.Accepted.Add(1)
 	conn.SetReadLimit(int64(s.cfg.ReadLimitBytes))
+	payloadDeflate := s.cfg.Compression.PayloadDeflate && conn.Subprotocol() == PayloadDeflateSubprotocol
 
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
 
-	c := newConn(conn, s.router, s.cfg, &s.pool, r.RemoteAddr)
+	c := newConn(conn, s.router, s.cfg, &s.limits, &s.pool, &s.metrics, r.RemoteAddr)
+	c.payloadDeflate = payloadDeflate
+	c.cancel = cancel
+	s.track(c)
+	defer s.untrack(c)
 	if err := c.run(ctx); err != nil {
-		log.Printf("ws_gateway: disconnect %s: %v", r.RemoteAddr, err)
+		slog.Debug("ws_gateway: disconnect", "remote", r.RemoteAddr, "err", err)
+	}
+}
+
+func (s *Server) acceptOptions(origins []string) *websocket.AcceptOptions {
+	opts := &websocket.AcceptOptions{
+		CompressionMode:      s.cfg.Compression.Mode.websocketMode(),
+		CompressionThreshold: s.cfg.Compression.ThresholdBytes,
+		OriginPatterns:       origins,
+	}
+	if s.cfg.Compression.PayloadDeflate {
+		opts.Subprotocols = []string{PayloadDeflateSubprotocol}
 	}
+	return opts
 }
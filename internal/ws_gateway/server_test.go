@@ -0,0 +1,169 @@
+package ws_gateway
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"example.com/mvp-repo/internal/router"
+)
+
+// testConfig is a valid gateway config with heartbeat timings short enough
+// for tests.
+func testConfig() Config {
+	return Config{
+		ReadLimitBytes: 64 << 10,
+		WriteQueues:    QueueLimits{Control: 16, Battle: 16, World: 16, Chat: 16},
+		ReadTimeout:    time.Second,
+		WriteTimeout:   time.Second,
+
+		HeartbeatInterval: 40 * time.Millisecond,
+		PongTimeout:       20 * time.Millisecond,
+		IdleTimeout:       time.Minute,
+		Drain: DrainConfig{
+			Timeout:        time.Second,
+			NoticeInterval: 100 * time.Millisecond,
+			FlushTimeout:   time.Second,
+		},
+	}
+}
+
+// startServer serves a gateway over an in-memory loopback httptest.Server.
+func startServer(t *testing.T, cfg Config, r *router.Router) (*Server, string) {
+	t.Helper()
+	if r == nil {
+		r = router.New()
+	}
+	s, err := New(cfg, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hs := httptest.NewServer(s)
+	t.Cleanup(hs.Close)
+	return s, "ws" + strings.TrimPrefix(hs.URL, "http")
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ws, _, err := websocket.Dial(ctx, url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close(websocket.StatusNormalClosure, "") })
+	return ws
+}
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// liveConn waits for the server side of the only connection.
+func liveConn(t *testing.T, s *Server) *conn {
+	t.Helper()
+	var c *conn
+	waitFor(t, "connection to be tracked", func() bool {
+		conns := s.liveConns()
+		if len(conns) == 1 {
+			c = conns[0]
+		}
+		return c != nil
+	})
+	return c
+}
+
+// readInBackground keeps a read in progress on ws, so the client answers
+// pings, and discards what arrives. The channel closes when reading fails.
+// websocket.Conn.CloseRead does not fit: it fails on the server's MSG_PING.
+func readInBackground(ws *websocket.Conn) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := ws.Read(context.Background()); err != nil {
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// readUntilClose drains ws (answering pings on the way) and returns the close
+// status the server sent.
+func readUntilClose(t *testing.T, ws *websocket.Conn) (websocket.StatusCode, string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		_, _, err := ws.Read(ctx)
+		if err == nil {
+			continue
+		}
+		var ce websocket.CloseError
+		if !errors.As(err, &ce) {
+			t.Fatalf("read: %v, want a close frame", err)
+		}
+		return ce.Code, ce.Reason
+	}
+}
+
+// socketConn returns a gateway conn whose socket is connected to a client
+// that discards everything. No loops run; the caller drives the conn the way
+// the read or write loop would.
+func socketConn(tb testing.TB, cfg Config, r *router.Router) *conn {
+	tb.Helper()
+	accepted := make(chan *websocket.Conn)
+	done := make(chan struct{})
+	hs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ws, err := websocket.Accept(w, req, nil)
+		if err != nil {
+			return
+		}
+		accepted <- ws
+		<-done
+	}))
+	client, _, err := websocket.Dial(context.Background(), "ws"+strings.TrimPrefix(hs.URL, "http"), nil)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	client.SetReadLimit(-1)
+	go func() {
+		for {
+			if _, _, err := client.Read(context.Background()); err != nil {
+				return
+			}
+		}
+	}()
+	ws := <-accepted
+	tb.Cleanup(func() {
+		client.CloseNow()
+		ws.CloseNow()
+		close(done)
+		hs.Close()
+	})
+
+	var limits atomic.Pointer[Limits]
+	limits.Store(&cfg.Limits)
+	pool := &sync.Pool{New: func() any {
+		buf := make([]byte, 0, int(cfg.ReadLimitBytes)+8)
+		return &buf
+	}}
+	return newConn(ws, r, cfg, &limits, pool, &Metrics{}, "bench")
+}
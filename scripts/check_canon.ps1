@@ -0,0 +1,15 @@
+# Fail on gameplay config problems or canon ID drift between proto\game.proto,
+# internal\protocol\enums.go and config\gameplay.json. Run before deploying.
+
+$ErrorActionPreference = "Stop"
+
+$RootDir = (Resolve-Path (Join-Path $PSScriptRoot "..")).Path
+Push-Location $RootDir
+try {
+  & go run ./cmd/gameplay lint
+  if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }
+  & go run ./cmd/gameplay canon
+  if ($LASTEXITCODE -ne 0) { exit $LASTEXITCODE }
+} finally {
+  Pop-Location
+}
@@ -0,0 +1,11 @@
+#!/usr/bin/env bash
+set -euo pipefail
+
+# Fail on gameplay config problems or canon ID drift between proto/game.proto,
+# internal/protocol/enums.go and config/gameplay.json. Run before deploying.
+
+ROOT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
+cd "${ROOT_DIR}"
+
+go run ./cmd/gameplay lint
+go run ./cmd/gameplay canon
@@ -1,5 +1,4 @@
 # Generate Go protobuf code from proto\*.proto into internal\proto\gen
-# NOTE: This batch does not ship .proto files yet; this script is ready for when they land.
 
 $ErrorActionPreference = "Stop"
 
@@ -22,7 +21,6 @@ New-Item -ItemType Directory -Force -Path $OutDir | Out-Null
 $protoFiles = Get-ChildItem -Path $ProtoDir -Filter "*.proto" -File -ErrorAction SilentlyContinue
 if (-not $protoFiles -or $protoFiles.Count -eq 0) {
   Write-Host "No .proto files found in $ProtoDir."
-  Write-Host "This is expected in Batch 00."
   exit 0
 }
 
@@ -2,7 +2,6 @@
 set -euo pipefail
 
 # Generate Go protobuf code from proto/*.proto into internal/proto/gen
-# NOTE: This batch does not ship .proto files yet; this script is ready for when they land.
 
 ROOT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")/.." && pwd)"
 PROTO_DIR="${ROOT_DIR}/proto"
@@ -29,7 +28,6 @@ shopt -u nullglob
 
 if [ ${#PROTO_FILES[@]} -eq 0 ]; then
   echo "No .proto files found in ${PROTO_DIR}."
-  echo "This is expected in Batch 00."
   exit 0
 fi
 